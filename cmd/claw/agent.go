@@ -0,0 +1,252 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/epuerta9/claw2claw/internal/agent"
+	"github.com/epuerta9/claw2claw/internal/hooks"
+	"github.com/spf13/cobra"
+)
+
+var sendBackground bool // For send - hand the transfer to the background agent
+
+func newAgentCmd() *cobra.Command {
+	agentCmd := &cobra.Command{
+		Use:   "agent",
+		Short: "Run the background agent that keeps transfers alive",
+		Long: `Run the per-user background agent in the foreground.
+
+CLI commands and hooks exit as soon as they're done, which would end any
+transfer still waiting for its peer. Instead they hand long-lived work to
+the agent over a Unix socket (~/.claw/agent.sock): pending sends (send
+--background, and shares made from hooks), channel listeners (channel
+listen) and inbox dispatch (inbox daemon --background).
+
+The agent is started automatically when a job is submitted, and then exits
+after 30 minutes with no running jobs. Check on jobs with 'claw jobs'.
+
+Examples:
+  claw agent              # Run in the foreground, logging to stdout
+  claw agent start        # Start in the background
+  claw agent status
+  claw agent stop         # Cancels running jobs`,
+		Args: cobra.NoArgs,
+		RunE: runAgent,
+	}
+	agentCmd.Flags().Duration("idle", 0, "Exit after this long with no running jobs (0 runs until stopped)")
+
+	startCmd := &cobra.Command{
+		Use:   "start",
+		Short: "Start the agent in the background",
+		Args:  cobra.NoArgs,
+		RunE:  runAgentStart,
+	}
+	startCmd.Flags().Duration("idle", 0, "Exit after this long with no running jobs (0 runs until stopped)")
+
+	stopCmd := &cobra.Command{
+		Use:   "stop",
+		Short: "Stop the agent, cancelling its running jobs",
+		Args:  cobra.NoArgs,
+		RunE:  runAgentStop,
+	}
+
+	statusCmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show whether the agent is running",
+		Args:  cobra.NoArgs,
+		RunE:  runAgentStatus,
+	}
+
+	agentCmd.AddCommand(startCmd, stopCmd, statusCmd)
+	return agentCmd
+}
+
+func newJobsCmd() *cobra.Command {
+	jobsCmd := &cobra.Command{
+		Use:   "jobs",
+		Short: "List the background agent's jobs",
+		Long: `List the jobs the background agent is running or finished recently
+(finished jobs are kept for 24 hours, until the agent exits).
+
+Examples:
+  claw jobs
+  claw jobs --json
+  claw jobs cancel 3f2a`,
+		Args: cobra.NoArgs,
+		RunE: runJobs,
+	}
+	jobsCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output as JSON")
+
+	cancelCmd := &cobra.Command{
+		Use:   "cancel <job-id>",
+		Short: "Cancel a running job (ID prefixes work)",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runJobsCancel,
+	}
+
+	jobsCmd.AddCommand(cancelCmd)
+	return jobsCmd
+}
+
+func runAgent(cmd *cobra.Command, args []string) error {
+	idle, _ := cmd.Flags().GetDuration("idle")
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	logger := log.New(os.Stdout, "", log.LstdFlags)
+	fmt.Fprintf(os.Stderr, "🤖 Agent listening on %s (Ctrl+C to stop)\n", agent.GetSocketPath())
+	return agent.Serve(ctx, idle, logger.Printf)
+}
+
+func runAgentStart(cmd *cobra.Command, args []string) error {
+	if agent.Running() {
+		fmt.Println("🤖 Agent already running")
+		return nil
+	}
+	idle, _ := cmd.Flags().GetDuration("idle")
+	if err := agent.Start(idle); err != nil {
+		return err
+	}
+	fmt.Printf("🤖 Agent started on %s\n", agent.GetSocketPath())
+	fmt.Printf("   Log: %s\n", agent.GetLogPath())
+	return nil
+}
+
+func runAgentStop(cmd *cobra.Command, args []string) error {
+	if !agent.Running() {
+		fmt.Println("Agent not running")
+		return nil
+	}
+	if err := agent.Stop(); err != nil {
+		return fmt.Errorf("failed to stop agent: %w", err)
+	}
+	fmt.Println("🛑 Agent stopped")
+	return nil
+}
+
+func runAgentStatus(cmd *cobra.Command, args []string) error {
+	if !agent.Running() {
+		fmt.Println("State: not running")
+		return nil
+	}
+	jobs, err := agent.List()
+	if err != nil {
+		return err
+	}
+	running := 0
+	for _, j := range jobs {
+		if j.Status == agent.StatusRunning {
+			running++
+		}
+	}
+	fmt.Println("State: running")
+	fmt.Printf("Socket: %s\n", agent.GetSocketPath())
+	fmt.Printf("Jobs: %d running, %d finished\n", running, len(jobs)-running)
+	return nil
+}
+
+func runJobs(cmd *cobra.Command, args []string) error {
+	var jobs []*agent.Job
+	if agent.Running() {
+		var err error
+		if jobs, err = agent.List(); err != nil {
+			return err
+		}
+	}
+
+	if jsonOutput {
+		if jobs == nil {
+			jobs = []*agent.Job{}
+		}
+		data, _ := json.MarshalIndent(jobs, "", "  ")
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if len(jobs) == 0 {
+		fmt.Println("📭 No jobs.")
+		fmt.Println("\nRun a transfer in the background with: claw send <file> --background")
+		return nil
+	}
+
+	icons := map[string]string{
+		agent.StatusRunning:   "⏳",
+		agent.StatusDone:      "✅",
+		agent.StatusFailed:    "❌",
+		agent.StatusCancelled: "🚫",
+	}
+	fmt.Println("🤖 Agent jobs:")
+	fmt.Println()
+	for _, j := range jobs {
+		fmt.Printf("%s %s  %-7s %-9s %s\n", icons[j.Status], j.ID, j.Kind, j.Status, j.Summary)
+		if j.Error != "" {
+			fmt.Printf("   %s\n", j.Error)
+		} else if j.Detail != "" {
+			fmt.Printf("   %s\n", j.Detail)
+		}
+		fmt.Printf("   started %s, updated %s\n", formatAge(j.CreatedAt), formatAge(j.UpdatedAt))
+	}
+	return nil
+}
+
+func runJobsCancel(cmd *cobra.Command, args []string) error {
+	if !agent.Running() {
+		return fmt.Errorf("agent not running")
+	}
+	j, err := agent.Cancel(args[0])
+	if err != nil {
+		return err
+	}
+	fmt.Printf("🚫 Cancelled job %s (%s %s)\n", j.ID, j.Kind, j.Summary)
+	return nil
+}
+
+// sendInBackground hands a send to the agent and prints the code to share
+func sendInBackground(filePath string) error {
+	// Session tracking (and what --full/--private save) needs a persistent room
+	if persistent {
+		return fmt.Errorf("--background sends use a code phrase, they can't use --persistent")
+	}
+	if fullContent || privateMode {
+		return fmt.Errorf("--full and --private track persistent rooms, they can't be used with --background")
+	}
+	abs, err := filepath.Abs(filePath)
+	if err != nil {
+		return err
+	}
+
+	code := hooks.GenerateCodePhrase()
+	cfg := newClientConfig()
+	job, err := agent.Submit(&agent.Spec{
+		Kind:     agent.KindSend,
+		File:     abs,
+		Code:     code,
+		LAN:      lanMode,
+		RelayURL: cfg.RelayURL,
+		Timeout:  timeout,
+		Direct:   cfg.Direct,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to hand transfer to agent: %w", err)
+	}
+
+	fmt.Printf("📤 Sharing: %s (in the background)\n", filepath.Base(filePath))
+	fmt.Printf("🔑 Share code: %s\n", code)
+	fmt.Printf("🤖 Job %s is waiting for the receiver (up to %ds)\n", job.ID, timeout)
+	fmt.Printf("\n📋 Share with receiver:\n")
+	if lanMode {
+		fmt.Printf("   claw receive %s --lan\n", code)
+	} else {
+		fmt.Printf("   claw receive %s\n", code)
+	}
+	fmt.Printf("\nCheck with: claw jobs · Stop with: claw jobs cancel %s\n", job.ID)
+	return nil
+}
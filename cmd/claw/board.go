@@ -1,15 +1,45 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/epuerta9/claw2claw/internal/account"
+	"github.com/epuerta9/claw2claw/internal/board"
+	"github.com/epuerta9/claw2claw/internal/diff"
 	"github.com/spf13/cobra"
 )
 
+var (
+	boardFile    string // For update - read content from a file
+	forceUpdate  bool   // For update - allow conflict markers
+	boardBase    int    // For update - version the content was edited from
+	checkoutOnly bool   // For edit - only write base and working copy
+	pullOnly     bool   // For sync - don't push local edits
+
+	watchExec     string        // For watch - command to run on each change
+	watchInterval time.Duration // For watch - polling interval
+	watchJSON     bool          // For watch - print changes as JSON lines
+
+	decideWhy    string // For decide - rationale
+	askTo        string // For ask - assignee
+	statusMember string // For set-status - member (defaults to you)
+
+	templateName      string // For init - template name or file
+	overwriteSections bool   // For init - replace existing sections
+)
+
 func newBoardCmd() *cobra.Command {
 	boardCmd := &cobra.Command{
 		Use:   "board [section]",
@@ -20,7 +50,10 @@ The board is a persistent shared document where team members' agents
 post updates, decisions, questions, and context.
 
 Without arguments, shows the full board.
-With a section name, shows just that section.`,
+With a section name, shows just that section.
+
+Reads keep a local mirror in .claw/board/ up to date. When the server
+can't be reached, the mirror is shown instead (see: claw board sync).`,
 		Args: cobra.MaximumNArgs(1),
 		RunE: runBoard,
 	}
@@ -35,6 +68,9 @@ If no content argument is given, reads from stdin.
 
 Sections: status, questions, decisions, context, files
 
+Changes are merged with anything saved since the version they were based
+on: the local copy's version for --file .claw/board/<section>.md, or --base.
+
 Examples:
   c2c board update status "All services green"
   c2c board update context "Working on CLO-274, refactored API routes"
@@ -43,61 +79,250 @@ Examples:
 		RunE: runBoardUpdate,
 	}
 
+	updateCmd.Flags().StringVarP(&boardFile, "file", "f", "", "Read content from a file (e.g. a resolved .claw/board/<section>.md)")
+	updateCmd.Flags().BoolVar(&forceUpdate, "force", false, "Allow content that still contains conflict markers")
+	updateCmd.Flags().IntVar(&boardBase, "base", 0, "Version the content was edited from, merged against if the section has moved on")
+
 	editCmd := &cobra.Command{
 		Use:   "edit <section>",
-		Short: "Edit a board section (reads content from stdin)",
-		Args:  cobra.ExactArgs(1),
-		RunE:  runBoardEdit,
+		Short: "Edit a board section in $EDITOR (or from stdin)",
+		Long: `Edit a board section.
+
+The current version is saved as the merge base in .claw/board/.base/ and
+a working copy is written to .claw/board/<section>.md. If someone else
+updates the section before you save, your edit is merged three-way
+against that base instead of silently overwriting their change.
+
+From a terminal, the working copy opens in $EDITOR and is saved on exit.
+When content is piped in, it is used directly.
+With --checkout, only the files are written - edit the working copy, then:
+  claw board update <section> --file .claw/board/<section>.md`,
+		Args: cobra.ExactArgs(1),
+		RunE: runBoardEdit,
 	}
+	editCmd.Flags().BoolVar(&checkoutOnly, "checkout", false, "Only write the base and working copy, don't open an editor")
 
 	initCmd := &cobra.Command{
 		Use:   "init [members...]",
-		Short: "Initialize the board with default template",
-		Long: `Initialize the team board with the default template.
+		Short: "Initialize the board from a template",
+		Long: `Initialize the team board.
+
+Without --template, the server creates its default layout. With --template,
+sections are created by this CLI, so any layout works: use a built-in
+template, one from ~/.claw/templates/<name>.json, or a path to a file.
+Existing sections are left alone unless --overwrite is given.
 
 Provide team member names to create per-member context sections.
 
-Example:
-  c2c board init eduardo jared`,
-		Args: cobra.MinimumNArgs(1),
+Examples:
+  c2c board init eduardo jared
+  c2c board init --template incident eduardo jared
+  c2c board init --template ./layouts/release.json`,
 		RunE: runBoardInit,
 	}
+	initCmd.Flags().StringVarP(&templateName, "template", "t", "", "Template name or path to a template file")
+	initCmd.Flags().BoolVar(&overwriteSections, "overwrite", false, "Replace sections that already exist")
+
+	templatesCmd := &cobra.Command{
+		Use:   "templates",
+		Short: "List available board templates",
+		Long: `List built-in and user-defined board templates.
+
+A template is a JSON file in ~/.claw/templates/:
+  {
+    "name": "release",
+    "description": "Release checklist",
+    "sections": [
+      {"section": "checklist", "content": "# Release {date}\n\n- [ ] Tag"},
+      {"section": "decisions"},
+      {"section": "context:{member}", "per_member": true, "content": "# {member}"}
+    ]
+  }
+
+Placeholders: {member}, {team}, {date}. Typed sections (status, questions,
+decisions) can omit content to start empty.`,
+		Args: cobra.NoArgs,
+		RunE: runBoardTemplates,
+	}
+
+	historyCmd := &cobra.Command{
+		Use:   "history <section>",
+		Short: "List past versions of a board section",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runBoardHistory,
+	}
+
+	diffCmd := &cobra.Command{
+		Use:   "diff <section> <from> [to]",
+		Short: "Show changes between two versions of a section",
+		Long: `Show a unified diff between two versions of a board section.
+
+If [to] is omitted, compares against the current version.
 
-	boardCmd.AddCommand(updateCmd, editCmd, initCmd)
+Examples:
+  c2c board diff decisions v3 v5
+  c2c board diff status 4`,
+		Args: cobra.RangeArgs(2, 3),
+		RunE: runBoardDiff,
+	}
+
+	revertCmd := &cobra.Command{
+		Use:   "revert <section> <version>",
+		Short: "Restore a section to an earlier version",
+		Long: `Restore a board section to the content of an earlier version.
+
+The revert is saved as a new version, so it can itself be reverted.
+
+Example:
+  c2c board revert decisions v3`,
+		Args: cobra.ExactArgs(2),
+		RunE: runBoardRevert,
+	}
+
+	syncCmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Sync the local board mirror in .claw/board/",
+		Long: `Two-way sync between the team board and the local mirror.
+
+Every section is kept as markdown in .claw/board/<section>.md, with
+versions in .claw/board/index.json. Sync pulls newer versions (merging
+them into any local edits) and then pushes local edits, using the
+version of each section to detect concurrent changes.
+
+When the server can't be reached, "claw board" reads from the mirror.
+
+Examples:
+  claw board sync
+  claw board sync --pull   # Only fetch, keep local edits local`,
+		Args: cobra.NoArgs,
+		RunE: runBoardSync,
+	}
+	syncCmd.Flags().BoolVar(&pullOnly, "pull", false, "Only pull changes, don't push local edits")
+
+	watchCmd := &cobra.Command{
+		Use:   "watch [section...]",
+		Short: "Stream board changes as they happen",
+		Long: `Watch the board and print a diff for each section update.
+
+Uses the server's live event stream when available, otherwise polls
+(sending the last ETag so unchanged boards cost almost nothing).
+
+With --exec, the command runs through the shell for every change, with the
+new content on stdin and these variables set:
+  CLAW_SECTION, CLAW_VERSION, CLAW_PREV_VERSION, CLAW_UPDATED_BY
+
+Examples:
+  claw board watch
+  claw board watch decisions questions
+  claw board watch status --exec 'notify-send "board: $CLAW_SECTION"'`,
+		RunE: runBoardWatch,
+	}
+	watchCmd.Flags().StringVar(&watchExec, "exec", "", "Shell command to run on each change")
+	watchCmd.Flags().DurationVar(&watchInterval, "interval", 15*time.Second, "Polling interval when live streaming isn't available")
+	watchCmd.Flags().BoolVar(&watchJSON, "json", false, "Print one JSON object per change")
+
+	decideCmd := &cobra.Command{
+		Use:   "decide <title>",
+		Short: "Record a decision in the decisions section",
+		Long: `Add a decision entry (ID, title, rationale, author, date) to the
+decisions section without rewriting the rest of it.
+
+Example:
+  claw board decide "Use SQLite for the local cache" --why "No server needed, fits offline mode"`,
+		Args: cobra.ExactArgs(1),
+		RunE: runBoardDecide,
+	}
+	decideCmd.Flags().StringVar(&decideWhy, "why", "", "Rationale for the decision")
+
+	askCmd := &cobra.Command{
+		Use:   "ask <question>",
+		Short: "Post an open question to the questions section",
+		Long: `Add an open question to the questions section, optionally assigned to a member.
+
+Example:
+  claw board ask "Do we still need the v1 auth endpoint?" --to jared`,
+		Args: cobra.ExactArgs(1),
+		RunE: runBoardAsk,
+	}
+	askCmd.Flags().StringVar(&askTo, "to", "", "Member the question is for")
+
+	answerCmd := &cobra.Command{
+		Use:   "answer <id> <answer>",
+		Short: "Answer an open question",
+		Long: `Mark a question as answered.
+
+Example:
+  claw board answer Q3 "No, remove it after the migration"`,
+		Args: cobra.ExactArgs(2),
+		RunE: runBoardAnswer,
+	}
+
+	setStatusCmd := &cobra.Command{
+		Use:   "set-status <status>",
+		Short: "Set your line in the status section",
+		Long: `Set your status in the status section, leaving other members' lines untouched.
+
+Example:
+  claw board set-status "Refactoring API routes (CLO-274)"`,
+		Args: cobra.ExactArgs(1),
+		RunE: runBoardSetStatus,
+	}
+	setStatusCmd.Flags().StringVar(&statusMember, "member", "", "Set the status of another member")
+
+	boardCmd.AddCommand(updateCmd, editCmd, initCmd, templatesCmd, historyCmd, diffCmd, revertCmd, syncCmd, watchCmd,
+		decideCmd, askCmd, answerCmd, setStatusCmd)
 	return boardCmd
 }
 
+// expandSection maps "context" to the caller's own "context:<user_id>" section
+func expandSection(cfg *account.Config, section string) string {
+	if section != "context" {
+		return section
+	}
+	userID := cfg.UserID
+	if userID == "" {
+		userID = cfg.Name
+	}
+	return "context:" + userID
+}
+
+// parseVersion accepts "v3" or "3"
+func parseVersion(s string) (int, error) {
+	v, err := strconv.Atoi(strings.TrimPrefix(strings.ToLower(s), "v"))
+	if err != nil || v < 1 {
+		return 0, fmt.Errorf("invalid version %q (use e.g. v3)", s)
+	}
+	return v, nil
+}
+
 func runBoard(cmd *cobra.Command, args []string) error {
 	cfg, err := account.LoadConfig()
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 	if !cfg.LoggedIn || cfg.TeamID == "" {
-		return fmt.Errorf("not logged in or team not configured. Run: claw login && set team_id in ~/.claw/account.json")
+		return fmt.Errorf("not logged in or team not configured. Run: claw login && claw team join <token> (or set CLAW_TEAM)")
 	}
 
 	if len(args) == 1 {
 		// Show specific section
-		section := args[0]
 		// Auto-expand "context" to "context:<user_id>" if no colon
-		if section == "context" {
-			userID := cfg.UserID
-			if userID == "" {
-				userID = cfg.Name
-			}
-			section = "context:" + userID
-		}
+		section := expandSection(cfg, args[0])
 
 		bs, err := account.GetBoardSection(cfg, section)
 		if err != nil {
+			if board.IsOffline(err) {
+				return showLocalSection(section, err)
+			}
 			return fmt.Errorf("failed to get section: %w", err)
 		}
 		if bs == nil {
 			fmt.Printf("Section '%s' not found.\n", section)
 			return nil
 		}
+		board.Apply([]account.BoardSection{*bs}, false) // Best effort mirror refresh
 
-		fmt.Println(bs.Content)
+		fmt.Println(board.Display(bs.Content))
 		fmt.Printf("\n--- Updated by %s at %s (v%d) ---\n", bs.UpdatedBy, bs.UpdatedAt.Format("2006-01-02 15:04"), bs.Version)
 		return nil
 	}
@@ -105,6 +330,9 @@ func runBoard(cmd *cobra.Command, args []string) error {
 	// Show full board
 	sections, err := account.GetBoard(cfg)
 	if err != nil {
+		if board.IsOffline(err) {
+			return showLocalBoard(err)
+		}
 		return fmt.Errorf("failed to get board: %w", err)
 	}
 
@@ -112,16 +340,80 @@ func runBoard(cmd *cobra.Command, args []string) error {
 		fmt.Println("Board is empty. Initialize it with: claw board init <member1> <member2>")
 		return nil
 	}
+	board.Apply(sections, true) // Best effort mirror refresh
 
 	for _, s := range sections {
-		fmt.Println(s.Content)
+		fmt.Println(board.Display(s.Content))
 		fmt.Printf("  _Updated by %s at %s_\n\n", s.UpdatedBy, s.UpdatedAt.Format("2006-01-02 15:04"))
 	}
 
 	return nil
 }
 
-func runBoardUpdate(cmd *cobra.Command, args []string) error {
+// showLocalSection prints a section from the local mirror when the server is unreachable
+func showLocalSection(section string, cause error) error {
+	ls, err := board.LoadLocal(section)
+	if err != nil || ls == nil {
+		return fmt.Errorf("failed to get section (no local copy): %w", cause)
+	}
+
+	fmt.Printf("📴 Offline - showing local copy from %s\n\n", formatAge(ls.FetchedAt))
+	fmt.Println(board.Display(ls.Content))
+	fmt.Printf("\n--- Updated by %s at %s (v%d) ---\n", ls.UpdatedBy, ls.UpdatedAt.Format("2006-01-02 15:04"), ls.Version)
+	if ls.Modified {
+		fmt.Println("✏️  Includes local edits not yet synced. Run: claw board sync")
+	}
+	return nil
+}
+
+// showLocalBoard prints the whole local mirror when the server is unreachable
+func showLocalBoard(cause error) error {
+	sections, err := board.Mirror()
+	if err != nil || len(sections) == 0 {
+		return fmt.Errorf("failed to get board (no local copy, run claw board sync when online): %w", cause)
+	}
+
+	synced := board.SyncedAt()
+	if synced.IsZero() {
+		synced = sections[0].FetchedAt
+		for _, s := range sections {
+			if s.FetchedAt.Before(synced) {
+				synced = s.FetchedAt
+			}
+		}
+	}
+	fmt.Printf("📴 Offline - showing local mirror synced %s\n\n", formatAge(synced))
+
+	for _, s := range sections {
+		fmt.Println(board.Display(s.Content))
+		fmt.Printf("  _Updated by %s at %s_", s.UpdatedBy, s.UpdatedAt.Format("2006-01-02 15:04"))
+		if s.Modified {
+			fmt.Print(" _(local edits, not synced)_")
+		}
+		fmt.Print("\n\n")
+	}
+	return nil
+}
+
+// formatAge renders how long ago t was, e.g. "3h ago"
+func formatAge(t time.Time) string {
+	if t.IsZero() {
+		return "at an unknown time"
+	}
+	d := time.Since(t)
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+	}
+}
+
+func runBoardSync(cmd *cobra.Command, args []string) error {
 	cfg, err := account.LoadConfig()
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
@@ -130,20 +422,62 @@ func runBoardUpdate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("not logged in or team not configured")
 	}
 
-	section := args[0]
-
-	// Auto-expand "context" to "context:<user_id>"
-	if section == "context" {
-		userID := cfg.UserID
-		if userID == "" {
-			userID = cfg.Name
+	var report *board.SyncReport
+	if pullOnly {
+		report, err = board.Pull(cfg)
+	} else {
+		report, err = board.Sync(cfg)
+	}
+	if report != nil {
+		for _, s := range report.Pulled {
+			fmt.Printf("⬇️  %s\n", s)
+		}
+		for _, s := range report.Merged {
+			fmt.Printf("🔀 %s (merged with your local edits)\n", s)
+		}
+		for _, s := range report.Pushed {
+			fmt.Printf("⬆️  %s\n", s)
+		}
+		for _, s := range report.Conflicts {
+			fmt.Printf("⚠️  %s has conflicts - resolve %s, then run: claw board sync\n", s, board.WorkingPath(s))
 		}
-		section = "context:" + userID
+		for _, path := range report.Untracked {
+			fmt.Printf("❓ %s is not a board section (create sections with: claw board update)\n", path)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("sync failed: %w", err)
+	}
+
+	if len(report.Pulled)+len(report.Merged)+len(report.Pushed)+len(report.Conflicts) == 0 {
+		fmt.Println("Board is up to date.")
+	} else {
+		fmt.Println("✅ Synced .claw/board/")
+	}
+	return nil
+}
+
+func runBoardUpdate(cmd *cobra.Command, args []string) error {
+	cfg, err := account.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if !cfg.LoggedIn || cfg.TeamID == "" {
+		return fmt.Errorf("not logged in or team not configured")
 	}
 
+	// Auto-expand "context" to "context:<user_id>"
+	section := expandSection(cfg, args[0])
+
 	var content string
 	if len(args) > 1 {
 		content = args[1]
+	} else if boardFile != "" {
+		data, err := os.ReadFile(boardFile)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", boardFile, err)
+		}
+		content = strings.TrimSpace(string(data))
 	} else {
 		// Read from stdin
 		data, err := io.ReadAll(os.Stdin)
@@ -157,18 +491,166 @@ func runBoardUpdate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("content is required")
 	}
 
-	bs, err := account.UpdateBoardSection(cfg, section, content)
+	base, err := updateBase(cfg, section)
+	if err != nil {
+		return err
+	}
+	return pushBoardSection(cfg, section, content, base)
+}
+
+// updateBase returns the version content for section was edited from: the
+// one given with --base, or the mirror's base for its own working copy.
+// Other content could have been written against any version, so merging it
+// with a stale mirror base would make up conflicts; it's only accepted if
+// the mirror is current.
+func updateBase(cfg *account.Config, section string) (*account.BoardSection, error) {
+	if boardBase > 0 {
+		return account.GetBoardRevision(cfg, section, boardBase)
+	}
+
+	base, err := board.LoadBase(section)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load local base: %w", err)
+	}
+	if base == nil || (boardFile != "" && samePath(boardFile, board.WorkingPath(section))) {
+		return base, nil
+	}
+
+	current, err := account.GetBoardSection(cfg, section)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get section: %w", err)
+	}
+	if current != nil && current.Version != base.Version {
+		return nil, fmt.Errorf("%w: '%s' is at v%d but the local copy is v%d; pass --base <version you edited> to merge, or use: claw board edit %s",
+			account.ErrVersionConflict, section, current.Version, base.Version, section)
+	}
+	return base, nil
+}
+
+func samePath(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && absA == absB
+}
+
+// pushBoardSection writes a section with conflict detection and three-way merge
+func pushBoardSection(cfg *account.Config, section, content string, base *account.BoardSection) error {
+	if diff.HasConflictMarkers(content) && !forceUpdate {
+		return fmt.Errorf("content still has conflict markers - resolve them first (or use --force)")
+	}
+
+	result, err := board.Update(cfg, section, content, base)
 	if err != nil {
+		var conflict *board.ConflictError
+		if errors.As(err, &conflict) {
+			path, werr := board.WriteWorking(section, conflict.Merged)
+			if werr != nil {
+				return fmt.Errorf("%w (and failed to write conflict file: %v)", err, werr)
+			}
+			fmt.Printf("⚠️  '%s' was changed by %s (v%d) while you were editing.\n\n",
+				section, conflict.Theirs.UpdatedBy, conflict.Theirs.Version)
+			fmt.Println(conflict.Merged)
+			fmt.Printf("Resolve the conflicts in %s, then run:\n", path)
+			fmt.Printf("  claw board update %s --file %s\n", section, path)
+			return err
+		}
 		return fmt.Errorf("failed to update section: %w", err)
 	}
 
+	bs := result.Section
+	if len(result.MergedBy) > 0 {
+		fmt.Printf("Merged concurrent changes by %s\n", strings.Join(result.MergedBy, ", "))
+	}
 	fmt.Printf("Updated section '%s' (v%d)\n", bs.Section, bs.Version)
+	switch result.Local {
+	case board.LocalMerged:
+		fmt.Printf("Merged the new version into your unpushed edits in %s\n", board.WorkingPath(section))
+	case board.LocalConflict:
+		fmt.Printf("⚠️  Your unpushed edits in %s conflict with the new version - resolve the markers there\n", board.WorkingPath(section))
+	case board.LocalKept:
+		fmt.Printf("⚠️  Left %s alone: it has edits that aren't in the new version\n", board.WorkingPath(section))
+	}
 	return nil
 }
 
 func runBoardEdit(cmd *cobra.Command, args []string) error {
-	// Same as update but always reads from stdin
-	return runBoardUpdate(cmd, args)
+	cfg, err := account.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if !cfg.LoggedIn || cfg.TeamID == "" {
+		return fmt.Errorf("not logged in or team not configured")
+	}
+
+	section := expandSection(cfg, args[0])
+
+	current, err := account.GetBoardSection(cfg, section)
+	if err != nil {
+		return fmt.Errorf("failed to get section: %w", err)
+	}
+	if current == nil {
+		current = &account.BoardSection{Section: section}
+	}
+
+	editor := os.Getenv("EDITOR")
+	interactive := isTerminal(os.Stdin)
+
+	if !interactive && !checkoutOnly {
+		// Piped content - same as update, based on the version just fetched
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("failed to read stdin: %w", err)
+		}
+		content := strings.TrimSpace(string(data))
+		if content == "" {
+			return fmt.Errorf("content is required")
+		}
+		return pushBoardSection(cfg, section, content, current)
+	}
+
+	// Record the version being edited as the merge base. Unsynced edits in the
+	// working copy are merged forward rather than overwritten.
+	report, err := board.Apply([]account.BoardSection{*current}, false)
+	if err != nil {
+		return fmt.Errorf("failed to update local copy: %w", err)
+	}
+	if len(report.Conflicts) > 0 {
+		fmt.Println("⚠️  Your unsynced local edits conflict with the current version - resolve the markers.")
+	}
+	path := board.WorkingPath(section)
+
+	if checkoutOnly || editor == "" {
+		fmt.Printf("Base v%d saved. Edit the working copy:\n  %s\n", current.Version, path)
+		fmt.Printf("Then run: claw board update %s --file %s\n", args[0], path)
+		return nil
+	}
+
+	parts := strings.Fields(editor)
+	editCmd := exec.Command(parts[0], append(parts[1:], path)...)
+	editCmd.Stdin, editCmd.Stdout, editCmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := editCmd.Run(); err != nil {
+		return fmt.Errorf("editor failed: %w", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	content := strings.TrimSpace(string(data))
+	if content == strings.TrimSpace(current.Content) {
+		fmt.Println("No changes.")
+		return nil
+	}
+	if content == "" {
+		return fmt.Errorf("content is required")
+	}
+	return pushBoardSection(cfg, section, content, current)
+}
+
+// isTerminal reports whether f is an interactive terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
 }
 
 func runBoardInit(cmd *cobra.Command, args []string) error {
@@ -180,11 +662,380 @@ func runBoardInit(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("not logged in or team not configured")
 	}
 
-	if err := account.InitBoard(cfg, args); err != nil {
-		return fmt.Errorf("failed to initialize board: %w", err)
+	if templateName == "" {
+		if len(args) == 0 {
+			return fmt.Errorf("list the team members, e.g.: claw board init eduardo jared")
+		}
+		if err := account.InitBoard(cfg, args); err != nil {
+			return fmt.Errorf("failed to initialize board: %w", err)
+		}
+		fmt.Printf("Board initialized for team '%s' with members: %s\n", cfg.TeamID, strings.Join(args, ", "))
+		fmt.Println("View it with: claw board")
+		return nil
+	}
+
+	tmpl, err := board.LoadTemplate(templateName)
+	if err != nil {
+		return err
+	}
+	sections, err := tmpl.Expand(cfg.TeamID, args)
+	if err != nil {
+		return err
+	}
+
+	existing := make(map[string]bool)
+	current, err := account.GetBoard(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to get board: %w", err)
+	}
+	for _, s := range current {
+		existing[s.Section] = true
+	}
+
+	created, skipped := 0, 0
+	for _, s := range sections {
+		if existing[s.Section] && !overwriteSections {
+			fmt.Printf("  ⏭️  %s (exists)\n", s.Section)
+			skipped++
+			continue
+		}
+		if _, err := account.UpdateBoardSection(cfg, s.Section, s.Content); err != nil {
+			return fmt.Errorf("failed to create section %s: %w", s.Section, err)
+		}
+		fmt.Printf("  ✅ %s\n", s.Section)
+		created++
 	}
 
-	fmt.Printf("Board initialized for team '%s' with members: %s\n", cfg.TeamID, strings.Join(args, ", "))
+	fmt.Printf("\nBoard initialized for team '%s' from template '%s' (%d created", cfg.TeamID, tmpl.Name, created)
+	if skipped > 0 {
+		fmt.Printf(", %d skipped - use --overwrite to replace", skipped)
+	}
+	fmt.Println(")")
 	fmt.Println("View it with: claw board")
 	return nil
 }
+
+func runBoardTemplates(cmd *cobra.Command, args []string) error {
+	templates, err := board.ListTemplates()
+	if err != nil {
+		return fmt.Errorf("failed to list templates: %w", err)
+	}
+
+	for _, t := range templates {
+		fmt.Printf("  %-12s %s\n", t.Name, t.Description)
+		if t.Source != "built-in" {
+			fmt.Printf("  %-12s (%s)\n", "", t.Source)
+		}
+	}
+	fmt.Printf("\nAdd your own in %s/<name>.json\n", board.GetTemplatesDir())
+	return nil
+}
+
+func runBoardHistory(cmd *cobra.Command, args []string) error {
+	cfg, err := account.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if !cfg.LoggedIn || cfg.TeamID == "" {
+		return fmt.Errorf("not logged in or team not configured")
+	}
+
+	section := expandSection(cfg, args[0])
+	revisions, err := account.GetBoardHistory(cfg, section)
+	if err != nil {
+		return fmt.Errorf("failed to get history: %w", err)
+	}
+	if len(revisions) == 0 {
+		fmt.Printf("No history for section '%s'.\n", section)
+		return nil
+	}
+
+	fmt.Printf("History of '%s':\n\n", section)
+	for i, rev := range revisions {
+		// Summarize each version by its change from the previous (older) one
+		prev := ""
+		if i+1 < len(revisions) {
+			prev = revisions[i+1].Content
+		}
+		added, removed := diff.Stats(diff.Compute(diff.Lines(prev), diff.Lines(rev.Content)))
+		fmt.Printf("  v%-4d %s  by %-15s +%d -%d\n",
+			rev.Version, rev.UpdatedAt.Format("2006-01-02 15:04"), rev.UpdatedBy, added, removed)
+	}
+	fmt.Printf("\nCompare: claw board diff %s v<from> v<to>\n", args[0])
+	return nil
+}
+
+func runBoardDiff(cmd *cobra.Command, args []string) error {
+	cfg, err := account.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if !cfg.LoggedIn || cfg.TeamID == "" {
+		return fmt.Errorf("not logged in or team not configured")
+	}
+
+	section := expandSection(cfg, args[0])
+	fromVersion, err := parseVersion(args[1])
+	if err != nil {
+		return err
+	}
+	from, err := account.GetBoardRevision(cfg, section, fromVersion)
+	if err != nil {
+		return err
+	}
+
+	var to *account.BoardSection
+	if len(args) == 3 {
+		toVersion, err := parseVersion(args[2])
+		if err != nil {
+			return err
+		}
+		if to, err = account.GetBoardRevision(cfg, section, toVersion); err != nil {
+			return err
+		}
+	} else {
+		if to, err = account.GetBoardSection(cfg, section); err != nil {
+			return fmt.Errorf("failed to get section: %w", err)
+		}
+		if to == nil {
+			return fmt.Errorf("section '%s' not found", section)
+		}
+	}
+
+	out := diff.Unified(
+		fmt.Sprintf("%s v%d (%s)", section, from.Version, from.UpdatedBy),
+		fmt.Sprintf("%s v%d (%s)", section, to.Version, to.UpdatedBy),
+		from.Content, to.Content, 3)
+	if out == "" {
+		fmt.Printf("No changes between v%d and v%d.\n", from.Version, to.Version)
+		return nil
+	}
+	fmt.Print(out)
+	return nil
+}
+
+func runBoardRevert(cmd *cobra.Command, args []string) error {
+	cfg, err := account.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if !cfg.LoggedIn || cfg.TeamID == "" {
+		return fmt.Errorf("not logged in or team not configured")
+	}
+
+	section := expandSection(cfg, args[0])
+	version, err := parseVersion(args[1])
+	if err != nil {
+		return err
+	}
+
+	rev, err := account.GetBoardRevision(cfg, section, version)
+	if err != nil {
+		return err
+	}
+	current, err := account.GetBoardSection(cfg, section)
+	if err != nil {
+		return fmt.Errorf("failed to get section: %w", err)
+	}
+	if current != nil && current.Content == rev.Content {
+		fmt.Printf("Section '%s' already matches v%d.\n", section, version)
+		return nil
+	}
+
+	// Only replace the version just compared against
+	baseVersion := 0
+	if current != nil {
+		baseVersion = current.Version
+	}
+	bs, err := account.PutBoardSection(cfg, section, rev.Content, baseVersion)
+	if errors.Is(err, account.ErrVersionConflict) {
+		return fmt.Errorf("failed to revert section: %w; check 'claw board history %s' and retry", err, section)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to revert section: %w", err)
+	}
+
+	fmt.Printf("Reverted '%s' to v%d (saved as v%d)\n", bs.Section, version, bs.Version)
+	return nil
+}
+
+func runBoardWatch(cmd *cobra.Command, args []string) error {
+	cfg, err := account.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if !cfg.LoggedIn || cfg.TeamID == "" {
+		return fmt.Errorf("not logged in or team not configured")
+	}
+	if watchInterval < time.Second {
+		return fmt.Errorf("--interval must be at least 1s")
+	}
+
+	var sections []string
+	for _, arg := range args {
+		sections = append(sections, expandSection(cfg, arg))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	opts := board.WatchOptions{
+		Sections: sections,
+		Interval: watchInterval,
+		OnPoll: func() {
+			if !watchJSON {
+				fmt.Fprintf(os.Stderr, "Live updates not available, polling every %s\n", watchInterval)
+			}
+		},
+		OnError: func(err error) {
+			fmt.Fprintf(os.Stderr, "⚠️  %v\n", err)
+		},
+	}
+
+	if !watchJSON {
+		target := "the board"
+		if len(sections) > 0 {
+			target = strings.Join(sections, ", ")
+		}
+		fmt.Fprintf(os.Stderr, "👀 Watching %s (Ctrl+C to stop)\n", target)
+	}
+
+	return board.Watch(ctx, cfg, opts, func(c board.Change) {
+		printBoardChange(c)
+		if watchExec != "" {
+			if err := runWatchExec(ctx, c); err != nil {
+				fmt.Fprintf(os.Stderr, "⚠️  --exec failed for %s: %v\n", c.Current.Section, err)
+			}
+		}
+	})
+}
+
+func printBoardChange(c board.Change) {
+	prevContent, prevVersion := "", 0
+	if c.Previous != nil {
+		prevContent, prevVersion = board.Display(c.Previous.Content), c.Previous.Version
+	}
+	content := board.Display(c.Current.Content)
+
+	if watchJSON {
+		out := map[string]interface{}{
+			"section":      c.Current.Section,
+			"version":      c.Current.Version,
+			"prev_version": prevVersion,
+			"updated_by":   c.Current.UpdatedBy,
+			"updated_at":   c.Current.UpdatedAt,
+			"content":      content,
+			"diff": diff.Unified(fmt.Sprintf("%s v%d", c.Current.Section, prevVersion),
+				fmt.Sprintf("%s v%d", c.Current.Section, c.Current.Version), prevContent, content, 3),
+		}
+		data, _ := json.Marshal(out)
+		fmt.Println(string(data))
+		return
+	}
+
+	fmt.Printf("\n🔔 [%s] %s updated by %s (v%d → v%d)\n", c.Current.UpdatedAt.Local().Format("15:04:05"),
+		c.Current.Section, c.Current.UpdatedBy, prevVersion, c.Current.Version)
+	fmt.Print(diff.Unified(fmt.Sprintf("%s v%d", c.Current.Section, prevVersion),
+		fmt.Sprintf("%s v%d", c.Current.Section, c.Current.Version), prevContent, content, 2))
+}
+
+// runWatchExec runs the --exec command for a change, with the new content on stdin
+func runWatchExec(ctx context.Context, c board.Change) error {
+	prevVersion := 0
+	if c.Previous != nil {
+		prevVersion = c.Previous.Version
+	}
+
+	execCmd := exec.CommandContext(ctx, "sh", "-c", watchExec)
+	execCmd.Stdin = strings.NewReader(c.Current.Content)
+	execCmd.Stdout, execCmd.Stderr = os.Stdout, os.Stderr
+	execCmd.Env = append(os.Environ(),
+		"CLAW_SECTION="+c.Current.Section,
+		"CLAW_VERSION="+strconv.Itoa(c.Current.Version),
+		"CLAW_PREV_VERSION="+strconv.Itoa(prevVersion),
+		"CLAW_UPDATED_BY="+c.Current.UpdatedBy,
+	)
+	return execCmd.Run()
+}
+
+// editEntries loads the config and applies fn to a typed section
+func editEntries(section string, fn func(cfg *account.Config, data *board.SectionData) error) (*account.BoardSection, error) {
+	cfg, err := account.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	if !cfg.LoggedIn || cfg.TeamID == "" {
+		return nil, fmt.Errorf("not logged in or team not configured")
+	}
+
+	bs, err := board.EditEntries(cfg, section, func(data *board.SectionData) error {
+		return fn(cfg, data)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update %s: %w", section, err)
+	}
+	return bs, nil
+}
+
+func runBoardDecide(cmd *cobra.Command, args []string) error {
+	var dec board.Decision
+	bs, err := editEntries(board.SchemaDecisions, func(cfg *account.Config, data *board.SectionData) error {
+		dec = *data.AddDecision(args[0], decideWhy, cfg.MemberName())
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Recorded decision %s: %s (v%d)\n", dec.ID, dec.Title, bs.Version)
+	return nil
+}
+
+func runBoardAsk(cmd *cobra.Command, args []string) error {
+	var q board.Question
+	bs, err := editEntries(board.SchemaQuestions, func(cfg *account.Config, data *board.SectionData) error {
+		q = *data.AddQuestion(args[0], cfg.MemberName(), askTo)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Asked %s (v%d)\n", q.ID, bs.Version)
+	if q.Assignee != "" {
+		fmt.Printf("Let them know with: claw notify %s \"Question %s on the board\" --type question\n", q.Assignee, q.ID)
+	}
+	return nil
+}
+
+func runBoardAnswer(cmd *cobra.Command, args []string) error {
+	var q board.Question
+	bs, err := editEntries(board.SchemaQuestions, func(cfg *account.Config, data *board.SectionData) error {
+		answered, err := data.AnswerQuestion(args[0], args[1], cfg.MemberName())
+		if err != nil {
+			return err
+		}
+		q = *answered
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Answered %s (v%d)\n", q.ID, bs.Version)
+	return nil
+}
+
+func runBoardSetStatus(cmd *cobra.Command, args []string) error {
+	var member string
+	bs, err := editEntries(board.SchemaStatus, func(cfg *account.Config, data *board.SectionData) error {
+		member = statusMember
+		if member == "" {
+			member = cfg.MemberName()
+		}
+		data.SetStatus(member, args[0])
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Status for %s updated (v%d)\n", member, bs.Version)
+	return nil
+}
@@ -1,14 +1,23 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/epuerta9/claw2claw/internal/account"
+	"github.com/epuerta9/claw2claw/internal/manifest"
+	"github.com/epuerta9/claw2claw/internal/safereader"
 	"github.com/spf13/cobra"
 )
 
+var sharePlaintext bool
+
+// sharedDir is where team files are downloaded to
+const sharedDir = ".claw/shared"
+
 func newShareCmd() *cobra.Command {
-	return &cobra.Command{
+	shareCmd := &cobra.Command{
 		Use:   "share <file>",
 		Short: "Upload a file to the shared team board",
 		Long: `Upload a file to the shared team board for other members to access.
@@ -16,12 +25,20 @@ func newShareCmd() *cobra.Command {
 Unlike p2p transfers (claw send), shared files are stored on the relay
 server and available to all team members.
 
+Files are encrypted locally with a random per-file key, which is wrapped to
+each member's public key (see claw keys). The server only stores ciphertext
+and wrapped keys - not even the filename. Members without a published key
+can't read files shared before they ran 'claw keys init'.
+
 Example:
   c2c share ./schema.ts
-  c2c share ./architecture.md`,
+  c2c share ./architecture.md
+  c2c share ./public-notes.md --plaintext`,
 		Args: cobra.ExactArgs(1),
 		RunE: runShare,
 	}
+	shareCmd.Flags().BoolVar(&sharePlaintext, "plaintext", false, "Upload without end-to-end encryption")
+	return shareCmd
 }
 
 func newFilesCmd() *cobra.Command {
@@ -53,12 +70,21 @@ func runShare(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("not logged in or team not configured")
 	}
 
-	sf, err := account.UploadFile(cfg, filePath)
+	upload := account.UploadFile
+	if sharePlaintext {
+		upload = account.UploadPlaintextFile
+	}
+	sf, err := upload(cfg, filePath)
 	if err != nil {
 		return fmt.Errorf("upload failed: %w", err)
 	}
 
-	fmt.Printf("Uploaded: %s (%d bytes)\n", sf.Filename, sf.Size)
+	if sf.Encrypted {
+		fmt.Printf("🔒 Uploaded encrypted: %s (%d bytes)\n", sf.Filename, sf.Size)
+		printUnreadable(sf)
+	} else {
+		fmt.Printf("Uploaded: %s (%d bytes)\n", sf.Filename, sf.Size)
+	}
 	fmt.Printf("File ID: %s\n", sf.ID)
 	fmt.Println("Team members can download with: claw download", sf.ID)
 	return nil
@@ -87,8 +113,12 @@ func runFiles(cmd *cobra.Command, args []string) error {
 	fmt.Println("Shared files:")
 	fmt.Println()
 	for _, f := range files {
+		name := f.Filename
+		if f.IsEncrypted() {
+			name = "🔒 (encrypted)"
+		}
 		fmt.Printf("  %-30s %8d bytes  by %s  %s\n",
-			f.Filename, f.Size, f.UploadedBy, f.CreatedAt.Format("2006-01-02 15:04"))
+			name, f.Size, f.UploadedBy, f.CreatedAt.Format("2006-01-02 15:04"))
 		fmt.Printf("  ID: %s\n\n", f.ID)
 	}
 	fmt.Println("Download: claw download <file-id>")
@@ -106,12 +136,86 @@ func runDownload(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("not logged in or team not configured")
 	}
 
-	outDir := ".claw/shared"
-	path, err := account.DownloadFile(cfg, fileID, outDir)
+	// Best effort: the listing knows who uploaded it
+	from := ""
+	if files, err := account.ListFiles(cfg); err == nil {
+		for _, f := range files {
+			if f.ID == fileID {
+				from = f.UploadedBy
+			}
+		}
+	}
+
+	d, err := downloadTeamFile(cfg, fileID, from)
 	if err != nil {
+		if errors.Is(err, account.ErrNoIdentity) {
+			return fmt.Errorf("file is encrypted and this profile has no key - run: claw keys init, then ask the sender to share it again")
+		}
 		return fmt.Errorf("download failed: %w", err)
 	}
 
-	fmt.Printf("Downloaded: %s\n", path)
+	fmt.Printf("Downloaded: %s\n", d.Scan.Path)
+	if d.Scan.Filename != d.Name {
+		fmt.Printf("   (renamed from %q)\n", d.Name)
+	}
+	fmt.Println("Read safely with: claw read", d.Scan.Filename)
 	return nil
 }
+
+// downloadTeamFile saves a shared file to .claw/shared and records where it came from
+func downloadTeamFile(cfg *account.Config, fileID, from string) (*account.DownloadedFile, error) {
+	d, err := account.DownloadFile(cfg, fileID, sharedDir)
+	if err != nil {
+		return nil, err
+	}
+
+	if d.Encrypted {
+		fmt.Println("🔓 Decrypted locally")
+	} else if !d.Verified {
+		fmt.Println("⚠️  Server sent no content hash, integrity not verified")
+	}
+	printScanWarnings(d.Scan)
+
+	recordProvenance(d.Scan, d.Name, manifest.Provenance{
+		Source:    manifest.SourceTeam,
+		SourceID:  fileID,
+		From:      from,
+		Encrypted: d.Encrypted,
+		Verified:  d.Verified,
+	})
+	return d, nil
+}
+
+// recordProvenance records a received file in the manifest with its origin
+// and safereader findings
+func recordProvenance(sc *safereader.SafeContent, originalName string, p manifest.Provenance) {
+	m, err := manifest.Load()
+	if err == nil {
+		m.RecordScanned(sc, originalName, p)
+		err = m.Save()
+	}
+	if err != nil {
+		fmt.Printf("⚠️  Failed to update manifest: %v\n", err)
+	}
+}
+
+// printScanWarnings flags received content that looks like prompt injection
+func printScanWarnings(sc *safereader.SafeContent) {
+	if sc.IsSafe {
+		return
+	}
+	fmt.Printf("🚨 %s contains patterns that may be prompt injection:\n", sc.Filename)
+	for _, w := range sc.Warnings {
+		fmt.Printf("   • %s\n", w)
+	}
+	fmt.Println("   Only read it through: claw read", sc.Filename)
+}
+
+// printUnreadable warns about team members who can't decrypt an upload
+func printUnreadable(sf *account.SharedFile) {
+	if len(sf.Unreadable) == 0 {
+		return
+	}
+	fmt.Printf("⚠️  No key published by: %s\n", strings.Join(sf.Unreadable, ", "))
+	fmt.Println("   They can't read this file. Ask them to run: claw keys init, then share again.")
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/epuerta9/claw2claw/internal/gitpatch"
+	"github.com/epuerta9/claw2claw/internal/manifest"
+	"github.com/epuerta9/claw2claw/internal/safereader"
+	"github.com/spf13/cobra"
+)
+
+var (
+	sendGit     bool // For send - send git changes as a patch bundle
+	gitStaged   bool // Staged changes only
+	gitWorktree bool // All uncommitted changes
+
+	applyBranch string
+	applyDir    string
+	applyYes    bool
+)
+
+// writeGitPatch builds a patch bundle from the current repository and writes
+// it to a temp file for sending. cleanup removes it.
+func writeGitPatch(rangeSpec string) (path string, cleanup func(), err error) {
+	opts := gitpatch.Options{Mode: gitpatch.ModeWorktree}
+	switch {
+	case rangeSpec != "" && (gitStaged || gitWorktree), gitStaged && gitWorktree:
+		return "", nil, fmt.Errorf("use one of a range, --staged or --worktree")
+	case rangeSpec != "":
+		opts = gitpatch.Options{Mode: gitpatch.ModeRange, Range: rangeSpec}
+	case gitStaged:
+		opts.Mode = gitpatch.ModeStaged
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", nil, err
+	}
+	repo, err := gitpatch.OpenRepo(cwd)
+	if err != nil {
+		return "", nil, err
+	}
+	p, err := gitpatch.Create(repo, opts)
+	if err != nil {
+		return "", nil, err
+	}
+	data, err := p.Marshal()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to encode patch: %w", err)
+	}
+
+	dir, err := os.MkdirTemp("", "claw-patch-")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup = func() { os.RemoveAll(dir) }
+
+	path = filepath.Join(dir, p.FileName())
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+
+	fmt.Printf("🌿 Git patch: %s\n", p.Summary())
+	if len(p.Untracked) > 0 {
+		fmt.Printf("⚠️  %d untracked file(s) not included - git add them to send them\n", len(p.Untracked))
+	}
+	return path, cleanup, nil
+}
+
+func newApplyCmd() *cobra.Command {
+	applyCmd := &cobra.Command{
+		Use:   "apply <file>",
+		Short: "Apply a received git patch on a new branch",
+		Long: `Apply a patch bundle sent with 'claw send --git'.
+
+The patch is shown (with prompt injection warnings) before anything is
+applied. It is applied with 'git apply --3way' on a new branch based on the
+sender's base commit, checked out in a separate worktree - your current
+checkout and branch are never touched. The base commit must exist locally;
+fetch it first if it doesn't.
+
+Examples:
+  claw apply feature-login-range-3f2a9c1d0e.clawpatch
+  claw apply fix.clawpatch --branch review/fix --dir ../fix-review
+  claw apply fix.clawpatch --yes`,
+		Args: cobra.ExactArgs(1),
+		RunE: runApply,
+	}
+	applyCmd.Flags().StringVar(&applyBranch, "branch", "", "Branch to create (default: claw/<branch>-<base>)")
+	applyCmd.Flags().StringVar(&applyDir, "dir", "", "Worktree directory (default: inside .git/claw/worktrees)")
+	applyCmd.Flags().BoolVarP(&applyYes, "yes", "y", false, "Apply without asking (required when not interactive)")
+	return applyCmd
+}
+
+func runApply(cmd *cobra.Command, args []string) error {
+	filePath, err := receivedPath(args[0])
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+	p, ok := gitpatch.Parse(data)
+	if !ok {
+		return fmt.Errorf("%s is not a git patch (send one with: claw send --git)", args[0])
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	repo, err := gitpatch.OpenRepo(cwd)
+	if err != nil {
+		return err
+	}
+	if !repo.HasCommit(p.Base) {
+		return &gitpatch.MissingBaseError{Base: p.Base, Remote: p.Remote}
+	}
+
+	// The diff comes from another user: show it wrapped and scanned
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return err
+	}
+	sc := safereader.Scan(filePath, []byte(p.Render()), info.ModTime())
+	fmt.Print(sc.Content)
+	if m, err := manifest.Load(); err == nil {
+		m.MarkRead(sc.Path)
+		m.Save()
+	}
+
+	if !applyYes {
+		if !isTerminal(os.Stdin) {
+			return fmt.Errorf("review the patch above, then re-run with --yes to apply it")
+		}
+		fmt.Print("\nApply on a new branch? [y/N] ")
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if a := strings.ToLower(strings.TrimSpace(answer)); a != "y" && a != "yes" {
+			fmt.Println("Not applied.")
+			return nil
+		}
+	}
+
+	result, err := gitpatch.Apply(repo, p, gitpatch.ApplyOptions{Branch: applyBranch, Dir: applyDir})
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	switch {
+	case len(result.Conflicts) > 0:
+		fmt.Printf("⚠️  Applied with conflicts on branch %s\n", result.Branch)
+		for _, f := range result.Conflicts {
+			fmt.Printf("   • %s\n", f)
+		}
+		fmt.Printf("Resolve them in: %s\n", result.Worktree)
+	case !result.Committed:
+		fmt.Printf("✅ Applied on branch %s (changes staged, commit failed)\n", result.Branch)
+		fmt.Printf("Commit them in: %s\n", result.Worktree)
+	default:
+		fmt.Printf("✅ Applied and committed on branch %s\n", result.Branch)
+		fmt.Printf("📂 Worktree: %s\n", result.Worktree)
+	}
+	fmt.Printf("Remove it when done with: git worktree remove %s\n", result.Worktree)
+	return nil
+}
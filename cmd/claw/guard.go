@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/epuerta9/claw2claw/internal/hooks"
+	"github.com/spf13/cobra"
+)
+
+func newGuardCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "guard",
+		Short: "PreToolUse hook that blocks raw reads of received content",
+		Long: `Claude Code PreToolUse hook, registered by 'claw install'.
+
+Reads the hook JSON on stdin and denies Read and Bash tool calls that open
+files under .claw/received, .claw/channels or .claw/shared, pointing the
+agent at 'claw read' instead, which scans content for prompt injection.
+claw, c2c and ls commands are allowed. Other commands are also denied when
+they name a directory above a guarded path (grep -r x .), including inside
+sh -c scripts and after a cd in the same command line.
+
+Configure the guarded paths in ~/.claw/guard.json, and add to them in
+.claw/guard.json (project). A project config can guard more paths and drop
+allowed commands, but can't loosen your own config:
+
+  {
+    "paths": [".claw/received", ".claw/channels/*", "downloads/untrusted"],
+    "allow_commands": ["claw", "c2c", "ls"]
+  }
+
+Paths are relative to the project and may use glob patterns. On malformed
+hook input the guard warns on stderr and allows the call; an invalid config
+falls back to the defaults.`,
+		Args: cobra.NoArgs,
+		RunE: runGuard,
+	}
+}
+
+func runGuard(cmd *cobra.Command, args []string) error {
+	// A broken guard must never wedge the agent: warn and allow
+	var in hooks.GuardInput
+	if err := json.NewDecoder(os.Stdin).Decode(&in); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  claw guard: invalid hook input: %v\n", err)
+		return nil
+	}
+	if in.Cwd == "" {
+		in.Cwd, _ = os.Getwd()
+	}
+
+	cfg, err := hooks.LoadGuardConfig(in.Cwd)
+	if err != nil {
+		// Keep guarding the default paths rather than none
+		fmt.Fprintf(os.Stderr, "⚠️  claw guard: %v (using defaults)\n", err)
+		cfg, _ = hooks.ParseGuardConfig(nil)
+	}
+
+	out := cfg.Guard(&in)
+	if out == nil {
+		return nil
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetEscapeHTML(false)
+	return enc.Encode(out)
+}
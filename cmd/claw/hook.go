@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/epuerta9/claw2claw/internal/account"
+	"github.com/epuerta9/claw2claw/internal/hooks"
+	"github.com/epuerta9/claw2claw/internal/manifest"
+	"github.com/spf13/cobra"
+)
+
+// sessionStartTimeout bounds the inbox check so session start stays fast
+const sessionStartTimeout = 5 * time.Second
+
+func newHookCmd() *cobra.Command {
+	hookCmd := &cobra.Command{
+		Use:   "hook",
+		Short: "Claude Code hook commands (registered by claw install)",
+	}
+
+	sessionStartCmd := &cobra.Command{
+		Use:   "session-start",
+		Short: "SessionStart hook: inject unread files and inbox as context",
+		Long: `Claude Code SessionStart hook, registered by 'claw install'.
+
+Prints hook JSON whose additionalContext summarizes what happened since the
+last session: unread and updated files in .claw/received, unread
+notifications and board changes (when logged in to a team). Everything that
+came from other users is wrapped in external-content markers.
+
+Prints nothing when there is nothing to report. Never fails the session:
+an unreachable server is noted in the context instead.`,
+		Args: cobra.NoArgs,
+		RunE: runHookSessionStart,
+	}
+
+	hookCmd.AddCommand(sessionStartCmd)
+	return hookCmd
+}
+
+func runHookSessionStart(cmd *cobra.Command, args []string) error {
+	// The payload is optional so the command can be run by hand
+	var in hooks.SessionStartInput
+	if info, err := os.Stdin.Stat(); err == nil && info.Mode()&os.ModeCharDevice == 0 {
+		if err := json.NewDecoder(os.Stdin).Decode(&in); err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  claw hook: invalid hook input: %v\n", err)
+		}
+	}
+	if in.Cwd != "" {
+		if err := os.Chdir(in.Cwd); err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  claw hook: %v\n", err)
+			return nil
+		}
+	}
+
+	m, err := manifest.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  claw hook: failed to load manifest: %v\n", err)
+	}
+	cfg, err := account.LoadConfig()
+	if err != nil {
+		cfg = nil // No account: local files only
+	}
+
+	out := hooks.BuildSessionSummary(m, cfg, sessionStartTimeout).Output()
+	if out == nil {
+		return nil
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetEscapeHTML(false)
+	return enc.Encode(out)
+}
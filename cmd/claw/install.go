@@ -0,0 +1,175 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/epuerta9/claw2claw/internal/hooks"
+	"github.com/spf13/cobra"
+)
+
+var (
+	installProject bool // Install into the project's .claude/ instead of ~/.claude/
+	installDryRun  bool // Show the diff without writing
+)
+
+func newInstallCmd() *cobra.Command {
+	installCmd := &cobra.Command{
+		Use:   "install",
+		Short: "Install Claude Code hooks",
+		Long: `Register claw2claw hooks with Claude Code:
+
+  SessionStart      claw hook session-start   Unread files and inbox as context
+  UserPromptSubmit  claw inbox --quiet ...    New notifications every 30 minutes
+  PreToolUse        claw guard                Block raw reads of received content
+
+Hooks go in ~/.claude/settings.json, or the project's .claude/settings.json
+with --project. Existing settings are merged, never replaced: a settings file
+that isn't valid JSON is left untouched and the install fails. Every file is
+backed up as <file>.bak-<timestamp> before it is written.
+
+What was added is recorded in ~/.claw/install.json so 'claw uninstall'
+removes exactly that. Running install again is safe.
+
+Examples:
+  claw install --dry-run
+  claw install --project`,
+		Args: cobra.NoArgs,
+		RunE: runInstall,
+	}
+	installCmd.Flags().BoolVar(&installProject, "project", false, "Install into .claude/settings.json in the current project")
+	installCmd.Flags().BoolVar(&installDryRun, "dry-run", false, "Show the changes as a diff without writing anything")
+	return installCmd
+}
+
+func newUninstallCmd() *cobra.Command {
+	uninstallCmd := &cobra.Command{
+		Use:   "uninstall",
+		Short: "Remove the Claude Code hooks claw install added",
+		Long: `Remove the hooks 'claw install' added, leaving everything else in the
+settings file as it is. Hooks that were already there before the install
+are kept. Files are backed up before they are written.
+
+Examples:
+  claw uninstall --dry-run
+  claw uninstall --project`,
+		Args: cobra.NoArgs,
+		RunE: runUninstall,
+	}
+	uninstallCmd.Flags().BoolVar(&installProject, "project", false, "Uninstall from .claude/settings.json in the current project")
+	uninstallCmd.Flags().BoolVar(&installDryRun, "dry-run", false, "Show the changes as a diff without writing anything")
+	return uninstallCmd
+}
+
+// installTarget returns the settings directory selected by --project
+func installTarget() (*hooks.Target, error) {
+	if installProject {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+		return hooks.ProjectTarget(cwd)
+	}
+	return hooks.UserTarget()
+}
+
+func runInstall(cmd *cobra.Command, args []string) error {
+	target, err := installTarget()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("📦 Installing Claude Code hooks into %s...\n", target.SettingsPath())
+
+	plan, err := hooks.PlanInstall(target)
+	if err != nil {
+		return fmt.Errorf("failed to install hooks: %w", err)
+	}
+	if installDryRun {
+		printPlanDiff(plan)
+		return nil
+	}
+	if err := applyPlan(plan); err != nil {
+		return fmt.Errorf("failed to install hooks: %w", err)
+	}
+
+	for _, h := range plan.Removed {
+		fmt.Printf("   ➖ %s (replaced)\n", h)
+	}
+	for _, h := range plan.Added {
+		fmt.Printf("   ➕ %s\n", h)
+	}
+	for _, h := range plan.Present {
+		fmt.Printf("   ✓ %s (already installed)\n", h)
+	}
+
+	fmt.Println("✅ Hooks installed!")
+	if !target.Project {
+		fmt.Println("\nYou can now use:")
+		fmt.Println("  /share <file>   - Share a file")
+		fmt.Println("  /receive <code> - Receive a shared file")
+	}
+	fmt.Println("\nNew sessions start with unread files, notifications and board changes,")
+	fmt.Println("and raw reads of .claw/received, .claw/channels and .claw/shared are blocked.")
+	fmt.Println("Remove with: claw uninstall" + projectFlag())
+	return nil
+}
+
+func runUninstall(cmd *cobra.Command, args []string) error {
+	target, err := installTarget()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("🧹 Removing Claude Code hooks from %s...\n", target.SettingsPath())
+
+	plan, err := hooks.PlanUninstall(target)
+	if err != nil {
+		return fmt.Errorf("failed to uninstall hooks: %w", err)
+	}
+	if plan.NoRecord {
+		fmt.Println("   (no install record, removing the default claw hooks)")
+	}
+	if installDryRun {
+		printPlanDiff(plan)
+		return nil
+	}
+	if err := applyPlan(plan); err != nil {
+		return fmt.Errorf("failed to uninstall hooks: %w", err)
+	}
+
+	if len(plan.Changes) == 0 {
+		fmt.Println("✅ Nothing to remove.")
+		return nil
+	}
+	for _, h := range plan.Removed {
+		fmt.Printf("   ➖ %s\n", h)
+	}
+	fmt.Println("✅ Hooks removed.")
+	return nil
+}
+
+// applyPlan writes a plan and lists the backups it made
+func applyPlan(plan *hooks.Plan) error {
+	backups, err := plan.Apply(time.Now())
+	for _, b := range backups {
+		fmt.Printf("   💾 Backup: %s\n", b)
+	}
+	return err
+}
+
+func printPlanDiff(plan *hooks.Plan) {
+	if len(plan.Changes) == 0 {
+		fmt.Println("No changes.")
+		return
+	}
+	fmt.Println()
+	fmt.Print(plan.Diff())
+	fmt.Println("\n(dry run, nothing written)")
+}
+
+func projectFlag() string {
+	if installProject {
+		return " --project"
+	}
+	return ""
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/epuerta9/claw2claw/internal/account"
+	"github.com/epuerta9/claw2claw/internal/crypto"
+	"github.com/spf13/cobra"
+)
+
+var keysRotate bool
+
+func newKeysCmd() *cobra.Command {
+	keysCmd := &cobra.Command{
+		Use:   "keys",
+		Short: "Manage your key for end-to-end encrypted team files",
+		Long: `Manage the key pair used for end-to-end encrypted team files.
+
+Each profile has an X25519 key pair. The private key stays on this machine
+(in the secret store when one is set up, otherwise ~/.claw/keys/, mode 0600);
+the public key is published to the team key directory so others can
+encrypt shared files to you.
+
+Members' keys are pinned the first time they are seen. If a key changes,
+uploads stop until you verify the new fingerprint with that person and
+run 'claw keys trust <user>'.`,
+		RunE: runKeysShow,
+	}
+
+	initCmd := &cobra.Command{
+		Use:   "init",
+		Short: "Create your key pair and publish the public key",
+		RunE:  runKeysInit,
+	}
+	initCmd.Flags().BoolVar(&keysRotate, "rotate", false, "Replace an existing key (files shared to the old key become unreadable)")
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List team members' published keys and their trust state",
+		RunE:  runKeysList,
+	}
+
+	trustCmd := &cobra.Command{
+		Use:   "trust <user>",
+		Short: "Accept a team member's current key after verifying its fingerprint",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runKeysTrust,
+	}
+
+	keysCmd.AddCommand(initCmd, listCmd, trustCmd)
+	return keysCmd
+}
+
+func loadTeamConfig() (*account.Config, error) {
+	cfg, err := account.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	if !cfg.LoggedIn || cfg.TeamID == "" {
+		return nil, fmt.Errorf("not logged in or team not configured")
+	}
+	return cfg, nil
+}
+
+func runKeysShow(cmd *cobra.Command, args []string) error {
+	cfg, err := account.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	id, err := account.LoadIdentity(cfg)
+	if err != nil {
+		if errors.Is(err, account.ErrNoIdentity) {
+			fmt.Println("No key yet for profile", cfg.Profile())
+			fmt.Println("Create one: claw keys init")
+			return nil
+		}
+		return err
+	}
+
+	fmt.Printf("🔑 Profile:     %s\n", cfg.Profile())
+	fmt.Printf("   Fingerprint: %s\n", id.Fingerprint())
+	fmt.Println()
+	fmt.Println("Compare fingerprints with teammates over a channel you trust.")
+	return nil
+}
+
+func runKeysInit(cmd *cobra.Command, args []string) error {
+	cfg, err := loadTeamConfig()
+	if err != nil {
+		return err
+	}
+
+	id, err := account.LoadIdentity(cfg)
+	switch {
+	case err == nil && !keysRotate:
+		fmt.Println("Key already exists, publishing it again")
+	case err == nil || errors.Is(err, account.ErrNoIdentity):
+		if id, err = account.CreateIdentity(cfg); err != nil {
+			return fmt.Errorf("failed to create key: %w", err)
+		}
+		if keysRotate {
+			fmt.Println("⚠️  Key rotated - files shared to your old key can no longer be decrypted")
+		}
+	default:
+		return err
+	}
+
+	if err := account.PublishKey(cfg, id.PublicKey); err != nil {
+		return fmt.Errorf("failed to publish key: %w", err)
+	}
+
+	fmt.Printf("✅ Published key for %s\n", cfg.Profile())
+	fmt.Printf("   Fingerprint: %s\n", id.Fingerprint())
+	return nil
+}
+
+func runKeysList(cmd *cobra.Command, args []string) error {
+	cfg, err := loadTeamConfig()
+	if err != nil {
+		return err
+	}
+
+	keys, err := account.GetTeamKeys(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to get team keys: %w", err)
+	}
+
+	published := make(map[string]bool)
+	sort.Slice(keys, func(i, j int) bool { return keys[i].UserID < keys[j].UserID })
+
+	fmt.Printf("Team keys (%s):\n\n", cfg.TeamID)
+	for _, k := range keys {
+		published[k.UserID] = true
+		pub, err := base64.StdEncoding.DecodeString(k.PublicKey)
+		if err != nil {
+			fmt.Printf("  ❌ %-20s invalid key\n", k.UserID)
+			continue
+		}
+
+		status := "new (pinned on next share)"
+		pinned, err := account.PinnedKey(cfg, k.UserID)
+		if err != nil {
+			return err
+		}
+		switch {
+		case pinned == nil:
+		case string(pinned) == string(pub):
+			status = "trusted"
+		default:
+			status = "CHANGED - verify, then: claw keys trust " + k.UserID
+		}
+		fmt.Printf("  %-20s %s  %s\n", k.UserID, crypto.Fingerprint(pub), status)
+	}
+
+	if members, err := account.GetTeamMembers(cfg); err == nil {
+		var missing []string
+		for _, m := range members {
+			if !published[m.UserID] {
+				missing = append(missing, m.UserID)
+			}
+		}
+		if len(missing) > 0 {
+			fmt.Println()
+			fmt.Println("No key published yet:")
+			for _, u := range missing {
+				fmt.Printf("  %s\n", u)
+			}
+		}
+	}
+	return nil
+}
+
+func runKeysTrust(cmd *cobra.Command, args []string) error {
+	cfg, err := loadTeamConfig()
+	if err != nil {
+		return err
+	}
+
+	pub, err := account.TrustKey(cfg, args[0])
+	if err != nil {
+		return fmt.Errorf("failed to trust key: %w", err)
+	}
+
+	fmt.Printf("✅ Trusted %s\n", args[0])
+	fmt.Printf("   Fingerprint: %s\n", crypto.Fingerprint(pub))
+	return nil
+}
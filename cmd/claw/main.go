@@ -3,17 +3,23 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/epuerta9/claw2claw/internal/account"
+	"github.com/epuerta9/claw2claw/internal/agent"
+	"github.com/epuerta9/claw2claw/internal/clawpack"
 	"github.com/epuerta9/claw2claw/internal/client"
+	"github.com/epuerta9/claw2claw/internal/gitpatch"
 	"github.com/epuerta9/claw2claw/internal/hooks"
 	"github.com/epuerta9/claw2claw/internal/manifest"
 	"github.com/epuerta9/claw2claw/internal/safereader"
+	"github.com/epuerta9/claw2claw/internal/transcript"
 	"github.com/spf13/cobra"
 )
 
@@ -28,6 +34,9 @@ var (
 	channelName string // For channel create
 	fullContent bool   // For send command - save full content to account
 	privateMode bool   // For send command - metadata only, no content
+	profileName string // Global --profile flag
+	directMode  bool   // Global --direct flag
+	lanMode     bool   // For send/receive - discover the peer on the local network
 )
 
 func main() {
@@ -35,11 +44,16 @@ func main() {
 		Use:   "claw",
 		Short: "Secure peer-to-peer context sharing for Claude",
 		Long:  `claw2claw enables secure, end-to-end encrypted file sharing between Claude users.`,
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			account.SetProfile(profileName)
+		},
 	}
 
 	// Global flags
 	rootCmd.PersistentFlags().StringVar(&relayURL, "relay", "", "Relay server URL")
+	rootCmd.PersistentFlags().StringVar(&profileName, "profile", "", "Account profile to use (overrides CLAW_PROFILE and .claw/config)")
 	rootCmd.PersistentFlags().IntVar(&timeout, "timeout", 300, "Transfer timeout in seconds")
+	rootCmd.PersistentFlags().BoolVar(&directMode, "direct", false, "Transfer over a direct TCP connection when the peer is reachable (relay used for rendezvous, then as fallback)")
 
 	// ========================
 	// Send Command
@@ -50,14 +64,51 @@ func main() {
 		Long: `Send a file securely to another user.
 
 By default, creates an ephemeral room with a memorable code phrase.
-Use --persistent to create a persistent room with a UUID (harder to guess).`,
-		Args: cobra.ExactArgs(1),
+Use --persistent to create a persistent room with a UUID (harder to guess).
+
+With --transcript, sends a Claude Code session as a handoff bundle instead
+of a file: the current project's latest session, or the one named by
+session ID (prefixes work) or .jsonl path. Secrets are stripped and long
+tool outputs trimmed; the receiver's 'claw read' shows it as a summary of
+the prior session.
+
+With --lan, the share is advertised on the local network over mDNS
+(under a random ID) and the receiver connects directly - no relay needed.
+
+With --git, sends git changes as a patch bundle with the base commit and
+branch they apply to: a commit range (A..B, or A for A..HEAD), --staged
+changes, or all uncommitted changes (--worktree, the default). The
+receiver applies it on a new branch with 'claw apply'.
+
+With --background, the transfer is handed to the background agent (see
+'claw agent') and the command returns right away; 'claw jobs' shows when
+the receiver has picked it up.
+
+Examples:
+  c2c send notes.md
+  c2c send --transcript
+  c2c send --transcript 3f2a --last 5
+  c2c send --transcript --turns 4-9 --tool-output summary
+  c2c send --git main..feature
+  c2c send --git --staged
+  c2c send notes.md --lan
+  c2c send notes.md --background`,
+		Args: sendArgs,
 		RunE: runSend,
 	}
 	sendCmd.Flags().BoolVarP(&persistent, "persistent", "p", false, "Create a persistent room (UUID-based, longer lived)")
 	sendCmd.Flags().IntVar(&ttlHours, "ttl", 24, "TTL for persistent rooms in hours (-1 for permanent)")
 	sendCmd.Flags().BoolVar(&fullContent, "full", false, "Save full file content to your account (for later re-reading)")
 	sendCmd.Flags().BoolVar(&privateMode, "private", false, "Metadata only - don't save any content to account")
+	sendCmd.Flags().BoolVar(&sendTranscript, "transcript", false, "Send a Claude Code session transcript as a handoff bundle")
+	sendCmd.Flags().StringVar(&transcriptTurns, "turns", "", "Turn range to include with --transcript (e.g. 3-7, 5-)")
+	sendCmd.Flags().IntVar(&transcriptLast, "last", 0, "Only the last N turns with --transcript")
+	sendCmd.Flags().StringVar(&transcriptOutput, "tool-output", transcript.OutputTrim, "Tool output with --transcript: trim, summary, full or none")
+	sendCmd.Flags().BoolVar(&lanMode, "lan", false, "Share on the local network via mDNS instead of the relay")
+	sendCmd.Flags().BoolVar(&sendGit, "git", false, "Send git changes (a range, --staged or --worktree) as a patch bundle")
+	sendCmd.Flags().BoolVar(&gitStaged, "staged", false, "Only staged changes with --git")
+	sendCmd.Flags().BoolVar(&gitWorktree, "worktree", false, "All uncommitted changes with --git (the default)")
+	sendCmd.Flags().BoolVar(&sendBackground, "background", false, "Hand the transfer to the background agent and return immediately")
 
 	// ========================
 	// Receive Command
@@ -68,22 +119,18 @@ Use --persistent to create a persistent room with a UUID (harder to guess).`,
 		Long: `Receive a shared file using a code phrase or room UUID.
 
 For ephemeral rooms: use the code phrase (e.g., swift-tiger-gold-42)
-For persistent rooms: use --code flag with the UUID`,
+For persistent rooms: use --code flag with the UUID
+For --lan shares: use the code phrase; the sender is found on the local network`,
 		Args: cobra.ExactArgs(1),
 		RunE: runReceive,
 	}
 	receiveCmd.Flags().StringVarP(&outputDir, "output", "o", ".", "Output directory")
 	receiveCmd.Flags().StringVar(&codePhrase, "code", "", "Encryption code (required for persistent rooms)")
+	receiveCmd.Flags().BoolVar(&lanMode, "lan", false, "Find the sender on the local network via mDNS instead of the relay")
 
 	// ========================
 	// Utility Commands
 	// ========================
-	installCmd := &cobra.Command{
-		Use:   "install",
-		Short: "Install Claude Code hooks",
-		RunE:  runInstall,
-	}
-
 	versionCmd := &cobra.Command{
 		Use:   "version",
 		Short: "Print version",
@@ -164,7 +211,17 @@ Unlike one-time transfers, channels persist and both parties can send messages.`
 		RunE:  runChannelList,
 	}
 
-	channelCmd.AddCommand(channelCreateCmd, channelJoinCmd, channelSendCmd, channelListCmd)
+	channelListenCmd := &cobra.Command{
+		Use:   "listen <channel-id>",
+		Short: "Keep receiving a channel's messages in the background",
+		Long: `Hand a joined channel to the background agent, which receives every
+message sent to it into .claw/channels/<channel-id> of this project until
+cancelled. New files show up in 'claw new'.`,
+		Args: cobra.ExactArgs(1),
+		RunE: runChannelListen,
+	}
+
+	channelCmd.AddCommand(channelCreateCmd, channelJoinCmd, channelSendCmd, channelListCmd, channelListenCmd)
 
 	// ========================
 	// Account Commands (Optional - for sync/share)
@@ -227,7 +284,7 @@ Examples:
 	}
 	contextCmd.Flags().BoolVar(&rawOutput, "raw", false, "Skip safety wrapper (use with caution)")
 
-	rootCmd.AddCommand(sendCmd, receiveCmd, installCmd, versionCmd, listCmd, readCmd, newCmd, channelCmd)
+	rootCmd.AddCommand(sendCmd, receiveCmd, versionCmd, listCmd, readCmd, newCmd, channelCmd)
 	rootCmd.AddCommand(loginCmd, logoutCmd, sessionsCmd, openCmd, whoamiCmd, contextCmd)
 
 	// Team + Relay commands (board, notifications, file sharing)
@@ -238,6 +295,18 @@ Examples:
 	rootCmd.AddCommand(newShareCmd())
 	rootCmd.AddCommand(newFilesCmd())
 	rootCmd.AddCommand(newDownloadCmd())
+	rootCmd.AddCommand(newKeysCmd())
+	rootCmd.AddCommand(newMCPCmd())
+	rootCmd.AddCommand(newGuardCmd())
+	rootCmd.AddCommand(newHookCmd())
+	rootCmd.AddCommand(newPackCmd())
+	rootCmd.AddCommand(newApplyCmd())
+	rootCmd.AddCommand(newInstallCmd())
+	rootCmd.AddCommand(newUninstallCmd())
+	rootCmd.AddCommand(newProfileCmd())
+	rootCmd.AddCommand(newSecretsCmd())
+	rootCmd.AddCommand(newAgentCmd())
+	rootCmd.AddCommand(newJobsCmd())
 
 	if err := rootCmd.Execute(); err != nil {
 		os.Exit(1)
@@ -245,13 +314,44 @@ Examples:
 }
 
 func runSend(cmd *cobra.Command, args []string) error {
-	filePath := args[0]
+	var filePath string
+	if sendTranscript {
+		session := ""
+		if len(args) > 0 {
+			session = args[0]
+		}
+		path, cleanup, err := writeTranscriptBundle(session)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+		filePath = path
+	} else if sendGit {
+		rangeSpec := ""
+		if len(args) > 0 {
+			rangeSpec = args[0]
+		}
+		path, cleanup, err := writeGitPatch(rangeSpec)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+		filePath = path
+	} else {
+		filePath = args[0]
+	}
 
 	// Check file exists
 	fileInfo, err := os.Stat(filePath)
 	if os.IsNotExist(err) {
 		return fmt.Errorf("file not found: %s", filePath)
 	}
+	if err := describePack(filePath); err != nil {
+		return err
+	}
+	if sendBackground {
+		return sendInBackground(filePath)
+	}
 
 	// Check if logged in for session tracking
 	acctCfg, _ := account.LoadConfig()
@@ -260,17 +360,30 @@ func runSend(cmd *cobra.Command, args []string) error {
 	code := hooks.GenerateCodePhrase()
 
 	// Create client
-	cfg := client.DefaultConfig()
-	if relayURL != "" {
-		cfg.RelayURL = relayURL
-	}
-	cfg.Timeout = time.Duration(timeout) * time.Second
+	cfg := newClientConfig()
 	c := client.New(cfg)
 
 	ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeout)
 	defer cancel()
 
-	if persistent {
+	if lanMode && persistent {
+		return fmt.Errorf("--lan shares are one-time, they can't use --persistent")
+	}
+
+	if lanMode {
+		// Local network mode - advertised under a random ID, no relay
+		fmt.Printf("📤 Sharing: %s (local network)\n", filepath.Base(filePath))
+		fmt.Printf("🔑 Share code: %s\n", code)
+		fmt.Println("📡 Advertising on the local network (the code is never published)...")
+		fmt.Printf("\n📋 Share with receiver:\n")
+		fmt.Printf("   claw receive %s --lan\n\n", code)
+
+		if err := c.SendLAN(ctx, filePath, code); err != nil {
+			return fmt.Errorf("transfer failed: %w", err)
+		}
+
+		fmt.Println("✅ Transfer complete!")
+	} else if persistent {
 		// Persistent room mode - uses UUID
 		fmt.Printf("📤 Sharing: %s (persistent room)\n", filepath.Base(filePath))
 		fmt.Printf("🔑 Encryption code: %s\n", code)
@@ -344,6 +457,7 @@ func runSend(cmd *cobra.Command, args []string) error {
 		}
 
 		fmt.Println("✅ Transfer complete!")
+		printDirect(c)
 		if activeSession != nil {
 			fmt.Printf("📂 View in browser: claw open %s\n", activeSession.ID)
 		}
@@ -360,6 +474,7 @@ func runSend(cmd *cobra.Command, args []string) error {
 		}
 
 		fmt.Println("✅ Transfer complete!")
+		printDirect(c)
 	}
 
 	return nil
@@ -379,11 +494,7 @@ func runReceive(cmd *cobra.Command, args []string) error {
 	}
 
 	// Create client
-	cfg := client.DefaultConfig()
-	if relayURL != "" {
-		cfg.RelayURL = relayURL
-	}
-	cfg.Timeout = time.Duration(timeout) * time.Second
+	cfg := newClientConfig()
 	c := client.New(cfg)
 
 	ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeout)
@@ -393,7 +504,14 @@ func runReceive(cmd *cobra.Command, args []string) error {
 	var err error
 
 	// Detect if it's a UUID (persistent room) or code phrase (ephemeral)
-	if codePhrase != "" {
+	if lanMode {
+		if codePhrase != "" {
+			return fmt.Errorf("--lan takes the share code as its argument, not --code")
+		}
+		fmt.Printf("📡 Looking for %s on the local network...\n", identifier)
+
+		receivedPath, err = c.ReceiveLAN(ctx, identifier, outDir)
+	} else if codePhrase != "" {
 		// Persistent room mode - identifier is UUID, code is for encryption
 		fmt.Printf("📥 Connecting to room: %s\n", identifier)
 		fmt.Println("⏳ Waiting for sender...")
@@ -412,23 +530,33 @@ func runReceive(cmd *cobra.Command, args []string) error {
 	}
 
 	fmt.Printf("✅ Received: %s\n", receivedPath)
-	return nil
-}
+	if !lanMode {
+		printDirect(c)
+	}
 
-func runInstall(cmd *cobra.Command, args []string) error {
-	fmt.Println("📦 Installing Claude Code hooks...")
+	sc, err := safereader.ReadSafe(receivedPath)
+	if err != nil {
+		return fmt.Errorf("failed to scan received file: %w", err)
+	}
+	printScanWarnings(sc)
 
-	if err := hooks.RegisterHooks(); err != nil {
-		return fmt.Errorf("failed to install hooks: %w", err)
+	// Persistent rooms are identified by their UUID; never record an ephemeral code phrase
+	p := manifest.Provenance{Source: manifest.SourceRelay, Encrypted: true}
+	if lanMode {
+		p.Source = manifest.SourceLAN
+	}
+	if codePhrase != "" {
+		p.SourceID = identifier
 	}
+	recordProvenance(sc, "", p)
 
-	fmt.Println("✅ Hooks installed!")
-	fmt.Println("\nYou can now use:")
-	fmt.Println("  /share <file>   - Share a file")
-	fmt.Println("  /receive <code> - Receive a shared file")
-	fmt.Println("\nInbox hook registered:")
-	fmt.Println("  UserPromptSubmit → c2c inbox --quiet --if-stale 30m")
-	fmt.Println("  (checks for new notifications every 30 minutes)")
+	if _, ok := transcript.ParseBundle(sc.RawContent); ok {
+		fmt.Printf("🧾 Session handoff - read it with: claw read %s\n", sc.Filename)
+	} else if clawpack.IsPack(sc.RawContent) {
+		fmt.Printf("📦 Clawpack briefing - read it with: claw read %s\n", sc.Filename)
+	} else if _, ok := gitpatch.Parse(sc.RawContent); ok {
+		fmt.Printf("🌿 Git patch - review and apply it on a new branch with: claw apply %s\n", sc.Filename)
+	}
 	return nil
 }
 
@@ -474,17 +602,9 @@ func runList(cmd *cobra.Command, args []string) error {
 }
 
 func runRead(cmd *cobra.Command, args []string) error {
-	filename := args[0]
-	clawDir := ".claw/received"
-
-	// Build full path
-	filePath := filepath.Join(clawDir, filename)
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		// Try as absolute/relative path
-		filePath = filename
-		if _, err := os.Stat(filePath); os.IsNotExist(err) {
-			return fmt.Errorf("file not found: %s", filename)
-		}
+	filePath, err := receivedPath(args[0])
+	if err != nil {
+		return err
 	}
 
 	if rawOutput {
@@ -497,8 +617,9 @@ func runRead(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	// Safe read with prompt injection protection
-	sc, err := safereader.ReadSafe(filePath)
+	// Safe read with prompt injection protection; clawpacks and handoff
+	// bundles are rendered as briefings first
+	sc, err := clawpack.ReadSafe(filePath)
 	if err != nil {
 		return fmt.Errorf("failed to read file: %w", err)
 	}
@@ -509,28 +630,45 @@ func runRead(cmd *cobra.Command, args []string) error {
 	// Update manifest to mark as read
 	m, err := manifest.Load()
 	if err == nil {
-		m.MarkRead(sc.Filename)
+		m.MarkRead(sc.Path)
 		m.Save()
 	}
 
 	return nil
 }
 
+// receivedPath finds a received or downloaded team file by name (or by
+// received/<name> or shared/<name> when both exist), falling back to other
+// files under .claw and then to the name as a path
+func receivedPath(filename string) (string, error) {
+	filePath, err := manifest.FindFile(filename)
+	if !errors.Is(err, manifest.ErrFileNotFound) {
+		return filePath, err
+	}
+	// Try as a path under .claw (channels/<id>/<name>), then as given
+	for _, path := range []string{filepath.Join(".claw", filename), filename} {
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("file not found: %s", filename)
+}
+
 func runNew(cmd *cobra.Command, args []string) error {
 	clawDir := ".claw/received"
 
-	// Check if directory exists
-	if _, err := os.Stat(clawDir); os.IsNotExist(err) {
-		fmt.Println("📭 No received files yet.")
-		return nil
-	}
-
 	// Load manifest
 	m, err := manifest.Load()
 	if err != nil {
 		return fmt.Errorf("failed to load manifest: %w", err)
 	}
 
+	// Check if directory exists (downloaded team files are only in the manifest)
+	if _, err := os.Stat(clawDir); os.IsNotExist(err) && len(m.Files) == 0 {
+		fmt.Println("📭 No received files yet.")
+		return nil
+	}
+
 	// Get unread and updated files
 	unread := m.GetUnread()
 	updated := m.GetUpdatedSinceRead()
@@ -538,7 +676,7 @@ func runNew(cmd *cobra.Command, args []string) error {
 	if len(unread) == 0 && len(updated) == 0 {
 		// Check for files not in manifest (first time)
 		entries, err := os.ReadDir(clawDir)
-		if err != nil {
+		if err != nil && !os.IsNotExist(err) {
 			return err
 		}
 
@@ -547,12 +685,13 @@ func runNew(cmd *cobra.Command, args []string) error {
 			if entry.IsDir() {
 				continue
 			}
-			if _, exists := m.Files[entry.Name()]; !exists {
+			path := filepath.Join(clawDir, entry.Name())
+			if m.Entry(path) == nil {
 				newFiles = append(newFiles, entry.Name())
 				// Add to manifest
 				info, _ := entry.Info()
-				content, _ := os.ReadFile(filepath.Join(clawDir, entry.Name()))
-				m.RecordReceived(entry.Name(), info.Size(), content, "")
+				content, _ := os.ReadFile(path)
+				m.RecordReceived(path, info.Size(), content, "")
 			}
 		}
 
@@ -575,14 +714,14 @@ func runNew(cmd *cobra.Command, args []string) error {
 	if len(unread) > 0 {
 		fmt.Println("🆕 Unread files:")
 		for _, entry := range unread {
-			fmt.Printf("   📄 %s (received %s)\n", entry.Filename, entry.ReceivedAt.Format("2006-01-02 15:04"))
+			fmt.Printf("   📄 %s (received %s)\n", entry.Path, entry.ReceivedAt.Format("2006-01-02 15:04"))
 		}
 	}
 
 	if len(updated) > 0 {
 		fmt.Println("\n🔄 Updated since last read:")
 		for _, entry := range updated {
-			fmt.Printf("   📄 %s (updated %s, v%d)\n", entry.Filename, entry.ReceivedAt.Format("2006-01-02 15:04"), entry.Sequence)
+			fmt.Printf("   📄 %s (updated %s, v%d)\n", entry.Path, entry.ReceivedAt.Format("2006-01-02 15:04"), entry.Sequence)
 		}
 	}
 
@@ -596,11 +735,7 @@ func runChannelCreate(cmd *cobra.Command, args []string) error {
 	code := hooks.GenerateCodePhrase()
 
 	// Create client
-	cfg := client.DefaultConfig()
-	if relayURL != "" {
-		cfg.RelayURL = relayURL
-	}
-	cfg.Timeout = time.Duration(timeout) * time.Second
+	cfg := newClientConfig()
 	c := client.New(cfg)
 
 	ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeout)
@@ -611,6 +746,14 @@ func runChannelCreate(cmd *cobra.Command, args []string) error {
 	var channelID string
 	onRoomCreated := func(roomID string) {
 		channelID = roomID
+
+		// Save channel info to manifest before waiting
+		m, _ := manifest.Load()
+		if err := m.RecordChannel(channelID, channelName, code, "creator"); err != nil {
+			fmt.Printf("⚠️  Failed to store channel code: %v\n", err)
+			return
+		}
+		m.Save()
 	}
 
 	// Create as persistent room with long TTL
@@ -619,11 +762,6 @@ func runChannelCreate(cmd *cobra.Command, args []string) error {
 	os.WriteFile(tmpFile, []byte("Channel initialized"), 0644)
 	defer os.Remove(tmpFile)
 
-	// Save channel info to manifest before waiting
-	m, _ := manifest.Load()
-	m.RecordChannel(channelID, channelName, code, "creator")
-	m.Save()
-
 	fmt.Printf("🔑 Channel code: %s\n", code)
 
 	err := c.SendPersistentWithCallback(ctx, tmpFile, code, 168, onRoomCreated) // 1 week TTL
@@ -650,11 +788,7 @@ func runChannelJoin(cmd *cobra.Command, args []string) error {
 	}
 
 	// Create client
-	cfg := client.DefaultConfig()
-	if relayURL != "" {
-		cfg.RelayURL = relayURL
-	}
-	cfg.Timeout = time.Duration(timeout) * time.Second
+	cfg := newClientConfig()
 	c := client.New(cfg)
 
 	ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeout)
@@ -667,9 +801,18 @@ func runChannelJoin(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to join channel: %w", err)
 	}
 
+	sc, err := safereader.ReadSafe(receivedPath)
+	if err != nil {
+		return fmt.Errorf("failed to scan received file: %w", err)
+	}
+	printScanWarnings(sc)
+	recordProvenance(sc, "", manifest.Provenance{Source: manifest.SourceChannel, SourceID: channelID, Encrypted: true})
+
 	// Save channel info
 	m, _ := manifest.Load()
-	m.RecordChannel(channelID, "", codePhrase, "joiner")
+	if err := m.RecordChannel(channelID, "", codePhrase, "joiner"); err != nil {
+		return fmt.Errorf("failed to store channel code: %w", err)
+	}
 	m.Save()
 
 	fmt.Printf("✅ Joined channel!\n")
@@ -695,12 +838,13 @@ func runChannelSend(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("channel not found: %s\nJoin it first with: claw channel join %s --code <code>", channelID, channelID)
 	}
 
-	// Create client
-	cfg := client.DefaultConfig()
-	if relayURL != "" {
-		cfg.RelayURL = relayURL
+	code, err := ch.Secret()
+	if err != nil {
+		return fmt.Errorf("failed to get channel code: %w", err)
 	}
-	cfg.Timeout = time.Duration(timeout) * time.Second
+
+	// Create client
+	cfg := newClientConfig()
 	c := client.New(cfg)
 
 	ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeout)
@@ -709,7 +853,7 @@ func runChannelSend(cmd *cobra.Command, args []string) error {
 	fmt.Printf("📤 Sending to channel: %s\n", channelID)
 	fmt.Println("⏳ Waiting for receiver...")
 
-	err = c.SendPersistentWithCallback(ctx, filePath, ch.Code, 168, func(roomID string) {
+	err = c.SendPersistentWithCallback(ctx, filePath, code, 168, func(roomID string) {
 		// Channel uses same ID
 	})
 	if err != nil {
@@ -724,6 +868,50 @@ func runChannelSend(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func runChannelListen(cmd *cobra.Command, args []string) error {
+	channelID := args[0]
+
+	// Load manifest to get channel code
+	m, err := manifest.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load manifest: %w", err)
+	}
+
+	ch, exists := m.Channels[channelID]
+	if !exists {
+		return fmt.Errorf("channel not found: %s\nJoin it first with: claw channel join %s --code <code>", channelID, channelID)
+	}
+
+	code, err := ch.Secret()
+	if err != nil {
+		return fmt.Errorf("failed to get channel code: %w", err)
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	cfg := newClientConfig()
+	job, err := agent.Submit(&agent.Spec{
+		Kind:     agent.KindChannel,
+		Channel:  channelID,
+		Code:     code,
+		Dir:      cwd,
+		RelayURL: cfg.RelayURL,
+		Timeout:  timeout,
+		Direct:   cfg.Direct,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to hand channel to agent: %w", err)
+	}
+
+	fmt.Printf("📡 Listening on channel %s in the background (job %s)\n", channelID, job.ID)
+	fmt.Printf("📥 Messages land in .claw/channels/%s - see them with: claw new\n", channelID)
+	fmt.Printf("\nCheck with: claw jobs · Stop with: claw jobs cancel %s\n", job.ID)
+	return nil
+}
+
 func runChannelList(cmd *cobra.Command, args []string) error {
 	m, err := manifest.Load()
 	if err != nil {
@@ -777,7 +965,13 @@ func runLogin(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
-	if cfg.LoggedIn {
+	if cfg.LoggedIn && cfg.TokenExpired() && cfg.RefreshToken != "" {
+		// Expired access token - try a silent refresh before a full login
+		if err := account.RefreshSession(cfg); err == nil {
+			fmt.Printf("🔄 Session refreshed for %s (%s)\n", cfg.Name, cfg.Email)
+			return nil
+		}
+	} else if cfg.LoggedIn && !cfg.TokenExpired() {
 		fmt.Printf("✅ Already logged in as %s (%s)\n", cfg.Name, cfg.Email)
 		fmt.Println("   Use 'claw logout' to switch accounts.")
 		return nil
@@ -789,8 +983,23 @@ func runLogin(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("login failed: %w", err)
 	}
 
+	// Store the new session in the active profile, keeping its relay settings.
+	// The team (and inbox checkpoint) belong to the old account if it changed.
+	if accountChanged(cfg, newCfg) {
+		cfg.TeamID = ""
+		cfg.LastBoardCheck = ""
+		fmt.Println("ℹ️  Logged in to a different account, so the team was cleared (claw team join / claw team create)")
+	}
+	cfg.UserID = newCfg.UserID
+	cfg.Token = newCfg.Token
+	cfg.RefreshToken = newCfg.RefreshToken
+	cfg.TokenExpiresAt = newCfg.TokenExpiresAt
+	cfg.Email = newCfg.Email
+	cfg.Name = newCfg.Name
+	cfg.LoggedIn = true
+
 	// Save config
-	if err := account.SaveConfig(newCfg); err != nil {
+	if err := account.SaveConfig(cfg); err != nil {
 		return fmt.Errorf("failed to save config: %w", err)
 	}
 
@@ -804,6 +1013,15 @@ func runLogin(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// accountChanged reports whether a login is for a different user than the
+// one the profile was set up for
+func accountChanged(old, login *account.Config) bool {
+	if old.UserID != "" && login.UserID != "" {
+		return old.UserID != login.UserID
+	}
+	return old.Email != "" && login.Email != "" && !strings.EqualFold(old.Email, login.Email)
+}
+
 func runLogout(cmd *cobra.Command, args []string) error {
 	cfg, err := account.LoadConfig()
 	if err != nil {
@@ -817,6 +1035,8 @@ func runLogout(cmd *cobra.Command, args []string) error {
 
 	// Clear credentials but keep baseURL
 	cfg.Token = ""
+	cfg.RefreshToken = ""
+	cfg.TokenExpiresAt = ""
 	cfg.Email = ""
 	cfg.Name = ""
 	cfg.LoggedIn = false
@@ -924,6 +1144,22 @@ func runWhoami(cmd *cobra.Command, args []string) error {
 		fmt.Printf("   Email: %s\n", cfg.Email)
 	}
 	fmt.Printf("   Server: %s\n", cfg.BaseURL)
+	fmt.Printf("   Profile: %s\n", cfg.Profile())
+	if cfg.TeamID != "" {
+		fmt.Printf("   Team:  %s\n", cfg.TeamID)
+	}
+	if exp := cfg.TokenExpiry(); !exp.IsZero() {
+		if cfg.TokenExpired() {
+			fmt.Printf("   Session: expired %s", exp.Format("2006-01-02 15:04"))
+			if cfg.RefreshToken != "" {
+				fmt.Println(" (will refresh on next call)")
+			} else {
+				fmt.Println(" (run: claw login)")
+			}
+		} else {
+			fmt.Printf("   Session: valid until %s\n", exp.Format("2006-01-02 15:04"))
+		}
+	}
 
 	return nil
 }
@@ -966,3 +1202,19 @@ func runContext(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// newClientConfig builds the relay client config. The relay URL comes from
+// the active profile (or CLAW_RELAY), overridden by the --relay flag.
+func newClientConfig() *client.Config {
+	cfg := hooks.ClientConfig(relayURL)
+	cfg.Timeout = time.Duration(timeout) * time.Second
+	cfg.Direct = directMode
+	return cfg
+}
+
+// printDirect notes when content bypassed the relay
+func printDirect(c *client.Client) {
+	if c.Direct() {
+		fmt.Println("⚡ Direct connection - content didn't pass through the relay")
+	}
+}
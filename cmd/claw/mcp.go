@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/epuerta9/claw2claw/internal/mcp"
+	"github.com/spf13/cobra"
+)
+
+func newMCPCmd() *cobra.Command {
+	mcpCmd := &cobra.Command{
+		Use:   "mcp",
+		Short: "Model Context Protocol server for agents",
+	}
+
+	serveCmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve claw2claw tools over stdio (MCP)",
+		Long: `Run a Model Context Protocol server on stdin/stdout.
+
+Agents get typed tools with JSON results instead of scraping CLI output:
+
+  claw_send, claw_transfer_status   Send a file, check on background sends
+  claw_receive                      Receive by code (or room ID + code)
+  claw_new, claw_read               Unread files; safe read with findings
+  claw_board_get, claw_board_update Team board sections
+  claw_inbox, claw_reply            Notifications and threaded replies
+  claw_notify                       Notify a team member
+
+Register it with Claude Code in .mcp.json:
+
+  {
+    "mcpServers": {
+      "claw2claw": {"command": "claw", "args": ["mcp", "serve"]}
+    }
+  }
+
+The server runs in the project directory, so .claw/ paths resolve as they do
+for the CLI. --profile and --relay apply to every call.`,
+		Args: cobra.NoArgs,
+		RunE: runMCPServe,
+	}
+
+	mcpCmd.AddCommand(serveCmd)
+	return mcpCmd
+}
+
+func runMCPServe(cmd *cobra.Command, args []string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// stdout carries the protocol; anything for humans goes to stderr
+	server := mcp.NewClawServer("0.2.0", mcp.Options{ClientConfig: newClientConfig})
+	return server.Serve(ctx, os.Stdin, os.Stdout)
+}
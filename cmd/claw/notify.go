@@ -1,20 +1,47 @@
 package main
 
 import (
+	"bufio"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/epuerta9/claw2claw/internal/account"
+	"github.com/epuerta9/claw2claw/internal/agent"
+	"github.com/epuerta9/claw2claw/internal/client"
+	"github.com/epuerta9/claw2claw/internal/hooks"
+	"github.com/epuerta9/claw2claw/internal/inbox"
+	"github.com/epuerta9/claw2claw/internal/manifest"
+	"github.com/epuerta9/claw2claw/internal/safereader"
 	"github.com/spf13/cobra"
 )
 
 var (
-	notifyType string
-	jsonOutput bool
-	quietMode  bool
-	ifStale    string
+	notifyType   string
+	notifyAttach []string // Files to attach
+	notifyRef    string   // Board section to reference
+	notifyE2E    bool     // Send attachments through an encrypted room instead of uploading
+	fetchAttach  bool     // For read - download attachments
+	jsonOutput   bool
+	quietMode    bool
+	ifStale      string
+
+	filterTypes []string // --type for inbox and read --all
+	filterFrom  string
+	filterSince string
+	readAll     bool
+
+	daemonConfig     string        // For daemon - handler config path
+	daemonInterval   time.Duration // For daemon - polling interval
+	daemonOnce       bool          // For daemon - poll once and exit
+	daemonBackground bool          // For daemon - run in the background agent
 )
 
 func newNotifyCmd() *cobra.Command {
@@ -25,13 +52,23 @@ func newNotifyCmd() *cobra.Command {
 
 Notification types: question, blocker, mention, file
 
+Attachments are uploaded as encrypted shared team files (like claw share).
+With --e2e the file is sent through a PAKE-keyed persistent room instead:
+only the room ID goes in the notification, the code is printed for you to
+pass on, and the command waits until the recipient fetches it.
+
 Examples:
   c2c notify jared "Caching decision needed" "Should we use KV or Redis?"
-  c2c notify jared "API review" --type blocker`,
+  c2c notify jared "API review" --type blocker
+  c2c notify jared "Review this" --attach design.md --ref decisions
+  c2c notify jared "Credentials layout" --attach secrets.md --e2e`,
 		Args: cobra.RangeArgs(2, 3),
 		RunE: runNotify,
 	}
-	notifyCmd.Flags().StringVar(&notifyType, "type", "question", "Notification type: question, blocker, mention, file")
+	notifyCmd.Flags().StringVar(&notifyType, "type", "question", "Notification type: question, blocker, mention, file (default file with --attach)")
+	notifyCmd.Flags().StringArrayVar(&notifyAttach, "attach", nil, "File to attach (repeatable)")
+	notifyCmd.Flags().StringVar(&notifyRef, "ref", "", "Board section this is about (e.g. decisions)")
+	notifyCmd.Flags().BoolVar(&notifyE2E, "e2e", false, "Send the attachment end-to-end encrypted through a persistent room")
 
 	return notifyCmd
 }
@@ -42,32 +79,134 @@ func newInboxCmd() *cobra.Command {
 		Short: "Check unread notifications and board changes",
 		Long: `Check your inbox for unread notifications and recent board changes.
 
-This is the session-start command - run it to see what's happened since you last checked.`,
+This is the session-start command - run it to see what's happened since you last checked.
+
+Notifications are listed by priority: blockers, then questions, mentions
+and files, newest first. Filters apply to --json output too, so hooks can
+inject only what matters.
+
+Examples:
+  claw inbox --type blocker
+  claw inbox --from jared --since 2d
+  claw inbox --type blocker,question --json --quiet`,
 		RunE: runInbox,
 	}
 	inboxCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output as JSON (for hooks)")
 	inboxCmd.Flags().BoolVar(&quietMode, "quiet", false, "Suppress non-essential output")
 	inboxCmd.Flags().StringVar(&ifStale, "if-stale", "", "Only check if last check was longer ago than this duration (e.g. 30m, 1h)")
-
+	addInboxFilterFlags(inboxCmd)
 
 	readCmd := &cobra.Command{
 		Use:   "read <notification-id>",
 		Short: "Read and mark a notification as read",
-		Args:  cobra.ExactArgs(1),
-		RunE:  runInboxRead,
+		Long: `Show a notification and mark it as read.
+
+Attachments are listed with a --fetch hint. --fetch downloads them to
+.claw/shared/ (or .claw/received/ for encrypted ones, which also need
+--code) and prints them wrapped as untrusted external content.
+
+With --all, marks every unread notification as read (narrowed by --type,
+--from and --since if given). Snoozed notifications are included.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: runInboxRead,
+	}
+	readCmd.Flags().BoolVar(&readAll, "all", false, "Mark all unread notifications as read")
+	readCmd.Flags().BoolVar(&fetchAttach, "fetch", false, "Download attachments and show them safely wrapped")
+	readCmd.Flags().StringVar(&codePhrase, "code", "", "Encryption code for an end-to-end encrypted attachment")
+	addInboxFilterFlags(readCmd)
+
+	snoozeCmd := &cobra.Command{
+		Use:   "snooze <notification-id> <duration>",
+		Short: "Hide a notification for a while",
+		Long: `Hide a notification from the inbox until the duration has passed.
+
+Durations: 30m, 2h, 2d, 1w. Snoozes are stored locally in ~/.claw/inbox/.
+
+Example:
+  claw inbox snooze 3f2a 2h`,
+		Args: cobra.ExactArgs(2),
+		RunE: runInboxSnooze,
 	}
 
 	replyCmd := &cobra.Command{
 		Use:   "reply <notification-id> <body>",
-		Short: "Reply to a notification",
+		Short: "Reply to a notification (keeps the thread)",
 		Args:  cobra.ExactArgs(2),
 		RunE:  runInboxReply,
 	}
 
-	inboxCmd.AddCommand(readCmd, replyCmd)
+	threadCmd := &cobra.Command{
+		Use:   "thread <id>",
+		Short: "Show a whole conversation",
+		Long: `Show every message in a notification thread, oldest first.
+
+The id can be any notification in the thread, or the thread ID itself
+(prefixes work). Threads are cached in ~/.claw/inbox/ so conversations you
+took part in stay readable even if the server can't return them.`,
+		Args: cobra.ExactArgs(1),
+		RunE: runInboxThread,
+	}
+
+	threadCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output as JSON")
+
+	daemonCmd := &cobra.Command{
+		Use:   "daemon",
+		Short: "Dispatch new notifications to local handlers",
+		Long: `Watch for new notifications and hand each one to the configured handlers.
+
+Uses the server's live notification stream when available, otherwise polls.
+Handlers are configured in ~/.claw/handlers.json. Each has one target and
+optional routing rules on type and sender (empty matches everything):
+
+  {
+    "poll_interval": "30s",
+    "handlers": [
+      {"name": "page", "match": {"types": ["blocker"]},
+       "webhook": "http://localhost:9000/page"},
+      {"name": "mentions", "match": {"types": ["mention"], "from": ["jared"]},
+       "command": "jq -r .subject >> ~/mentions.log"},
+      {"name": "drop", "dir": ".claw/inbox"}
+    ]
+  }
+
+Webhooks receive the notification JSON as a POST body, commands on stdin
+(with CLAW_NOTIFICATION_ID, _TYPE, _FROM and _SUBJECT set), and dir
+handlers write <id>.json. Each notification is delivered once; failed
+deliveries are retried up to 3 times.
+
+With --background, dispatch runs in the background agent (see 'claw agent')
+instead; relative handler dirs and commands use the current directory.`,
+		Args: cobra.NoArgs,
+		RunE: runInboxDaemon,
+	}
+	daemonCmd.Flags().StringVar(&daemonConfig, "config", "", "Handler config (default ~/.claw/handlers.json)")
+	daemonCmd.Flags().DurationVar(&daemonInterval, "interval", 0, "Polling interval (overrides poll_interval, default 30s)")
+	daemonCmd.Flags().BoolVar(&daemonOnce, "once", false, "Deliver pending notifications once and exit (for cron)")
+	daemonCmd.Flags().BoolVar(&daemonBackground, "background", false, "Dispatch from the background agent and return immediately")
+
+	inboxCmd.AddCommand(readCmd, replyCmd, threadCmd, snoozeCmd, daemonCmd)
 	return inboxCmd
 }
 
+func addInboxFilterFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSliceVar(&filterTypes, "type", nil, "Only these types: blocker, question, mention, file, board")
+	cmd.Flags().StringVar(&filterFrom, "from", "", "Only from this member")
+	cmd.Flags().StringVar(&filterSince, "since", "", "Only newer than this (e.g. 30m, 2h, 2d, 1w)")
+}
+
+// inboxFilter builds the filter from --type, --from and --since
+func inboxFilter() (inbox.Filter, error) {
+	f := inbox.Filter{Types: filterTypes, From: filterFrom}
+	if filterSince != "" {
+		d, err := inbox.ParseDuration(filterSince)
+		if err != nil {
+			return f, fmt.Errorf("invalid --since: %w", err)
+		}
+		f.Since = time.Now().Add(-d)
+	}
+	return f, nil
+}
+
 func runNotify(cmd *cobra.Command, args []string) error {
 	cfg, err := account.LoadConfig()
 	if err != nil {
@@ -77,22 +216,122 @@ func runNotify(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("not logged in or team not configured")
 	}
 
-	toUser := args[0]
-	subject := args[1]
-	body := ""
+	n := &account.Notification{
+		ToUser:     args[0],
+		Type:       notifyType,
+		Subject:    args[1],
+		RefSection: notifyRef,
+	}
 	if len(args) > 2 {
-		body = args[2]
+		n.Body = args[2]
+	}
+	if len(notifyAttach) > 0 && !cmd.Flags().Changed("type") {
+		n.Type = "file"
+	}
+
+	if notifyRef != "" {
+		n.RefSection = expandSection(cfg, notifyRef)
+		if bs, err := account.GetBoardSection(cfg, n.RefSection); err == nil && bs == nil {
+			fmt.Printf("⚠️  Board section '%s' doesn't exist (yet)\n", n.RefSection)
+		}
+	}
+	for _, path := range notifyAttach {
+		if _, err := os.Stat(path); err != nil {
+			return fmt.Errorf("cannot attach %s: %w", path, err)
+		}
+	}
+
+	if notifyE2E {
+		return notifyEncrypted(cfg, n)
 	}
 
-	n, err := account.SendNotification(cfg, toUser, notifyType, subject, body)
+	for _, path := range notifyAttach {
+		sf, err := account.UploadFile(cfg, path)
+		if err != nil {
+			return fmt.Errorf("failed to upload %s: %w", path, err)
+		}
+		n.Attachments = append(n.Attachments, account.Attachment{
+			Kind:     account.AttachmentFile,
+			Filename: sf.Filename,
+			Size:     sf.Size,
+			FileID:   sf.ID,
+		})
+		fmt.Printf("📎 Uploaded %s encrypted (%d bytes)\n", sf.Filename, sf.Size)
+		printUnreadable(sf)
+	}
+
+	sent, err := account.PostNotification(cfg, n)
 	if err != nil {
 		return fmt.Errorf("failed to send notification: %w", err)
 	}
+	recordNotifications(cfg, *sent)
+
+	fmt.Printf("Sent %s to %s: %s\n", sent.Type, sent.ToUser, sent.Subject)
+	return nil
+}
+
+// notifyEncrypted sends one attachment through a persistent room. The
+// notification carries the room ID; the code must reach the recipient out of band.
+func notifyEncrypted(cfg *account.Config, n *account.Notification) error {
+	if len(notifyAttach) != 1 {
+		return fmt.Errorf("--e2e sends exactly one --attach file")
+	}
+	path := notifyAttach[0]
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	code := hooks.GenerateCodePhrase()
+	clientCfg := newClientConfig()
+	c := client.New(clientCfg)
+
+	ctx, cancel := context.WithTimeout(context.Background(), clientCfg.Timeout)
+	defer cancel()
+
+	var notifyErr error
+	onRoomCreated := func(roomID string) {
+		n.Attachments = []account.Attachment{{
+			Kind:     account.AttachmentRoom,
+			Filename: filepath.Base(path),
+			Size:     info.Size(),
+			RoomID:   roomID,
+		}}
+		sent, err := account.PostNotification(cfg, n)
+		if err != nil {
+			notifyErr = err
+			cancel()
+			return
+		}
+		recordNotifications(cfg, *sent)
+
+		fmt.Printf("Sent %s to %s: %s\n", sent.Type, sent.ToUser, sent.Subject)
+		fmt.Printf("🔑 Encryption code: %s\n", code)
+		fmt.Printf("   Give %s the code out of band, then they run:\n", sent.ToUser)
+		fmt.Printf("   claw inbox read %s --fetch --code %s\n", shortID(sent.ID), code)
+		fmt.Println("⏳ Waiting for the recipient to fetch the attachment...")
+	}
 
-	fmt.Printf("Sent %s to %s: %s\n", n.Type, n.ToUser, n.Subject)
+	if err := c.SendPersistentWithCallback(ctx, path, code, ttlHours, onRoomCreated); err != nil {
+		if notifyErr != nil {
+			return fmt.Errorf("failed to send notification: %w", notifyErr)
+		}
+		return fmt.Errorf("transfer failed: %w", err)
+	}
+	fmt.Println("✅ Attachment delivered")
 	return nil
 }
 
+// recordNotifications caches notifications in the local thread store, best effort
+func recordNotifications(cfg *account.Config, notifications ...account.Notification) {
+	state, err := inbox.Load(cfg)
+	if err != nil {
+		return
+	}
+	state.Record(notifications...)
+	state.Save()
+}
+
 func runInbox(cmd *cobra.Command, args []string) error {
 	// If --if-stale is set, check whether we need to run at all
 	if ifStale != "" {
@@ -128,7 +367,21 @@ func runInbox(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("not logged in or team not configured")
 	}
 
-	inbox, err := account.GetInbox(cfg)
+	filter, err := inboxFilter()
+	if err != nil {
+		if quietMode {
+			return nil
+		}
+		return err
+	}
+
+	// A filtered view leaves the checkpoint alone, so what it hides is
+	// still reported by the next unfiltered check
+	fetch := account.GetInbox
+	if !filter.Empty() {
+		fetch = account.PeekInbox
+	}
+	inbox, err := fetch(cfg)
 	if err != nil {
 		if quietMode {
 			return nil
@@ -136,6 +389,28 @@ func runInbox(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to get inbox: %w", err)
 	}
 
+	recordNotifications(cfg, inbox.Notifications...)
+
+	state, err := inboxState(cfg)
+	if err != nil {
+		if quietMode {
+			return nil
+		}
+		return err
+	}
+	var snoozed int
+	inbox.Notifications, snoozed = state.Select(inbox.Notifications, filter)
+	inbox.UnreadCount = len(inbox.Notifications)
+	if !filter.Empty() {
+		var changes []account.BoardSection
+		for _, bs := range inbox.BoardChanges {
+			if filter.MatchBoard(&bs) {
+				changes = append(changes, bs)
+			}
+		}
+		inbox.BoardChanges = changes
+	}
+
 	if jsonOutput {
 		data, _ := json.MarshalIndent(inbox, "", "  ")
 		fmt.Println(string(data))
@@ -144,7 +419,14 @@ func runInbox(cmd *cobra.Command, args []string) error {
 
 	if inbox.UnreadCount == 0 && len(inbox.BoardChanges) == 0 {
 		if !quietMode {
-			fmt.Println("All caught up! No new notifications or board changes.")
+			switch {
+			case snoozed > 0:
+				fmt.Printf("All caught up! (%d snoozed)\n", snoozed)
+			case !filter.Empty():
+				fmt.Println("Nothing matches those filters.")
+			default:
+				fmt.Println("All caught up! No new notifications or board changes.")
+			}
 		}
 		return nil
 	}
@@ -162,7 +444,17 @@ func runInbox(cmd *cobra.Command, args []string) error {
 				typeIcon = "*"
 			}
 
-			fmt.Printf("  [%s] %s from %s: %s\n", typeIcon, n.ID[:8], n.FromUser, n.Subject)
+			label := ""
+			if n.Type == "blocker" {
+				label = "BLOCKER "
+			}
+			fmt.Printf("  [%s] %s%s from %s: %s\n", typeIcon, label, n.ID[:8], n.FromUser, n.Subject)
+			if n.ParentID != "" {
+				fmt.Printf("      ↳ reply in thread %s\n", shortID(n.Thread()))
+			}
+			if len(n.Attachments) > 0 {
+				fmt.Printf("      📎 %d attachment(s) - claw inbox read %s --fetch\n", len(n.Attachments), shortID(n.ID))
+			}
 			if n.Body != "" {
 				// Show first line of body
 				lines := strings.SplitN(n.Body, "\n", 2)
@@ -170,7 +462,10 @@ func runInbox(cmd *cobra.Command, args []string) error {
 			}
 			fmt.Printf("      %s\n\n", n.CreatedAt.Format("2006-01-02 15:04"))
 		}
-		fmt.Println("Mark as read: claw inbox read <id>")
+		if snoozed > 0 {
+			fmt.Printf("(%d snoozed)\n", snoozed)
+		}
+		fmt.Println("Mark as read: claw inbox read <id>   Snooze: claw inbox snooze <id> 2h")
 	}
 
 	if len(inbox.BoardChanges) > 0 {
@@ -195,37 +490,201 @@ func runInboxRead(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
+	if readAll {
+		if len(args) > 0 {
+			return fmt.Errorf("use either a notification id or --all")
+		}
+		return markAllRead(cfg)
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("notification id is required (or use --all)")
+	}
+
 	notifID := args[0]
 
-	// Try to find the full notification ID if a prefix was given
-	if len(notifID) < 32 {
-		// It's a prefix - get all notifications and find the match
-		userID := cfg.UserID
-		if userID == "" {
-			userID = cfg.Name
-			if userID == "" {
-				userID = cfg.Email
-			}
+	// Find the full notification (a prefix may have been given)
+	var n *account.Notification
+	if notifications, err := account.GetNotifications(cfg, cfg.MemberName(), false); err == nil {
+		n, err = inbox.Resolve(notifications, nil, notifID)
+		if err != nil && !errors.Is(err, inbox.ErrNotFound) {
+			return err
 		}
-		notifications, err := account.GetNotifications(cfg, userID, false)
-		if err == nil {
-			for _, n := range notifications {
-				if strings.HasPrefix(n.ID, notifID) {
-					notifID = n.ID
-					break
-				}
-			}
+		if n != nil {
+			notifID = n.ID
 		}
 	}
 
+	if n != nil {
+		printNotification(n)
+	}
+
 	if err := account.MarkNotificationRead(cfg, notifID); err != nil {
 		return fmt.Errorf("failed to mark read: %w", err)
 	}
+	fmt.Printf("Marked %s as read.\n", shortID(notifID))
+
+	if n == nil || len(n.Attachments) == 0 {
+		return nil
+	}
+	if !fetchAttach && isTerminal(os.Stdin) {
+		fmt.Print("\nFetch attachments now? [y/N] ")
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		fetchAttach = strings.EqualFold(strings.TrimSpace(answer), "y")
+	}
+	if !fetchAttach {
+		fmt.Printf("Fetch attachments: claw inbox read %s --fetch\n", shortID(n.ID))
+		return nil
+	}
+	for _, a := range n.Attachments {
+		if err := fetchAttachment(cfg, n.FromUser, a); err != nil {
+			return fmt.Errorf("failed to fetch %s: %w", a.Filename, err)
+		}
+	}
+	return nil
+}
+
+// printNotification shows a notification with its references and attachments
+func printNotification(n *account.Notification) {
+	fmt.Printf("[%s] %s from %s: %s\n", n.Type, shortID(n.ID), n.FromUser, n.Subject)
+	fmt.Printf("%s\n", n.CreatedAt.Format("2006-01-02 15:04"))
+	if n.Body != "" {
+		fmt.Printf("\n%s\n", n.Body)
+	}
+	if n.RefSection != "" {
+		fmt.Printf("\n📌 Re board section '%s' (view: claw board %s)\n", n.RefSection, n.RefSection)
+	}
+	for _, a := range n.Attachments {
+		switch a.Kind {
+		case account.AttachmentRoom:
+			fmt.Printf("📎 %s (%d bytes, end-to-end encrypted - needs the code from %s)\n", a.Filename, a.Size, n.FromUser)
+		default:
+			fmt.Printf("📎 %s (%d bytes, file %s)\n", a.Filename, a.Size, shortID(a.FileID))
+		}
+	}
+	fmt.Println()
+}
+
+// fetchAttachment downloads an attachment and prints it wrapped by safereader
+func fetchAttachment(cfg *account.Config, from string, a account.Attachment) error {
+	var sc *safereader.SafeContent
+	switch a.Kind {
+	case account.AttachmentRoom:
+		if codePhrase == "" {
+			return fmt.Errorf("encrypted attachment, pass --code <code> from the sender")
+		}
+		clientCfg := newClientConfig()
+		ctx, cancel := context.WithTimeout(context.Background(), clientCfg.Timeout)
+		defer cancel()
+
+		outDir := ".claw/received"
+		if err := os.MkdirAll(outDir, 0755); err != nil {
+			return err
+		}
+		fmt.Printf("📥 Connecting to room %s...\n", a.RoomID)
+		p, err := client.New(clientCfg).ReceivePersistent(ctx, a.RoomID, codePhrase, outDir)
+		if err != nil {
+			return err
+		}
+		if sc, err = safereader.ReadSafe(p); err != nil {
+			return err
+		}
+		recordProvenance(sc, a.Filename, manifest.Provenance{
+			Source:    manifest.SourceRelay,
+			SourceID:  a.RoomID,
+			From:      from,
+			Encrypted: true,
+		})
+	case account.AttachmentFile:
+		d, err := downloadTeamFile(cfg, a.FileID, from)
+		if err != nil {
+			return err
+		}
+		sc = d.Scan
+	default:
+		return fmt.Errorf("unknown attachment kind %q", a.Kind)
+	}
+
+	fmt.Printf("✅ Saved: %s\n\n", sc.Path)
+	fmt.Println(sc.Content)
+	return nil
+}
+
+// markAllRead marks every unread notification matching the filters as read
+func markAllRead(cfg *account.Config) error {
+	if !cfg.LoggedIn || cfg.TeamID == "" {
+		return fmt.Errorf("not logged in or team not configured")
+	}
+	filter, err := inboxFilter()
+	if err != nil {
+		return err
+	}
+
+	notifications, err := account.GetNotifications(cfg, cfg.MemberName(), true)
+	if err != nil {
+		return fmt.Errorf("failed to get notifications: %w", err)
+	}
 
-	fmt.Printf("Marked %s as read.\n", notifID[:8])
+	marked := 0
+	for _, n := range notifications {
+		if n.ReadAt != nil || !filter.Match(&n) {
+			continue
+		}
+		if err := account.MarkNotificationRead(cfg, n.ID); err != nil {
+			return fmt.Errorf("failed to mark %s read: %w", shortID(n.ID), err)
+		}
+		marked++
+	}
+
+	fmt.Printf("Marked %d notification(s) as read.\n", marked)
 	return nil
 }
 
+func runInboxSnooze(cmd *cobra.Command, args []string) error {
+	cfg, err := account.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if !cfg.LoggedIn || cfg.TeamID == "" {
+		return fmt.Errorf("not logged in or team not configured")
+	}
+
+	d, err := inbox.ParseDuration(args[1])
+	if err != nil {
+		return err
+	}
+
+	notifications, err := account.GetNotifications(cfg, cfg.MemberName(), false)
+	if err != nil {
+		return fmt.Errorf("failed to get notifications: %w", err)
+	}
+	target, err := inbox.Resolve(notifications, nil, args[0])
+	if err != nil {
+		return err
+	}
+
+	state, err := inboxState(cfg)
+	if err != nil {
+		return err
+	}
+	until := time.Now().Add(d)
+	state.Snooze(target.ID, until)
+	if err := state.Save(); err != nil {
+		return fmt.Errorf("failed to save inbox state: %w", err)
+	}
+
+	fmt.Printf("Snoozed %s until %s: %s\n", shortID(target.ID), until.Format("2006-01-02 15:04"), target.Subject)
+	return nil
+}
+
+// inboxState loads the local inbox state for the current profile and team
+func inboxState(cfg *account.Config) (*inbox.State, error) {
+	state, err := inbox.Load(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load inbox state: %w", err)
+	}
+	return state, nil
+}
+
 func runInboxReply(cmd *cobra.Command, args []string) error {
 	cfg, err := account.LoadConfig()
 	if err != nil {
@@ -235,47 +694,189 @@ func runInboxReply(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("not logged in or team not configured")
 	}
 
-	notifID := args[0]
-	body := args[1]
+	n, err := inbox.Reply(cfg, args[0], args[1])
+	if err != nil {
+		return err
+	}
 
-	// Find the original notification to get the sender
-	userID := cfg.UserID
-	if userID == "" {
-		userID = cfg.Name
-		if userID == "" {
-			userID = cfg.Email
-		}
+	fmt.Printf("Replied to %s: %s\n", n.ToUser, n.Subject)
+	fmt.Printf("View the conversation: claw inbox thread %s\n", shortID(n.Thread()))
+	return nil
+}
+
+func runInboxThread(cmd *cobra.Command, args []string) error {
+	cfg, err := account.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if !cfg.LoggedIn || cfg.TeamID == "" {
+		return fmt.Errorf("not logged in or team not configured")
 	}
-	notifications, err := account.GetNotifications(cfg, userID, false)
+
+	state, err := inbox.Load(cfg)
 	if err != nil {
-		return fmt.Errorf("failed to get notifications: %w", err)
+		return fmt.Errorf("failed to load inbox state: %w", err)
 	}
 
-	var originalFrom string
-	var originalSubject string
-	for _, n := range notifications {
-		if strings.HasPrefix(n.ID, notifID) {
-			originalFrom = n.FromUser
-			originalSubject = n.Subject
-			notifID = n.ID
-			break
-		}
+	// Resolve the thread ID from the cache and received notifications
+	notifications, err := account.GetNotifications(cfg, cfg.MemberName(), false)
+	if err != nil {
+		notifications = nil // Offline: the cache alone may still resolve it
+	}
+	threadID, err := inbox.ResolveThread(notifications, state, args[0])
+	if err != nil {
+		return err
 	}
 
-	if originalFrom == "" {
-		return fmt.Errorf("notification not found: %s", notifID)
+	messages, err := account.GetThread(cfg, threadID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Could not fetch thread from server, showing cached copy: %v\n\n", err)
 	}
+	state.Record(messages...)
+	state.Save()
 
-	// Mark original as read
-	account.MarkNotificationRead(cfg, notifID)
+	t := state.Threads[threadID]
+	if t == nil || len(t.Messages) == 0 {
+		return fmt.Errorf("thread not found: %s", args[0])
+	}
 
-	// Send reply as a new notification
-	subject := "Re: " + originalSubject
-	n, err := account.SendNotification(cfg, originalFrom, "mention", subject, body)
+	if jsonOutput {
+		data, _ := json.MarshalIndent(t, "", "  ")
+		fmt.Println(string(data))
+		return nil
+	}
+
+	fmt.Printf("🧵 %s (%d message(s), thread %s)\n\n", t.Subject, len(t.Messages), shortID(t.ID))
+
+	depth := make(map[string]int)
+	for _, n := range t.Messages {
+		d := 0
+		if n.ParentID != "" {
+			d = depth[n.ParentID] + 1
+			if d > 4 {
+				d = 4
+			}
+		}
+		depth[n.ID] = d
+
+		indent := strings.Repeat("    ", d)
+		marker := ""
+		if d > 0 {
+			marker = "↳ "
+		}
+		fmt.Printf("%s%s%s → %s · %s [%s]\n", indent, marker, n.FromUser, n.ToUser,
+			n.CreatedAt.Format("2006-01-02 15:04"), shortID(n.ID))
+		body := n.Body
+		if body == "" {
+			body = n.Subject
+		}
+		for _, line := range strings.Split(body, "\n") {
+			fmt.Printf("%s  %s\n", indent, line)
+		}
+		fmt.Println()
+	}
+	fmt.Printf("Reply: claw inbox reply %s \"...\"\n", shortID(t.Messages[len(t.Messages)-1].ID))
+	return nil
+}
+
+// submitInboxDaemon hands notification dispatch to the background agent
+func submitInboxDaemon(cfg *account.Config, path string, handlers *inbox.HandlerConfig, interval time.Duration) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	cwd, err := os.Getwd()
 	if err != nil {
-		return fmt.Errorf("failed to send reply: %w", err)
+		return err
 	}
 
-	fmt.Printf("Replied to %s: %s\n", n.ToUser, subject)
+	job, err := agent.Submit(&agent.Spec{
+		Kind:     agent.KindInbox,
+		Profile:  cfg.Profile(),
+		TeamID:   cfg.TeamID,
+		Handlers: abs,
+		Interval: int(interval / time.Second),
+		Dir:      cwd,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to hand dispatch to agent: %w", err)
+	}
+
+	fmt.Printf("📬 Dispatching notifications to %d handler(s) from %s in the background (job %s)\n", len(handlers.Handlers), path, job.ID)
+	fmt.Printf("\nCheck with: claw jobs · Stop with: claw jobs cancel %s\n", job.ID)
 	return nil
 }
+
+// shortID abbreviates IDs for display
+func shortID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
+
+func runInboxDaemon(cmd *cobra.Command, args []string) error {
+	cfg, err := account.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if !cfg.LoggedIn || cfg.TeamID == "" {
+		return fmt.Errorf("not logged in or team not configured")
+	}
+
+	path := daemonConfig
+	if path == "" {
+		path = inbox.GetHandlersPath()
+	}
+	handlers, err := inbox.LoadHandlers(path)
+	if err != nil {
+		return err
+	}
+
+	interval := daemonInterval
+	if interval == 0 {
+		interval = handlers.Interval(30 * time.Second)
+	}
+	if interval < time.Second {
+		return fmt.Errorf("--interval must be at least 1s")
+	}
+	if daemonBackground {
+		if daemonOnce {
+			return fmt.Errorf("use either --once or --background, not both")
+		}
+		return submitInboxDaemon(cfg, path, handlers, interval)
+	}
+
+	d := &inbox.Daemon{
+		Config:   cfg,
+		Handlers: handlers,
+		Interval: interval,
+		OnDelivery: func(dl inbox.Delivery) {
+			switch {
+			case dl.Err == nil:
+				fmt.Printf("📨 %s %s from %s → %s\n", shortID(dl.Notification.ID), dl.Notification.Type, dl.Notification.FromUser, dl.Handler)
+			case dl.GaveUp:
+				fmt.Fprintf(os.Stderr, "❌ %s → %s failed, giving up: %v\n", shortID(dl.Notification.ID), dl.Handler, dl.Err)
+			default:
+				fmt.Fprintf(os.Stderr, "⚠️  %s → %s failed, will retry: %v\n", shortID(dl.Notification.ID), dl.Handler, dl.Err)
+			}
+		},
+		OnPoll: func() {
+			fmt.Fprintf(os.Stderr, "Live notifications not available, polling every %s\n", interval)
+		},
+		OnError: func(err error) {
+			fmt.Fprintf(os.Stderr, "⚠️  %v\n", err)
+		},
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if daemonOnce {
+		d.Poll(ctx)
+		return nil
+	}
+
+	fmt.Fprintf(os.Stderr, "📬 Dispatching notifications to %d handler(s) from %s (Ctrl+C to stop)\n", len(handlers.Handlers), path)
+	return d.Run(ctx)
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/epuerta9/claw2claw/internal/account"
+	"github.com/epuerta9/claw2claw/internal/clawpack"
+	"github.com/spf13/cobra"
+)
+
+var (
+	packPurpose   string
+	packQuestions []string
+	packTODOs     []string
+	packNotes     string
+	packAuthor    string
+	packOutput    string
+)
+
+func newPackCmd() *cobra.Command {
+	packCmd := &cobra.Command{
+		Use:   "pack [files or directories...]",
+		Short: "Build a .clawpack context bundle",
+		Long: `Package files with a manifest describing why you're sharing them: purpose,
+author, repository and commit, open questions and TODOs.
+
+The receiver's 'claw read' shows a clawpack as a structured briefing
+instead of a pile of files. Paths must be inside the current directory;
+directories are added recursively (skipping .git and .claw).
+
+Examples:
+  claw pack docs/design.md internal/auth --purpose "Auth refactor handoff" \
+    --question "Keep the session cookie?" --todo "Migrate the token table"
+  claw send auth-refactor-handoff.clawpack`,
+		RunE: runPack,
+	}
+	packCmd.Flags().StringVar(&packPurpose, "purpose", "", "What this context is for")
+	packCmd.Flags().StringArrayVar(&packQuestions, "question", nil, "Open question for the receiver (repeatable)")
+	packCmd.Flags().StringArrayVar(&packTODOs, "todo", nil, "Outstanding TODO (repeatable)")
+	packCmd.Flags().StringVar(&packNotes, "notes", "", "Free-form notes")
+	packCmd.Flags().StringVar(&packAuthor, "author", "", "Author (default: your account or git user name)")
+	packCmd.Flags().StringVarP(&packOutput, "output", "o", "", "Output file (default: named after the purpose)")
+	return packCmd
+}
+
+func runPack(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 && packPurpose == "" && len(packQuestions) == 0 && len(packTODOs) == 0 && packNotes == "" {
+		return fmt.Errorf("nothing to pack: give files, or at least a --purpose")
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	files, err := collectPackFiles(cwd, args)
+	if err != nil {
+		return err
+	}
+
+	m := &clawpack.Manifest{
+		Purpose:    packPurpose,
+		Author:     packAuthor,
+		Repository: clawpack.DetectRepository(cwd),
+		Questions:  packQuestions,
+		TODOs:      packTODOs,
+		Notes:      packNotes,
+	}
+	if m.Author == "" {
+		m.Author = defaultPackAuthor(cwd)
+	}
+
+	out := packOutput
+	if out == "" {
+		out = packFileName(packPurpose, time.Now())
+	}
+	if !strings.HasSuffix(out, clawpack.Ext) {
+		out += clawpack.Ext
+	}
+
+	f, err := os.OpenFile(out, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		if os.IsExist(err) {
+			return fmt.Errorf("%s already exists (use -o to pick another name)", out)
+		}
+		return err
+	}
+	if err := clawpack.Write(f, m, cwd, files); err != nil {
+		f.Close()
+		os.Remove(out)
+		return fmt.Errorf("failed to build clawpack: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	fmt.Printf("📦 Packed %d file(s) → %s\n", len(m.Files), out)
+	if m.Repository != nil {
+		dirty := ""
+		if m.Repository.Dirty {
+			dirty = " (uncommitted changes)"
+		}
+		fmt.Printf("   Repository: %s @ %.12s%s\n", m.Repository.Branch, m.Repository.Commit, dirty)
+	}
+	if len(m.Questions) > 0 || len(m.TODOs) > 0 {
+		fmt.Printf("   %d question(s), %d TODO(s)\n", len(m.Questions), len(m.TODOs))
+	}
+	fmt.Printf("\nSend with: claw send %s\n", out)
+	return nil
+}
+
+// collectPackFiles expands args into file paths relative to root
+func collectPackFiles(root string, args []string) ([]string, error) {
+	var files []string
+	for _, arg := range args {
+		abs, err := filepath.Abs(arg)
+		if err != nil {
+			return nil, err
+		}
+		rel, err := filepath.Rel(root, abs)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return nil, fmt.Errorf("%s is outside the current directory", arg)
+		}
+
+		info, err := os.Stat(abs)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, rel)
+			continue
+		}
+
+		err = filepath.WalkDir(abs, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if p != abs && (d.Name() == ".git" || d.Name() == ".claw") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+			r, err := filepath.Rel(root, p)
+			if err != nil {
+				return err
+			}
+			files = append(files, r)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// defaultPackAuthor is the account name, then git's user name, then $USER
+func defaultPackAuthor(dir string) string {
+	if cfg, err := account.LoadConfig(); err == nil && cfg.LoggedIn {
+		if cfg.Name != "" {
+			return cfg.Name
+		}
+		if cfg.Email != "" {
+			return cfg.Email
+		}
+	}
+	if name := clawpack.GitAuthor(dir); name != "" {
+		return name
+	}
+	return os.Getenv("USER")
+}
+
+var slugRe = regexp.MustCompile(`[^a-z0-9]+`)
+
+// packFileName names a pack after its purpose, or the time if there is none
+func packFileName(purpose string, now time.Time) string {
+	slug := strings.Trim(slugRe.ReplaceAllString(strings.ToLower(purpose), "-"), "-")
+	if len(slug) > 40 {
+		slug = strings.TrimRight(slug[:40], "-")
+	}
+	if slug == "" {
+		slug = "context-" + now.Format("20060102-1504")
+	}
+	return slug + clawpack.Ext
+}
+
+// describePack validates a clawpack about to be sent and summarizes it.
+// Other files pass through untouched.
+func describePack(filePath string) error {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+	if !clawpack.IsPack(data) {
+		if strings.HasSuffix(filePath, clawpack.Ext) {
+			return fmt.Errorf("%s is not a valid clawpack", filePath)
+		}
+		return nil
+	}
+	p, err := clawpack.Open(filePath)
+	if err != nil {
+		return fmt.Errorf("invalid clawpack %s: %w", filePath, err)
+	}
+	m := p.Manifest
+	purpose := m.Purpose
+	if purpose == "" {
+		purpose = "(no purpose given)"
+	}
+	fmt.Printf("📦 Clawpack: %s (%d file(s), %d question(s), %d TODO(s))\n", purpose, len(m.Files), len(m.Questions), len(m.TODOs))
+	return nil
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/epuerta9/claw2claw/internal/account"
+	"github.com/spf13/cobra"
+)
+
+func newProfileCmd() *cobra.Command {
+	profileCmd := &cobra.Command{
+		Use:   "profile",
+		Short: "Manage account profiles (multiple teams or servers)",
+		Long: `Manage named account profiles.
+
+Each profile has its own login, team and server. The active profile is
+chosen in this order:
+
+  1. --profile flag
+  2. CLAW_PROFILE environment variable
+  3. .claw/config in the current project
+  4. claw profile use <name>
+  5. "default" (~/.claw/account.json)
+
+CLAW_TEAM, CLAW_BASE_URL and CLAW_RELAY override the profile's settings.`,
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List profiles",
+		RunE:  runProfileList,
+	}
+
+	useCmd := &cobra.Command{
+		Use:   "use <name>",
+		Short: "Switch the active profile",
+		Long: `Switch the active profile for this user, or pin it for the current project.
+
+Examples:
+  claw profile use work                         # Default for this user
+  claw profile use work --project               # Pin for this repo (.claw/config)
+  claw profile use work --project --team infra  # Pin profile and team`,
+		Args: cobra.ExactArgs(1),
+		RunE: runProfileUse,
+	}
+	useCmd.Flags().Bool("project", false, "Pin the profile in .claw/config for this project")
+	useCmd.Flags().String("team", "", "Team to pin for this project (with --project)")
+
+	addCmd := &cobra.Command{
+		Use:   "add <name>",
+		Short: "Create a new profile",
+		Long: `Create a new profile, then log in to it.
+
+Example:
+  claw profile add work --base-url https://claw.example.com
+  claw --profile work login`,
+		Args: cobra.ExactArgs(1),
+		RunE: runProfileAdd,
+	}
+	addCmd.Flags().String("base-url", "", "API server URL (for self-hosted servers)")
+	addCmd.Flags().String("relay-url", "", "Relay server URL")
+	addCmd.Flags().String("team", "", "Team slug")
+
+	profileCmd.AddCommand(listCmd, useCmd, addCmd)
+	return profileCmd
+}
+
+func runProfileList(cmd *cobra.Command, args []string) error {
+	names, err := account.ListProfiles()
+	if err != nil {
+		return fmt.Errorf("failed to list profiles: %w", err)
+	}
+
+	active := account.ActiveProfile()
+	fmt.Println("Profiles:")
+	fmt.Println()
+	for _, name := range names {
+		cfg, err := account.LoadProfile(name)
+		if err != nil {
+			fmt.Printf("    %-15s (unreadable: %v)\n", name, err)
+			continue
+		}
+
+		marker := "  "
+		if name == active {
+			marker = "* "
+		}
+		user := "not logged in"
+		if cfg.LoggedIn {
+			user = cfg.Email
+			if user == "" {
+				user = cfg.Name
+			}
+		}
+		team := cfg.TeamID
+		if team == "" {
+			team = "-"
+		}
+		fmt.Printf("  %s%-15s %-30s team: %-15s %s\n", marker, name, user, team, cfg.BaseURL)
+	}
+
+	fmt.Printf("\nActive: %s (from %s)\n", active, account.ProfileSource())
+	return nil
+}
+
+func runProfileUse(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	project, _ := cmd.Flags().GetBool("project")
+	team, _ := cmd.Flags().GetString("team")
+
+	if err := account.ValidateProfileName(name); err != nil {
+		return err
+	}
+	if !account.ProfileExists(name) {
+		return fmt.Errorf("profile %q not found. Create it with: claw profile add %s", name, name)
+	}
+
+	if !project {
+		if team != "" {
+			return fmt.Errorf("--team requires --project")
+		}
+		if err := account.UseProfile(name); err != nil {
+			return fmt.Errorf("failed to switch profile: %w", err)
+		}
+		fmt.Printf("Switched to profile '%s'\n", name)
+		return nil
+	}
+
+	pc, err := account.LoadProjectConfig()
+	if err != nil {
+		return err
+	}
+	pc.Profile = name
+	if team != "" {
+		pc.TeamID = team
+	}
+	if err := account.SaveProjectConfig(pc); err != nil {
+		return fmt.Errorf("failed to save project config: %w", err)
+	}
+
+	fmt.Printf("Pinned profile '%s' for this project", name)
+	if pc.TeamID != "" {
+		fmt.Printf(" (team: %s)", pc.TeamID)
+	}
+	fmt.Println()
+	fmt.Println("Saved to .claw/config")
+	return nil
+}
+
+func runProfileAdd(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	baseURL, _ := cmd.Flags().GetString("base-url")
+	relay, _ := cmd.Flags().GetString("relay-url")
+	team, _ := cmd.Flags().GetString("team")
+
+	cfg, err := account.AddProfile(name, baseURL, relay, team)
+	if err != nil {
+		return fmt.Errorf("failed to add profile: %w", err)
+	}
+
+	fmt.Printf("Created profile '%s' (%s)\n", name, cfg.BaseURL)
+	fmt.Println("\nLog in with:")
+	fmt.Printf("  claw --profile %s login\n", name)
+	fmt.Printf("\nMake it the default: claw profile use %s\n", name)
+	return nil
+}
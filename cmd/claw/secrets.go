@@ -0,0 +1,227 @@
+package main
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/epuerta9/claw2claw/internal/account"
+	"github.com/epuerta9/claw2claw/internal/manifest"
+	"github.com/epuerta9/claw2claw/internal/secrets"
+	"github.com/spf13/cobra"
+)
+
+func newSecretsCmd() *cobra.Command {
+	secretsCmd := &cobra.Command{
+		Use:   "secrets",
+		Short: "Manage the encrypted local store for tokens and channel codes",
+		Long: `Keep account tokens and channel codes in an encrypted local store
+(~/.claw/secrets.json) instead of plaintext config files.
+
+The store key is derived from a passphrase, or held by the OS keychain
+(macOS Keychain, or secret-tool on Linux). Config files and .claw/manifest.json
+then only contain "secret:" references, so they are safe to commit by accident.
+
+Unlock once per session with 'claw secrets unlock' - a small agent caches the
+key in memory so hooks and agents can use it. CLAW_PASSPHRASE also unlocks
+the store non-interactively (CI).`,
+	}
+
+	initCmd := &cobra.Command{
+		Use:   "init",
+		Short: "Create the secret store and move existing tokens and channel codes into it",
+		RunE:  runSecretsInit,
+	}
+	initCmd.Flags().Bool("keychain", false, "Keep the store key in the OS keychain instead of using a passphrase")
+
+	unlockCmd := &cobra.Command{
+		Use:   "unlock",
+		Short: "Unlock the store for this session (starts the unlock agent)",
+		RunE:  runSecretsUnlock,
+	}
+	unlockCmd.Flags().Duration("ttl", 8*time.Hour, "How long the agent keeps the store unlocked")
+
+	lockCmd := &cobra.Command{
+		Use:   "lock",
+		Short: "Stop the unlock agent and forget the key",
+		RunE:  runSecretsLock,
+	}
+
+	statusCmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show secret store status",
+		RunE:  runSecretsStatus,
+	}
+
+	migrateCmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Move plaintext tokens and this project's channel codes into the store",
+		RunE:  runSecretsMigrate,
+	}
+
+	agentCmd := &cobra.Command{
+		Use:    "agent",
+		Short:  "Run the unlock agent (started by 'claw secrets unlock')",
+		Hidden: true,
+		RunE:   runSecretsAgent,
+	}
+	agentCmd.Flags().Duration("ttl", 8*time.Hour, "How long to keep the key")
+
+	secretsCmd.AddCommand(initCmd, unlockCmd, lockCmd, statusCmd, migrateCmd, agentCmd)
+	return secretsCmd
+}
+
+func runSecretsInit(cmd *cobra.Command, args []string) error {
+	useKeychain, _ := cmd.Flags().GetBool("keychain")
+
+	if useKeychain {
+		if !secrets.KeychainAvailable() {
+			return fmt.Errorf("no OS keychain available (needs 'security' on macOS or 'secret-tool' on Linux)")
+		}
+		if err := secrets.Init("", true); err != nil {
+			return fmt.Errorf("failed to create secret store: %w", err)
+		}
+	} else {
+		passphrase := os.Getenv(secrets.EnvPassphrase)
+		if passphrase == "" {
+			var err error
+			passphrase, err = secrets.ReadPassphrase("🔐 New passphrase: ")
+			if err != nil {
+				return fmt.Errorf("failed to read passphrase: %w", err)
+			}
+			confirm, err := secrets.ReadPassphrase("🔐 Confirm passphrase: ")
+			if err != nil {
+				return fmt.Errorf("failed to read passphrase: %w", err)
+			}
+			if passphrase != confirm {
+				return fmt.Errorf("passphrases do not match")
+			}
+		}
+		if err := secrets.Init(passphrase, false); err != nil {
+			return fmt.Errorf("failed to create secret store: %w", err)
+		}
+	}
+
+	fmt.Printf("🔒 Secret store created: %s\n", secrets.GetStorePath())
+	return runSecretsMigrate(cmd, args)
+}
+
+func runSecretsMigrate(cmd *cobra.Command, args []string) error {
+	if !secrets.Enabled() {
+		return secrets.ErrNotInitialized
+	}
+
+	profiles, err := account.MigrateSecrets()
+	if err != nil {
+		return fmt.Errorf("failed to migrate account tokens: %w", err)
+	}
+	fmt.Printf("   Account tokens moved: %d profile(s)\n", profiles)
+
+	m, err := manifest.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load manifest: %w", err)
+	}
+	channels, err := m.MigrateSecrets()
+	if err != nil {
+		return fmt.Errorf("failed to migrate channel codes: %w", err)
+	}
+	if channels > 0 {
+		if err := m.Save(); err != nil {
+			return fmt.Errorf("failed to save manifest: %w", err)
+		}
+	}
+	fmt.Printf("   Channel codes moved:  %d (this project)\n", channels)
+	fmt.Println("\nRun 'claw secrets migrate' in other projects with channels.")
+	return nil
+}
+
+func runSecretsUnlock(cmd *cobra.Command, args []string) error {
+	ttl, _ := cmd.Flags().GetDuration("ttl")
+
+	source, err := secrets.KeySource()
+	if err != nil {
+		return err
+	}
+	if source == secrets.KDFKeychain {
+		fmt.Println("🔓 Store key is held by the OS keychain - no unlock needed.")
+		return nil
+	}
+	if secrets.AgentRunning() {
+		fmt.Println("🔓 Already unlocked (agent running). Use 'claw secrets lock' to lock.")
+		return nil
+	}
+
+	passphrase := os.Getenv(secrets.EnvPassphrase)
+	if passphrase == "" {
+		passphrase, err = secrets.ReadPassphrase("🔐 Secret store passphrase: ")
+		if err != nil {
+			return fmt.Errorf("failed to read passphrase: %w", err)
+		}
+	}
+
+	key, err := secrets.Unlock(passphrase)
+	if err != nil {
+		return err
+	}
+	if err := secrets.StartAgent(key, ttl); err != nil {
+		return err
+	}
+
+	fmt.Printf("🔓 Unlocked for %s\n", ttl)
+	return nil
+}
+
+func runSecretsLock(cmd *cobra.Command, args []string) error {
+	if !secrets.AgentRunning() {
+		fmt.Println("🔒 Already locked.")
+		return nil
+	}
+	if err := secrets.LockAgent(); err != nil {
+		return fmt.Errorf("failed to lock: %w", err)
+	}
+	fmt.Println("🔒 Locked.")
+	return nil
+}
+
+func runSecretsStatus(cmd *cobra.Command, args []string) error {
+	if !secrets.Enabled() {
+		fmt.Println("🔓 No secret store - tokens and channel codes are stored in plaintext.")
+		fmt.Println("   Create one with: claw secrets init")
+		return nil
+	}
+
+	source, err := secrets.KeySource()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Store: %s\n", secrets.GetStorePath())
+	switch source {
+	case secrets.KDFKeychain:
+		fmt.Println("Key:   OS keychain")
+	default:
+		fmt.Println("Key:   passphrase")
+		if secrets.AgentRunning() {
+			fmt.Println("State: unlocked (agent running)")
+		} else {
+			fmt.Println("State: locked")
+		}
+	}
+	return nil
+}
+
+func runSecretsAgent(cmd *cobra.Command, args []string) error {
+	ttl, _ := cmd.Flags().GetDuration("ttl")
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("failed to read key: %w", err)
+	}
+	key, err := hex.DecodeString(strings.TrimSpace(line))
+	if err != nil {
+		return fmt.Errorf("invalid key: %w", err)
+	}
+	return secrets.ServeAgent(key, ttl)
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/epuerta9/claw2claw/internal/transcript"
+	"github.com/spf13/cobra"
+)
+
+var (
+	sendTranscript   bool   // For send - send a session transcript instead of a file
+	transcriptTurns  string // Turn range, e.g. 3-7
+	transcriptLast   int    // Only the last N turns
+	transcriptOutput string // Tool output mode
+)
+
+// sendArgs takes a file, an optional session with --transcript or an
+// optional range with --git
+func sendArgs(cmd *cobra.Command, args []string) error {
+	if sendTranscript && sendGit {
+		return fmt.Errorf("use either --transcript or --git, not both")
+	}
+	if (gitStaged || gitWorktree) && !sendGit {
+		return fmt.Errorf("--staged and --worktree need --git")
+	}
+	if sendTranscript || sendGit {
+		return cobra.MaximumNArgs(1)(cmd, args)
+	}
+	return cobra.ExactArgs(1)(cmd, args)
+}
+
+// writeTranscriptBundle builds a handoff bundle from a Claude Code session
+// and writes it to a temp file for sending. cleanup removes it.
+func writeTranscriptBundle(session string) (path string, cleanup func(), err error) {
+	opts := transcript.Options{Last: transcriptLast, ToolOutput: transcriptOutput}
+	if transcriptTurns != "" {
+		if transcriptLast > 0 {
+			return "", nil, fmt.Errorf("use either --turns or --last, not both")
+		}
+		if opts.From, opts.To, err = transcript.ParseRange(transcriptTurns); err != nil {
+			return "", nil, err
+		}
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", nil, err
+	}
+	source, err := transcript.Find(cwd, session)
+	if err != nil {
+		return "", nil, err
+	}
+	t, err := transcript.Load(source)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to load transcript: %w", err)
+	}
+	b, err := transcript.Build(t, opts)
+	if err != nil {
+		return "", nil, err
+	}
+	data, err := b.Marshal()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to encode handoff: %w", err)
+	}
+
+	dir, err := os.MkdirTemp("", "claw-handoff-")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup = func() { os.RemoveAll(dir) }
+
+	name := "handoff-" + shortID(b.SessionID) + transcript.BundleExt
+	path = filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+
+	fmt.Printf("🧾 Transcript: session %s, turns %d-%d of %d (tool output: %s)\n",
+		shortID(b.SessionID), b.From, b.To, b.TotalTurns, b.ToolOutput)
+	if b.Redactions > 0 {
+		fmt.Printf("🔒 Redacted %d secret(s)\n", b.Redactions)
+	}
+	return path, cleanup, nil
+}
@@ -12,6 +12,8 @@ import (
 	"runtime"
 	"strings"
 	"time"
+
+	"github.com/epuerta9/claw2claw/internal/secrets"
 )
 
 // Config holds account configuration
@@ -24,42 +26,96 @@ type Config struct {
 	UserID         string `json:"user_id,omitempty"`
 	TeamID         string `json:"team_id,omitempty"`
 	LastBoardCheck string `json:"last_board_check,omitempty"` // RFC3339 timestamp
+	RefreshToken   string `json:"refresh_token,omitempty"`
+	TokenExpiresAt string `json:"token_expires_at,omitempty"` // RFC3339 timestamp
+	RelayURL       string `json:"relay_url,omitempty"`
+
+	profile    string
+	path       string
+	fromFile   settings // Values as stored in the profile file
+	overridden settings // Values applied from project config or environment
 }
 
-const configFileName = ".claw/account.json"
+// settings are the config values that can be overridden per project or via environment
+type settings struct {
+	TeamID   string
+	BaseURL  string
+	RelayURL string
+}
 
-// GetConfigPath returns the path to the config file
+const (
+	configFileName = ".claw/account.json"
+	defaultBaseURL = "https://claw2claw.cloudshipai.com"
+)
+
+// GetConfigPath returns the path to the config file of the active profile
 func GetConfigPath() string {
-	home, _ := os.UserHomeDir()
-	return filepath.Join(home, configFileName)
+	return GetProfilePath(ActiveProfile())
 }
 
-// LoadConfig loads the account configuration
+// LoadConfig loads the account configuration for the active profile,
+// applying project (.claw/config) and environment overrides
 func LoadConfig() (*Config, error) {
-	path := GetConfigPath()
-	data, err := os.ReadFile(path)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return &Config{BaseURL: "https://claw2claw.cloudshipai.com"}, nil
-		}
+	profile := ActiveProfile()
+	if err := ValidateProfileName(profile); err != nil {
 		return nil, err
 	}
+	path := GetProfilePath(profile)
 
-	var cfg Config
-	if err := json.Unmarshal(data, &cfg); err != nil {
+	cfg := &Config{}
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
 		return nil, err
 	}
-	return &cfg, nil
+	if err == nil {
+		if err := json.Unmarshal(data, cfg); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = defaultBaseURL
+	}
+
+	cfg.profile = profile
+	cfg.path = path
+	cfg.applyOverrides()
+	return cfg, nil
 }
 
-// SaveConfig saves the account configuration
+// SaveConfig saves the account configuration to its profile file.
+// Values that came from project config or environment overrides are not persisted.
 func SaveConfig(cfg *Config) error {
-	path := GetConfigPath()
+	path := cfg.path
+	if path == "" {
+		path = GetConfigPath()
+	}
 	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
 		return err
 	}
 
-	data, err := json.MarshalIndent(cfg, "", "  ")
+	out := *cfg
+	if cfg.overridden.TeamID != "" && out.TeamID == cfg.overridden.TeamID {
+		out.TeamID = cfg.fromFile.TeamID
+	}
+	if cfg.overridden.BaseURL != "" && out.BaseURL == cfg.overridden.BaseURL {
+		out.BaseURL = cfg.fromFile.BaseURL
+	}
+	if cfg.overridden.RelayURL != "" && out.RelayURL == cfg.overridden.RelayURL {
+		out.RelayURL = cfg.fromFile.RelayURL
+	}
+
+	// Keep tokens in the encrypted secret store when one is set up
+	if secrets.Enabled() {
+		var err error
+		if out.Token, err = storeSecret(cfg.Profile()+"/token", out.Token); err != nil {
+			return err
+		}
+		if out.RefreshToken, err = storeSecret(cfg.Profile()+"/refresh_token", out.RefreshToken); err != nil {
+			return err
+		}
+	}
+
+	data, err := json.MarshalIndent(&out, "", "  ")
 	if err != nil {
 		return err
 	}
@@ -67,6 +123,40 @@ func SaveConfig(cfg *Config) error {
 	return os.WriteFile(path, data, 0600)
 }
 
+// storeSecret moves a credential into the secret store and returns its reference.
+// Clearing a credential (logout, expiry) removes it from the store.
+func storeSecret(name, value string) (string, error) {
+	if value == "" {
+		secrets.Delete("profile/" + name) // Best effort, the store may be locked
+		return "", nil
+	}
+	ref, err := secrets.Store("profile/"+name, value)
+	if err != nil {
+		return "", fmt.Errorf("failed to store credential: %w", err)
+	}
+	return ref, nil
+}
+
+// Profile returns the name of the profile this config was loaded from
+func (cfg *Config) Profile() string {
+	if cfg.profile == "" {
+		return DefaultProfile
+	}
+	return cfg.profile
+}
+
+// MemberName returns the name the user goes by in the team: their user ID,
+// falling back to their name and then email for older configs
+func (cfg *Config) MemberName() string {
+	if cfg.UserID != "" {
+		return cfg.UserID
+	}
+	if cfg.Name != "" {
+		return cfg.Name
+	}
+	return cfg.Email
+}
+
 // DeviceAuthResponse from the API
 type DeviceAuthResponse struct {
 	DeviceCode      string `json:"device_code"`
@@ -78,9 +168,11 @@ type DeviceAuthResponse struct {
 
 // TokenResponse from the API
 type TokenResponse struct {
-	Status      string `json:"status"`
-	AccessToken string `json:"access_token"`
-	TokenType   string `json:"token_type"`
+	Status       string `json:"status"`
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	ExpiresIn    int    `json:"expires_in,omitempty"` // Seconds until AccessToken expires
 }
 
 // Login performs the device auth flow
@@ -133,13 +225,14 @@ func Login(baseURL string) (*Config, error) {
 				fmt.Println("\n✅ Logged in successfully!")
 
 				cfg := &Config{
-					Token:    tokenResp.AccessToken,
 					BaseURL:  baseURL,
 					LoggedIn: true,
 				}
+				cfg.applyToken(tokenResp)
 
 				// Fetch user info
 				if user, err := fetchUser(baseURL, tokenResp.AccessToken); err == nil {
+					cfg.UserID = user.ID
 					cfg.Email = user.Email
 					cfg.Name = user.Name
 				}
@@ -174,6 +267,7 @@ func (s stringReader) Read(p []byte) (n int, err error) {
 }
 
 type UserInfo struct {
+	ID    string `json:"id"`
 	Email string `json:"email"`
 	Name  string `json:"name"`
 }
@@ -215,9 +309,8 @@ func ListSessions(cfg *Config) ([]Session, error) {
 	}
 
 	req, _ := http.NewRequest("GET", cfg.BaseURL+"/api/v1/sessions", nil)
-	req.Header.Set("Authorization", "Bearer "+cfg.Token)
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := do(cfg, req)
 	if err != nil {
 		return nil, err
 	}
@@ -276,10 +369,9 @@ func CreateSession(cfg *Config, title, roomID string) (*Session, error) {
 	body := fmt.Sprintf(`{"title":%q,"room_id":%q,"visibility":"private"}`, title, roomID)
 
 	req, _ := http.NewRequest("POST", cfg.BaseURL+"/api/v1/sessions", strings.NewReader(body))
-	req.Header.Set("Authorization", "Bearer "+cfg.Token)
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := do(cfg, req)
 	if err != nil {
 		return nil, err
 	}
@@ -306,10 +398,9 @@ func FindOrCreateSession(cfg *Config, title, roomID string) (*Session, bool, err
 	body := fmt.Sprintf(`{"title":%q,"room_id":%q}`, title, roomID)
 
 	req, _ := http.NewRequest("POST", cfg.BaseURL+"/api/v1/sessions/find-or-create", strings.NewReader(body))
-	req.Header.Set("Authorization", "Bearer "+cfg.Token)
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := do(cfg, req)
 	if err != nil {
 		return nil, false, err
 	}
@@ -347,10 +438,9 @@ func AddMessageWithContent(cfg *Config, sessionID, direction, filename string, f
 
 	req, _ := http.NewRequest("POST", cfg.BaseURL+"/api/v1/sessions/"+sessionID+"/messages",
 		strings.NewReader(body))
-	req.Header.Set("Authorization", "Bearer "+cfg.Token)
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := do(cfg, req)
 	if err != nil {
 		return err
 	}
@@ -390,9 +480,8 @@ func GetSessionContext(cfg *Config, sessionID string) (*SessionContext, error) {
 	}
 
 	req, _ := http.NewRequest("GET", cfg.BaseURL+"/api/v1/sessions/"+sessionID+"/context", nil)
-	req.Header.Set("Authorization", "Bearer "+cfg.Token)
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := do(cfg, req)
 	if err != nil {
 		return nil, err
 	}
@@ -3,15 +3,24 @@ package account
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"mime"
 	"mime/multipart"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/epuerta9/claw2claw/internal/crypto"
+	"github.com/epuerta9/claw2claw/internal/safereader"
 )
 
 // BoardSection represents a section of the shared team board
@@ -27,16 +36,43 @@ type BoardSection struct {
 
 // Notification represents a notification
 type Notification struct {
-	ID         string     `json:"id"`
-	TeamID     string     `json:"team_id"`
-	FromUser   string     `json:"from_user"`
-	ToUser     string     `json:"to_user"`
-	Type       string     `json:"type"`
-	Subject    string     `json:"subject"`
-	Body       string     `json:"body,omitempty"`
-	RefSection string     `json:"ref_section,omitempty"`
-	ReadAt     *time.Time `json:"read_at,omitempty"`
-	CreatedAt  time.Time  `json:"created_at"`
+	ID          string       `json:"id"`
+	TeamID      string       `json:"team_id"`
+	FromUser    string       `json:"from_user"`
+	ToUser      string       `json:"to_user"`
+	Type        string       `json:"type"`
+	Subject     string       `json:"subject"`
+	Body        string       `json:"body,omitempty"`
+	RefSection  string       `json:"ref_section,omitempty"`
+	ThreadID    string       `json:"thread_id,omitempty"` // ID of the first notification in the conversation
+	ParentID    string       `json:"parent_id,omitempty"` // Notification this one replies to
+	Attachments []Attachment `json:"attachments,omitempty"`
+	ReadAt      *time.Time   `json:"read_at,omitempty"`
+	CreatedAt   time.Time    `json:"created_at"`
+}
+
+// Attachment kinds
+const (
+	AttachmentFile = "file" // Uploaded shared file (claw share)
+	AttachmentRoom = "room" // End-to-end encrypted persistent room; the code travels out of band
+)
+
+// Attachment links a file to a notification
+type Attachment struct {
+	Kind     string `json:"kind"`
+	Filename string `json:"filename"`
+	Size     int64  `json:"size,omitempty"`
+	FileID   string `json:"file_id,omitempty"`
+	RoomID   string `json:"room_id,omitempty"`
+}
+
+// Thread returns the conversation this notification belongs to
+// (its own ID if it started one)
+func (n *Notification) Thread() string {
+	if n.ThreadID != "" {
+		return n.ThreadID
+	}
+	return n.ID
 }
 
 // SharedFile represents a shared file
@@ -47,6 +83,18 @@ type SharedFile struct {
 	Filename   string    `json:"filename"`
 	Size       int64     `json:"size"`
 	CreatedAt  time.Time `json:"created_at"`
+
+	// Set locally after an encrypted upload
+	Encrypted  bool     `json:"-"`
+	Unreadable []string `json:"-"` // Team members without a key at upload time
+}
+
+// SealedSuffix marks uploads that are end-to-end encrypted
+const SealedSuffix = ".sealed"
+
+// IsEncrypted reports whether a shared file was uploaded encrypted
+func (f *SharedFile) IsEncrypted() bool {
+	return strings.HasSuffix(f.Filename, SealedSuffix)
 }
 
 // InboxSummary is the response from the inbox endpoint
@@ -63,9 +111,8 @@ func GetBoard(cfg *Config) ([]BoardSection, error) {
 	}
 
 	req, _ := http.NewRequest("GET", cfg.BaseURL+"/api/v1/board/"+cfg.TeamID, nil)
-	req.Header.Set("Authorization", "Bearer "+cfg.Token)
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := do(cfg, req)
 	if err != nil {
 		return nil, err
 	}
@@ -84,6 +131,79 @@ func GetBoard(cfg *Config) ([]BoardSection, error) {
 	return result.Sections, nil
 }
 
+// ErrStreamUnsupported is returned when the server has no board event stream
+var ErrStreamUnsupported = errors.New("board event stream not supported by server")
+
+// StatusError is an unexpected HTTP status from the server
+type StatusError struct {
+	Op   string // What failed, e.g. "get board"
+	Code int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("failed to %s: %d", e.Op, e.Code)
+}
+
+// Temporary reports whether retrying later may succeed (server errors,
+// timeouts and rate limits) rather than the request being refused
+func (e *StatusError) Temporary() bool {
+	return e.Code >= 500 || e.Code == http.StatusRequestTimeout || e.Code == http.StatusTooManyRequests
+}
+
+// GetBoardIfChanged fetches the full board unless it still matches etag.
+// Returns changed=false (and no sections) on 304 Not Modified.
+func GetBoardIfChanged(cfg *Config, etag string) (sections []BoardSection, newETag string, changed bool, err error) {
+	if !cfg.LoggedIn || cfg.TeamID == "" {
+		return nil, "", false, fmt.Errorf("not logged in or team not configured")
+	}
+
+	req, _ := http.NewRequest("GET", cfg.BaseURL+"/api/v1/board/"+cfg.TeamID, nil)
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	resp, err := do(cfg, req)
+	if err != nil {
+		return nil, "", false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, etag, false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", false, &StatusError{Op: "get board", Code: resp.StatusCode}
+	}
+
+	var result struct {
+		Sections []BoardSection `json:"sections"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, "", false, err
+	}
+	return result.Sections, resp.Header.Get("ETag"), true, nil
+}
+
+// StreamBoard subscribes to board updates as server-sent events, calling
+// onUpdate with each updated section until ctx is cancelled or the stream ends.
+// Returns ErrStreamUnsupported if the server doesn't offer the stream.
+func StreamBoard(ctx context.Context, cfg *Config, onUpdate func(BoardSection)) error {
+	if !cfg.LoggedIn || cfg.TeamID == "" {
+		return fmt.Errorf("not logged in or team not configured")
+	}
+
+	url := cfg.BaseURL + "/api/v1/board/" + cfg.TeamID + "/events"
+	return streamEvents(ctx, cfg, url, func(event string, data []byte) {
+		if event != "" && event != "section" {
+			return
+		}
+		var bs BoardSection
+		if err := json.Unmarshal(data, &bs); err == nil && bs.Section != "" {
+			onUpdate(bs)
+		}
+	})
+}
+
 // GetBoardSection fetches a single board section
 func GetBoardSection(cfg *Config, section string) (*BoardSection, error) {
 	if !cfg.LoggedIn || cfg.TeamID == "" {
@@ -91,9 +211,8 @@ func GetBoardSection(cfg *Config, section string) (*BoardSection, error) {
 	}
 
 	req, _ := http.NewRequest("GET", cfg.BaseURL+"/api/v1/board/"+cfg.TeamID+"/"+section, nil)
-	req.Header.Set("Authorization", "Bearer "+cfg.Token)
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := do(cfg, req)
 	if err != nil {
 		return nil, err
 	}
@@ -113,7 +232,10 @@ func GetBoardSection(cfg *Config, section string) (*BoardSection, error) {
 	return &bs, nil
 }
 
-// UpdateBoardSection updates a board section
+// ErrVersionConflict is returned when a section changed since the version an update was based on
+var ErrVersionConflict = errors.New("section was changed by someone else")
+
+// UpdateBoardSection updates a board section, based on whatever version is current
 func UpdateBoardSection(cfg *Config, section, content string) (*BoardSection, error) {
 	if !cfg.LoggedIn || cfg.TeamID == "" {
 		return nil, fmt.Errorf("not logged in or team not configured")
@@ -129,21 +251,98 @@ func UpdateBoardSection(cfg *Config, section, content string) (*BoardSection, er
 		version = existing.Version
 	}
 
-	body := fmt.Sprintf(`{"content":%q,"version":%d}`, content, version)
+	return PutBoardSection(cfg, section, content, version)
+}
+
+// PutBoardSection writes a board section if it is still at baseVersion
+// (0 for a new section). Returns ErrVersionConflict if it has moved on.
+func PutBoardSection(cfg *Config, section, content string, baseVersion int) (*BoardSection, error) {
+	if !cfg.LoggedIn || cfg.TeamID == "" {
+		return nil, fmt.Errorf("not logged in or team not configured")
+	}
+
+	body := fmt.Sprintf(`{"content":%q,"version":%d}`, content, baseVersion)
 	req, _ := http.NewRequest("PUT", cfg.BaseURL+"/api/v1/board/"+cfg.TeamID+"/"+section,
 		strings.NewReader(body))
-	req.Header.Set("Authorization", "Bearer "+cfg.Token)
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := do(cfg, req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusConflict || resp.StatusCode == http.StatusPreconditionFailed {
+		return nil, fmt.Errorf("%w (based on v%d)", ErrVersionConflict, baseVersion)
+	}
 	if resp.StatusCode != http.StatusOK {
-		respBody, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("failed to update section: %d %s", resp.StatusCode, string(respBody))
+		var errResp struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&errResp)
+		if errResp.Error != "" {
+			return nil, fmt.Errorf("failed to update section: %s", errResp.Error)
+		}
+		return nil, fmt.Errorf("failed to update section: %d", resp.StatusCode)
+	}
+
+	var bs BoardSection
+	if err := json.NewDecoder(resp.Body).Decode(&bs); err != nil {
+		return nil, err
+	}
+	return &bs, nil
+}
+
+// GetBoardHistory fetches all stored versions of a board section, newest first
+func GetBoardHistory(cfg *Config, section string) ([]BoardSection, error) {
+	if !cfg.LoggedIn || cfg.TeamID == "" {
+		return nil, fmt.Errorf("not logged in or team not configured")
+	}
+
+	req, _ := http.NewRequest("GET", cfg.BaseURL+"/api/v1/board/"+cfg.TeamID+"/"+section+"/history", nil)
+
+	resp, err := do(cfg, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get section history: %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Revisions []BoardSection `json:"revisions"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	return result.Revisions, nil
+}
+
+// GetBoardRevision fetches a specific version of a board section
+func GetBoardRevision(cfg *Config, section string, version int) (*BoardSection, error) {
+	if !cfg.LoggedIn || cfg.TeamID == "" {
+		return nil, fmt.Errorf("not logged in or team not configured")
+	}
+
+	req, _ := http.NewRequest("GET", fmt.Sprintf("%s/api/v1/board/%s/%s/history/%d",
+		cfg.BaseURL, cfg.TeamID, section, version), nil)
+
+	resp, err := do(cfg, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("section '%s' has no version %d", section, version)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get revision: %d", resp.StatusCode)
 	}
 
 	var bs BoardSection
@@ -164,10 +363,9 @@ func InitBoard(cfg *Config, members []string) error {
 
 	req, _ := http.NewRequest("POST", cfg.BaseURL+"/api/v1/board/"+cfg.TeamID+"/init",
 		strings.NewReader(body))
-	req.Header.Set("Authorization", "Bearer "+cfg.Token)
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := do(cfg, req)
 	if err != nil {
 		return err
 	}
@@ -181,27 +379,41 @@ func InitBoard(cfg *Config, members []string) error {
 
 // SendNotification creates a notification
 func SendNotification(cfg *Config, toUser, notifType, subject, body string) (*Notification, error) {
+	return PostNotification(cfg, &Notification{ToUser: toUser, Type: notifType, Subject: subject, Body: body})
+}
+
+// PostNotification sends a notification, including thread and reply links.
+// FromUser defaults to the current user.
+func PostNotification(cfg *Config, n *Notification) (*Notification, error) {
 	if !cfg.LoggedIn || cfg.TeamID == "" {
 		return nil, fmt.Errorf("not logged in or team not configured")
 	}
 
-	fromUser := cfg.UserID
+	fromUser := n.FromUser
 	if fromUser == "" {
-		fromUser = cfg.Name
-		if fromUser == "" {
-			fromUser = cfg.Email
-		}
+		fromUser = cfg.MemberName()
+	}
+
+	reqBody, err := json.Marshal(map[string]interface{}{
+		"from_user":   fromUser,
+		"to_user":     n.ToUser,
+		"type":        n.Type,
+		"subject":     n.Subject,
+		"body":        n.Body,
+		"ref_section": n.RefSection,
+		"thread_id":   n.ThreadID,
+		"parent_id":   n.ParentID,
+		"attachments": n.Attachments,
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	reqBody := fmt.Sprintf(`{"from_user":%q,"to_user":%q,"type":%q,"subject":%q,"body":%q}`,
-		fromUser, toUser, notifType, subject, body)
-
 	req, _ := http.NewRequest("POST", cfg.BaseURL+"/api/v1/notifications/"+cfg.TeamID,
-		strings.NewReader(reqBody))
-	req.Header.Set("Authorization", "Bearer "+cfg.Token)
+		bytes.NewReader(reqBody))
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := do(cfg, req)
 	if err != nil {
 		return nil, err
 	}
@@ -211,11 +423,75 @@ func SendNotification(cfg *Config, toUser, notifType, subject, body string) (*No
 		return nil, fmt.Errorf("failed to create notification: %d", resp.StatusCode)
 	}
 
-	var n Notification
-	if err := json.NewDecoder(resp.Body).Decode(&n); err != nil {
+	var sent Notification
+	if err := json.NewDecoder(resp.Body).Decode(&sent); err != nil {
 		return nil, err
 	}
-	return &n, nil
+	// Older servers don't echo thread fields back
+	if sent.ThreadID == "" {
+		sent.ThreadID = n.ThreadID
+	}
+	if sent.ParentID == "" {
+		sent.ParentID = n.ParentID
+	}
+	if sent.RefSection == "" {
+		sent.RefSection = n.RefSection
+	}
+	if len(sent.Attachments) == 0 {
+		sent.Attachments = n.Attachments
+	}
+	return &sent, nil
+}
+
+// GetThread fetches every notification in a thread, oldest first.
+// Returns nil (and no error) if the server doesn't know the thread.
+func GetThread(cfg *Config, threadID string) ([]Notification, error) {
+	if !cfg.LoggedIn || cfg.TeamID == "" {
+		return nil, fmt.Errorf("not logged in or team not configured")
+	}
+
+	req, _ := http.NewRequest("GET", cfg.BaseURL+"/api/v1/notifications/"+cfg.TeamID+"/thread/"+threadID, nil)
+
+	resp, err := do(cfg, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get thread: %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Notifications []Notification `json:"notifications"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	return result.Notifications, nil
+}
+
+// StreamNotifications subscribes to new notifications for a user as server-sent
+// events until ctx is cancelled or the stream ends.
+// Returns ErrStreamUnsupported if the server doesn't offer the stream.
+func StreamNotifications(ctx context.Context, cfg *Config, userID string, onNotification func(Notification)) error {
+	if !cfg.LoggedIn || cfg.TeamID == "" {
+		return fmt.Errorf("not logged in or team not configured")
+	}
+
+	url := cfg.BaseURL + "/api/v1/notifications/" + cfg.TeamID + "/" + userID + "/events"
+	return streamEvents(ctx, cfg, url, func(event string, data []byte) {
+		if event != "" && event != "notification" {
+			return
+		}
+		var n Notification
+		if err := json.Unmarshal(data, &n); err == nil && n.ID != "" {
+			onNotification(n)
+		}
+	})
 }
 
 // GetNotifications fetches notifications for a user
@@ -230,9 +506,8 @@ func GetNotifications(cfg *Config, userID string, unreadOnly bool) ([]Notificati
 	}
 
 	req, _ := http.NewRequest("GET", url, nil)
-	req.Header.Set("Authorization", "Bearer "+cfg.Token)
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := do(cfg, req)
 	if err != nil {
 		return nil, err
 	}
@@ -250,9 +525,8 @@ func GetNotifications(cfg *Config, userID string, unreadOnly bool) ([]Notificati
 // MarkNotificationRead marks a notification as read
 func MarkNotificationRead(cfg *Config, notificationID string) error {
 	req, _ := http.NewRequest("PATCH", cfg.BaseURL+"/api/v1/notifications/"+notificationID+"/read", nil)
-	req.Header.Set("Authorization", "Bearer "+cfg.Token)
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := do(cfg, req)
 	if err != nil {
 		return err
 	}
@@ -264,29 +538,36 @@ func MarkNotificationRead(cfg *Config, notificationID string) error {
 	return nil
 }
 
-// GetInbox fetches the inbox summary for session-start check
+// GetInbox fetches the inbox summary since the last check and moves the
+// checkpoint forward
 func GetInbox(cfg *Config) (*InboxSummary, error) {
-	if !cfg.LoggedIn || cfg.TeamID == "" {
-		return nil, fmt.Errorf("not logged in or team not configured")
+	inbox, err := PeekInbox(cfg)
+	if err != nil {
+		return nil, err
 	}
 
-	userID := cfg.UserID
-	if userID == "" {
-		userID = cfg.Name
-		if userID == "" {
-			userID = cfg.Email
-		}
+	// Update last check timestamp
+	cfg.LastBoardCheck = time.Now().Format(time.RFC3339)
+	SaveConfig(cfg)
+
+	return inbox, nil
+}
+
+// PeekInbox fetches the inbox summary since the last check without moving
+// the checkpoint, so what it shows is still reported by the next GetInbox
+func PeekInbox(cfg *Config) (*InboxSummary, error) {
+	if !cfg.LoggedIn || cfg.TeamID == "" {
+		return nil, fmt.Errorf("not logged in or team not configured")
 	}
 
-	url := cfg.BaseURL + "/api/v1/inbox/" + cfg.TeamID + "/" + userID
+	url := cfg.BaseURL + "/api/v1/inbox/" + cfg.TeamID + "/" + cfg.MemberName()
 	if cfg.LastBoardCheck != "" {
 		url += "?since=" + cfg.LastBoardCheck
 	}
 
 	req, _ := http.NewRequest("GET", url, nil)
-	req.Header.Set("Authorization", "Bearer "+cfg.Token)
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := do(cfg, req)
 	if err != nil {
 		return nil, err
 	}
@@ -300,43 +581,78 @@ func GetInbox(cfg *Config) (*InboxSummary, error) {
 	if err := json.NewDecoder(resp.Body).Decode(&inbox); err != nil {
 		return nil, err
 	}
-
-	// Update last check timestamp
-	cfg.LastBoardCheck = time.Now().Format(time.RFC3339)
-	SaveConfig(cfg)
-
 	return &inbox, nil
 }
 
-// UploadFile uploads a file to the team board
+// UploadFile encrypts a file to every team member's public key and uploads
+// it. The server only receives ciphertext under a random name.
 func UploadFile(cfg *Config, filePath string) (*SharedFile, error) {
-	if !cfg.LoggedIn || cfg.TeamID == "" {
-		return nil, fmt.Errorf("not logged in or team not configured")
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
 	}
 
+	id, _, err := EnsureIdentity(cfg)
+	if err != nil {
+		return nil, err
+	}
+	recipients, err := TeamRecipients(cfg, id)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := sealFile(filepath.Base(filePath), content, recipients)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt file: %w", err)
+	}
+
+	name, err := crypto.GenerateRandom(8)
+	if err != nil {
+		return nil, err
+	}
+	sf, err := UploadData(cfg, hex.EncodeToString(name)+SealedSuffix, bytes.NewReader(sealed))
+	if err != nil {
+		return nil, err
+	}
+	sf.Filename = filepath.Base(filePath)
+	sf.Size = int64(len(content))
+	sf.Encrypted = true
+	sf.Unreadable = recipients.Missing
+	return sf, nil
+}
+
+// UploadPlaintextFile uploads a file to the team board without encryption
+func UploadPlaintextFile(cfg *Config, filePath string) (*SharedFile, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open file: %w", err)
 	}
 	defer file.Close()
 
+	return UploadData(cfg, filepath.Base(filePath), file)
+}
+
+// UploadData uploads content under the given filename to the team board
+func UploadData(cfg *Config, filename string, content io.Reader) (*SharedFile, error) {
+	if !cfg.LoggedIn || cfg.TeamID == "" {
+		return nil, fmt.Errorf("not logged in or team not configured")
+	}
+
 	var buf bytes.Buffer
 	writer := multipart.NewWriter(&buf)
 
-	part, err := writer.CreateFormFile("file", filepath.Base(filePath))
+	part, err := writer.CreateFormFile("file", filename)
 	if err != nil {
 		return nil, err
 	}
-	if _, err := io.Copy(part, file); err != nil {
+	if _, err := io.Copy(part, content); err != nil {
 		return nil, err
 	}
 	writer.Close()
 
 	req, _ := http.NewRequest("POST", cfg.BaseURL+"/api/v1/files/"+cfg.TeamID+"/upload", &buf)
-	req.Header.Set("Authorization", "Bearer "+cfg.Token)
 	req.Header.Set("Content-Type", writer.FormDataContentType())
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := do(cfg, req)
 	if err != nil {
 		return nil, err
 	}
@@ -360,9 +676,8 @@ func ListFiles(cfg *Config) ([]SharedFile, error) {
 	}
 
 	req, _ := http.NewRequest("GET", cfg.BaseURL+"/api/v1/files/"+cfg.TeamID, nil)
-	req.Header.Set("Authorization", "Bearer "+cfg.Token)
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := do(cfg, req)
 	if err != nil {
 		return nil, err
 	}
@@ -377,47 +692,85 @@ func ListFiles(cfg *Config) ([]SharedFile, error) {
 	return result.Files, nil
 }
 
-// DownloadFile downloads a shared file to the specified directory
-func DownloadFile(cfg *Config, fileID, outputDir string) (string, error) {
-	if !cfg.LoggedIn || cfg.TeamID == "" {
-		return "", fmt.Errorf("not logged in or team not configured")
-	}
+// ErrHashMismatch means a download did not match the server's content hash
+var ErrHashMismatch = errors.New("downloaded content does not match the server's hash")
 
-	req, _ := http.NewRequest("GET", cfg.BaseURL+"/api/v1/files/"+cfg.TeamID+"/"+fileID, nil)
-	req.Header.Set("Authorization", "Bearer "+cfg.Token)
+// maxDownloadSize bounds a shared file download; the whole file is held in
+// memory to be checked and decrypted
+const maxDownloadSize = 100 << 20
 
-	resp, err := http.DefaultClient.Do(req)
+// DownloadedFile is a shared file saved locally by DownloadFile
+type DownloadedFile struct {
+	FileID    string
+	Name      string                  // Filename as uploaded, before sanitizing
+	Scan      *safereader.SafeContent // Saved content and its scan; Scan.Path is where it was written
+	Encrypted bool                    // Decrypted locally
+	Verified  bool                    // Server provided a content hash and it matched
+}
+
+// DownloadFile downloads a shared file into outputDir. The content is checked
+// against the server's hash, decrypted locally if it was uploaded encrypted,
+// and written with safereader's hardened writer (sanitized name, no overwrites).
+func DownloadFile(cfg *Config, fileID, outputDir string) (*DownloadedFile, error) {
+	filename, data, hash, err := downloadData(cfg, fileID)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("download failed: %d", resp.StatusCode)
+	d := &DownloadedFile{FileID: fileID}
+	if hash != "" {
+		sum := sha256.Sum256(data)
+		if !strings.EqualFold(hash, hex.EncodeToString(sum[:])) {
+			return nil, ErrHashMismatch
+		}
+		d.Verified = true
 	}
 
-	// Get filename from Content-Disposition header
-	filename := "downloaded_file"
-	cd := resp.Header.Get("Content-Disposition")
-	if strings.Contains(cd, "filename=") {
-		parts := strings.SplitN(cd, "filename=", 2)
-		if len(parts) == 2 {
-			filename = strings.Trim(parts[1], "\"")
+	if crypto.IsSealed(data) {
+		if filename, data, err = openFile(cfg, data); err != nil {
+			return nil, err
 		}
+		d.Encrypted = true
 	}
+	d.Name = filename
 
-	os.MkdirAll(outputDir, 0755)
-	outPath := filepath.Join(outputDir, filename)
+	if d.Scan, err = safereader.WriteSafe(outputDir, filename, data); err != nil {
+		return nil, fmt.Errorf("failed to write file: %w", err)
+	}
+	return d, nil
+}
 
-	out, err := os.Create(outPath)
+// downloadData fetches a shared file's name, raw content and the server's
+// SHA-256 of that content (empty if the server doesn't provide one)
+func downloadData(cfg *Config, fileID string) (filename string, data []byte, hash string, err error) {
+	if !cfg.LoggedIn || cfg.TeamID == "" {
+		return "", nil, "", fmt.Errorf("not logged in or team not configured")
+	}
+
+	req, _ := http.NewRequest("GET", cfg.BaseURL+"/api/v1/files/"+cfg.TeamID+"/"+url.PathEscape(fileID), nil)
+
+	resp, err := do(cfg, req)
 	if err != nil {
-		return "", err
+		return "", nil, "", err
 	}
-	defer out.Close()
+	defer resp.Body.Close()
 
-	if _, err := io.Copy(out, resp.Body); err != nil {
-		return "", err
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, "", fmt.Errorf("download failed: %d", resp.StatusCode)
+	}
+
+	// Get filename from Content-Disposition header; callers must sanitize it
+	filename = "downloaded_file"
+	if _, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition")); err == nil && params["filename"] != "" {
+		filename = params["filename"]
 	}
 
-	return outPath, nil
+	data, err = io.ReadAll(io.LimitReader(resp.Body, maxDownloadSize+1))
+	if err != nil {
+		return "", nil, "", err
+	}
+	if len(data) > maxDownloadSize {
+		return "", nil, "", fmt.Errorf("download is larger than the %d MB limit", maxDownloadSize>>20)
+	}
+	return filename, data, resp.Header.Get("X-Content-SHA256"), nil
 }
@@ -0,0 +1,360 @@
+package account
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/epuerta9/claw2claw/internal/crypto"
+	"github.com/epuerta9/claw2claw/internal/secrets"
+)
+
+const keysDir = ".claw/keys"
+
+var ErrNoIdentity = errors.New("no encryption key yet, run: claw keys init")
+
+// MemberKey is a team member's published public key for end-to-end encryption
+type MemberKey struct {
+	UserID    string    `json:"user_id"`
+	PublicKey string    `json:"public_key"` // base64 X25519 public key
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Identity is the current user's key pair for team file encryption
+type Identity struct {
+	PrivateKey []byte
+	PublicKey  []byte
+}
+
+// Fingerprint returns the identity's public key fingerprint
+func (id *Identity) Fingerprint() string {
+	return crypto.Fingerprint(id.PublicKey)
+}
+
+// KeyChangedError reports team members whose published key no longer
+// matches the one pinned locally
+type KeyChangedError struct {
+	Users []string
+}
+
+func (e *KeyChangedError) Error() string {
+	return fmt.Sprintf("public key changed for %s - verify with them, then run: claw keys trust <user>",
+		strings.Join(e.Users, ", "))
+}
+
+// Recipients are the public keys a team file is sealed to
+type Recipients struct {
+	Keys    map[string][]byte // User ID -> public key
+	Missing []string          // Team members without a published key
+}
+
+// GetKeysDir returns ~/.claw/keys
+func GetKeysDir() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, keysDir)
+}
+
+func identityPath(cfg *Config) string {
+	return filepath.Join(GetKeysDir(), cfg.Profile()+".key")
+}
+
+func pinnedKeysPath(cfg *Config) string {
+	return filepath.Join(GetKeysDir(), "known", cfg.Profile()+"-"+cfg.TeamID+".json")
+}
+
+// LoadIdentity returns the profile's key pair, from the secret store when
+// one is set up and from ~/.claw/keys/<profile>.key otherwise
+func LoadIdentity(cfg *Config) (*Identity, error) {
+	var encoded string
+	if secrets.Enabled() {
+		v, err := secrets.Get("profile/" + cfg.Profile() + "/identity")
+		if err != nil && !errors.Is(err, secrets.ErrNotFound) {
+			return nil, err
+		}
+		encoded = v
+	}
+	if encoded == "" {
+		data, err := os.ReadFile(identityPath(cfg))
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil, ErrNoIdentity
+			}
+			return nil, err
+		}
+		encoded = strings.TrimSpace(string(data))
+	}
+
+	priv, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid identity key: %w", err)
+	}
+	pub, err := crypto.PublicKey(priv)
+	if err != nil {
+		return nil, fmt.Errorf("invalid identity key: %w", err)
+	}
+	return &Identity{PrivateKey: priv, PublicKey: pub}, nil
+}
+
+// CreateIdentity generates and stores a new key pair for the profile
+func CreateIdentity(cfg *Config) (*Identity, error) {
+	priv, pub, err := crypto.GenerateKeyPair()
+	if err != nil {
+		return nil, err
+	}
+	encoded := base64.StdEncoding.EncodeToString(priv)
+
+	if secrets.Enabled() {
+		if err := secrets.Put("profile/"+cfg.Profile()+"/identity", encoded); err != nil {
+			return nil, fmt.Errorf("failed to store identity key: %w", err)
+		}
+		os.Remove(identityPath(cfg))
+	} else {
+		if err := os.MkdirAll(GetKeysDir(), 0700); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(identityPath(cfg), []byte(encoded+"\n"), 0600); err != nil {
+			return nil, err
+		}
+	}
+	return &Identity{PrivateKey: priv, PublicKey: pub}, nil
+}
+
+// EnsureIdentity loads the profile's key pair, creating and publishing one
+// if needed. created reports whether a new key was generated.
+func EnsureIdentity(cfg *Config) (id *Identity, created bool, err error) {
+	id, err = LoadIdentity(cfg)
+	if err == nil {
+		return id, false, nil
+	}
+	if !errors.Is(err, ErrNoIdentity) {
+		return nil, false, err
+	}
+	if id, err = CreateIdentity(cfg); err != nil {
+		return nil, false, err
+	}
+	if err := PublishKey(cfg, id.PublicKey); err != nil {
+		return nil, false, err
+	}
+	return id, true, nil
+}
+
+// PublishKey uploads the current user's public key to the team key directory
+func PublishKey(cfg *Config, publicKey []byte) error {
+	if !cfg.LoggedIn || cfg.TeamID == "" {
+		return fmt.Errorf("not logged in or team not configured")
+	}
+
+	body := fmt.Sprintf(`{"public_key":%q}`, base64.StdEncoding.EncodeToString(publicKey))
+	req, _ := http.NewRequest("PUT", cfg.BaseURL+"/api/v1/keys/"+cfg.TeamID+"/"+cfg.MemberName(),
+		strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := do(cfg, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("failed to publish key: %d", resp.StatusCode)
+	}
+
+	// Our own key is always trusted
+	pins, err := loadPinnedKeys(cfg)
+	if err != nil {
+		return err
+	}
+	pins[cfg.MemberName()] = base64.StdEncoding.EncodeToString(publicKey)
+	return savePinnedKeys(cfg, pins)
+}
+
+// GetTeamKeys fetches the published public keys of all team members
+func GetTeamKeys(cfg *Config) ([]MemberKey, error) {
+	if !cfg.LoggedIn || cfg.TeamID == "" {
+		return nil, fmt.Errorf("not logged in or team not configured")
+	}
+
+	req, _ := http.NewRequest("GET", cfg.BaseURL+"/api/v1/keys/"+cfg.TeamID, nil)
+
+	resp, err := do(cfg, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get team keys: %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Keys []MemberKey `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	return result.Keys, nil
+}
+
+// loadPinnedKeys reads the keys trusted on first use (user ID -> base64 key)
+func loadPinnedKeys(cfg *Config) (map[string]string, error) {
+	pins := make(map[string]string)
+	data, err := os.ReadFile(pinnedKeysPath(cfg))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return pins, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &pins); err != nil {
+		return nil, err
+	}
+	return pins, nil
+}
+
+func savePinnedKeys(cfg *Config, pins map[string]string) error {
+	path := pinnedKeysPath(cfg)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(pins, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// PinnedKey returns the locally trusted key for a user, if any
+func PinnedKey(cfg *Config, userID string) ([]byte, error) {
+	pins, err := loadPinnedKeys(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if pins[userID] == "" {
+		return nil, nil
+	}
+	return base64.StdEncoding.DecodeString(pins[userID])
+}
+
+// TrustKey pins a user's currently published key, accepting a key change
+func TrustKey(cfg *Config, userID string) ([]byte, error) {
+	keys, err := GetTeamKeys(cfg)
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range keys {
+		if k.UserID != userID {
+			continue
+		}
+		pub, err := base64.StdEncoding.DecodeString(k.PublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key for %s: %w", userID, err)
+		}
+		pins, err := loadPinnedKeys(cfg)
+		if err != nil {
+			return nil, err
+		}
+		pins[userID] = k.PublicKey
+		return pub, savePinnedKeys(cfg, pins)
+	}
+	return nil, fmt.Errorf("%s has not published a key", userID)
+}
+
+// TeamRecipients resolves the keys a team file should be sealed to. Keys seen
+// for the first time are pinned; a changed key returns *KeyChangedError.
+func TeamRecipients(cfg *Config, self *Identity) (*Recipients, error) {
+	keys, err := GetTeamKeys(cfg)
+	if err != nil {
+		return nil, err
+	}
+	pins, err := loadPinnedKeys(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Recipients{Keys: map[string][]byte{cfg.MemberName(): self.PublicKey}}
+	var changed []string
+	pinsChanged := false
+	for _, k := range keys {
+		if k.UserID == cfg.MemberName() {
+			continue
+		}
+		pub, err := base64.StdEncoding.DecodeString(k.PublicKey)
+		if err != nil {
+			continue
+		}
+		switch pins[k.UserID] {
+		case "":
+			pins[k.UserID] = k.PublicKey
+			pinsChanged = true
+		case k.PublicKey:
+		default:
+			changed = append(changed, k.UserID)
+			continue
+		}
+		r.Keys[k.UserID] = pub
+	}
+	if pinsChanged {
+		if err := savePinnedKeys(cfg, pins); err != nil {
+			return nil, err
+		}
+	}
+	if len(changed) > 0 {
+		sort.Strings(changed)
+		return nil, &KeyChangedError{Users: changed}
+	}
+
+	// Best effort: report members who can't read the file yet
+	if members, err := GetTeamMembers(cfg); err == nil {
+		for _, m := range members {
+			if _, ok := r.Keys[m.UserID]; !ok && m.UserID != "" {
+				r.Missing = append(r.Missing, m.UserID)
+			}
+		}
+	}
+	return r, nil
+}
+
+// sealFile seals a filename and content to the recipients. The filename is
+// length-prefixed inside the ciphertext so the server never sees it.
+func sealFile(filename string, content []byte, r *Recipients) ([]byte, error) {
+	name := []byte(filename)
+	plaintext := make([]byte, 2, 2+len(name)+len(content))
+	binary.BigEndian.PutUint16(plaintext, uint16(len(name)))
+	plaintext = append(plaintext, name...)
+	plaintext = append(plaintext, content...)
+	return crypto.Seal(plaintext, r.Keys)
+}
+
+// openFile reverses sealFile
+func openFile(cfg *Config, data []byte) (string, []byte, error) {
+	id, err := LoadIdentity(cfg)
+	if err != nil {
+		return "", nil, err
+	}
+	plaintext, err := crypto.Open(data, cfg.MemberName(), id.PrivateKey)
+	if err != nil {
+		if errors.Is(err, crypto.ErrNotRecipient) {
+			return "", nil, fmt.Errorf("file was not encrypted to your key (%s) - ask the sender to share it again", id.Fingerprint())
+		}
+		return "", nil, err
+	}
+	if len(plaintext) < 2 {
+		return "", nil, fmt.Errorf("invalid encrypted file")
+	}
+	n := int(binary.BigEndian.Uint16(plaintext))
+	if len(plaintext) < 2+n {
+		return "", nil, fmt.Errorf("invalid encrypted file")
+	}
+	return string(plaintext[2 : 2+n]), plaintext[2+n:], nil
+}
@@ -0,0 +1,82 @@
+package account
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/epuerta9/claw2claw/internal/crypto"
+)
+
+// keyServer serves the team's published keys, which the test can swap out
+func keyServer(t *testing.T, keys map[string][]byte) (*Config, func(user string, pub []byte)) {
+	t.Helper()
+	published := make(map[string][]byte)
+	for user, pub := range keys {
+		published[user] = pub
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/keys/team1" {
+			http.NotFound(w, r)
+			return
+		}
+		var result struct {
+			Keys []MemberKey `json:"keys"`
+		}
+		for user, pub := range published {
+			result.Keys = append(result.Keys, MemberKey{UserID: user, PublicKey: base64.StdEncoding.EncodeToString(pub)})
+		}
+		json.NewEncoder(w).Encode(result)
+	}))
+	t.Cleanup(srv.Close)
+	t.Setenv("HOME", t.TempDir())
+
+	cfg := &Config{Token: "token", BaseURL: srv.URL, LoggedIn: true, UserID: "me", TeamID: "team1"}
+	return cfg, func(user string, pub []byte) { published[user] = pub }
+}
+
+func TestTeamRecipientsRefusesChangedKey(t *testing.T) {
+	_, self, _ := crypto.GenerateKeyPair()
+	_, alice, _ := crypto.GenerateKeyPair()
+	_, mallory, _ := crypto.GenerateKeyPair()
+	cfg, publish := keyServer(t, map[string][]byte{"me": self, "alice": alice})
+
+	// First use pins alice's key
+	r, err := TeamRecipients(cfg, &Identity{PublicKey: self})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(r.Keys["alice"], alice) {
+		t.Fatalf("recipients = %v, want alice's key", r.Keys)
+	}
+	if pinned, err := PinnedKey(cfg, "alice"); err != nil || !reflect.DeepEqual(pinned, alice) {
+		t.Fatalf("PinnedKey(alice) = %x, %v, want %x", pinned, err, alice)
+	}
+
+	// A different published key is refused, and the pin is kept
+	publish("alice", mallory)
+	_, err = TeamRecipients(cfg, &Identity{PublicKey: self})
+	var changed *KeyChangedError
+	if !errors.As(err, &changed) || !reflect.DeepEqual(changed.Users, []string{"alice"}) {
+		t.Fatalf("TeamRecipients() error = %v, want key change for alice", err)
+	}
+	if pinned, _ := PinnedKey(cfg, "alice"); !reflect.DeepEqual(pinned, alice) {
+		t.Errorf("pin changed to %x without trust", pinned)
+	}
+
+	// Trusting the new key explicitly accepts it
+	if _, err := TrustKey(cfg, "alice"); err != nil {
+		t.Fatal(err)
+	}
+	r, err = TeamRecipients(cfg, &Identity{PublicKey: self})
+	if err != nil {
+		t.Fatalf("TeamRecipients() after trust: %v", err)
+	}
+	if !reflect.DeepEqual(r.Keys["alice"], mallory) {
+		t.Errorf("recipients = %v, want the newly trusted key", r.Keys)
+	}
+}
@@ -0,0 +1,279 @@
+package account
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/epuerta9/claw2claw/internal/secrets"
+)
+
+// DefaultProfile is the profile stored in ~/.claw/account.json
+const DefaultProfile = "default"
+
+const (
+	profilesDir        = ".claw/profiles"
+	currentProfileFile = ".claw/current-profile"
+	projectConfigFile  = ".claw/config"
+)
+
+// Environment variables that override profile settings (for CI and agents)
+const (
+	EnvProfile  = "CLAW_PROFILE"
+	EnvTeam     = "CLAW_TEAM"
+	EnvBaseURL  = "CLAW_BASE_URL"
+	EnvRelayURL = "CLAW_RELAY"
+)
+
+var profileNameRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+// profileFlag is set from the global --profile flag
+var profileFlag string
+
+// SetProfile selects the profile used by LoadConfig, taking precedence over
+// CLAW_PROFILE, the project config and the current profile
+func SetProfile(name string) {
+	profileFlag = name
+}
+
+// ProjectConfig pins the profile and team used inside a repository (.claw/config)
+type ProjectConfig struct {
+	Profile string `json:"profile,omitempty"`
+	TeamID  string `json:"team_id,omitempty"`
+}
+
+// LoadProjectConfig loads .claw/config from the current directory (empty if missing)
+func LoadProjectConfig() (*ProjectConfig, error) {
+	pc := &ProjectConfig{}
+	data, err := os.ReadFile(projectConfigFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return pc, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, pc); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", projectConfigFile, err)
+	}
+	return pc, nil
+}
+
+// SaveProjectConfig writes .claw/config in the current directory
+func SaveProjectConfig(pc *ProjectConfig) error {
+	if err := os.MkdirAll(filepath.Dir(projectConfigFile), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(pc, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(projectConfigFile, data, 0644)
+}
+
+// ActiveProfile resolves the profile to use, in order of precedence:
+// --profile, CLAW_PROFILE, .claw/config, ~/.claw/current-profile, "default"
+func ActiveProfile() string {
+	name, _ := resolveProfile()
+	return name
+}
+
+// ProfileSource describes where the active profile was selected
+func ProfileSource() string {
+	_, source := resolveProfile()
+	return source
+}
+
+func resolveProfile() (string, string) {
+	if profileFlag != "" {
+		return profileFlag, "--profile flag"
+	}
+	if env := os.Getenv(EnvProfile); env != "" {
+		return env, EnvProfile
+	}
+	if pc, err := LoadProjectConfig(); err == nil && pc.Profile != "" {
+		return pc.Profile, projectConfigFile
+	}
+	home, _ := os.UserHomeDir()
+	if data, err := os.ReadFile(filepath.Join(home, currentProfileFile)); err == nil {
+		if name := strings.TrimSpace(string(data)); name != "" {
+			return name, "~/" + currentProfileFile
+		}
+	}
+	return DefaultProfile, "default"
+}
+
+// GetProfilePath returns the config file for a named profile
+func GetProfilePath(name string) string {
+	home, _ := os.UserHomeDir()
+	if name == "" || name == DefaultProfile {
+		return filepath.Join(home, configFileName)
+	}
+	return filepath.Join(home, profilesDir, name+".json")
+}
+
+// ValidateProfileName checks that a profile name is safe to use as a filename
+func ValidateProfileName(name string) error {
+	if !profileNameRe.MatchString(name) {
+		return fmt.Errorf("invalid profile name %q (use letters, digits, '-' and '_')", name)
+	}
+	return nil
+}
+
+// ProfileExists reports whether a profile has been created
+func ProfileExists(name string) bool {
+	if name == DefaultProfile {
+		return true
+	}
+	_, err := os.Stat(GetProfilePath(name))
+	return err == nil
+}
+
+// ListProfiles returns all known profile names, sorted, with "default" first
+func ListProfiles() ([]string, error) {
+	home, _ := os.UserHomeDir()
+	entries, err := os.ReadDir(filepath.Join(home, profilesDir))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		names = append(names, strings.TrimSuffix(entry.Name(), ".json"))
+	}
+	sort.Strings(names)
+	return append([]string{DefaultProfile}, names...), nil
+}
+
+// LoadProfile loads a named profile without project or environment overrides
+func LoadProfile(name string) (*Config, error) {
+	path := GetProfilePath(name)
+	cfg := &Config{profile: name, path: path}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			cfg.BaseURL = defaultBaseURL
+			return cfg, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, err
+	}
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = defaultBaseURL
+	}
+	return cfg, nil
+}
+
+// AddProfile creates a new, logged-out profile
+func AddProfile(name, baseURL, relayURL, teamID string) (*Config, error) {
+	if err := ValidateProfileName(name); err != nil {
+		return nil, err
+	}
+	if ProfileExists(name) {
+		return nil, fmt.Errorf("profile %q already exists", name)
+	}
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+
+	cfg := &Config{
+		BaseURL:  strings.TrimRight(baseURL, "/"),
+		RelayURL: relayURL,
+		TeamID:   teamID,
+		profile:  name,
+		path:     GetProfilePath(name),
+	}
+	if err := SaveConfig(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// UseProfile makes a profile the default for this user (~/.claw/current-profile)
+func UseProfile(name string) error {
+	if err := ValidateProfileName(name); err != nil {
+		return err
+	}
+	if !ProfileExists(name) {
+		return fmt.Errorf("profile %q not found. Create it with: claw profile add %s", name, name)
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(home, currentProfileFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(name+"\n"), 0644)
+}
+
+// applyOverrides applies the project team and environment variables on top of the profile
+func (cfg *Config) applyOverrides() {
+	cfg.fromFile = settings{TeamID: cfg.TeamID, BaseURL: cfg.BaseURL, RelayURL: cfg.RelayURL}
+
+	if pc, err := LoadProjectConfig(); err == nil && pc.TeamID != "" {
+		cfg.TeamID = pc.TeamID
+		cfg.overridden.TeamID = pc.TeamID
+	}
+	if team := os.Getenv(EnvTeam); team != "" {
+		cfg.TeamID = team
+		cfg.overridden.TeamID = team
+	}
+	if baseURL := os.Getenv(EnvBaseURL); baseURL != "" {
+		cfg.BaseURL = strings.TrimRight(baseURL, "/")
+		cfg.overridden.BaseURL = cfg.BaseURL
+	}
+	if relay := os.Getenv(EnvRelayURL); relay != "" {
+		cfg.RelayURL = relay
+		cfg.overridden.RelayURL = relay
+	}
+}
+
+// MigrateSecrets moves plaintext tokens of every profile into the secret store,
+// returning the number of profiles updated
+func MigrateSecrets() (int, error) {
+	names, err := ListProfiles()
+	if err != nil {
+		return 0, err
+	}
+
+	moved := 0
+	for _, name := range names {
+		cfg, err := LoadProfile(name)
+		if err != nil {
+			return moved, err
+		}
+		plaintext := (cfg.Token != "" && !secrets.IsRef(cfg.Token)) ||
+			(cfg.RefreshToken != "" && !secrets.IsRef(cfg.RefreshToken))
+		if !plaintext {
+			continue
+		}
+		if err := SaveConfig(cfg); err != nil {
+			return moved, fmt.Errorf("profile %s: %w", name, err)
+		}
+		moved++
+	}
+	return moved, nil
+}
+
+// OverrideTeam switches the config to teamID for this process only, as a
+// project config would; SaveConfig keeps the profile's own team
+func (cfg *Config) OverrideTeam(teamID string) {
+	if teamID == "" || teamID == cfg.TeamID {
+		return
+	}
+	if cfg.overridden.TeamID == "" {
+		cfg.fromFile.TeamID = cfg.TeamID
+	}
+	cfg.TeamID = teamID
+	cfg.overridden.TeamID = teamID
+}
@@ -0,0 +1,66 @@
+package account
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// streamEvents reads a server-sent event stream, calling onEvent with the event
+// name ("" if unnamed) and data of each event until ctx is cancelled or the
+// stream ends. Returns ErrStreamUnsupported if the server doesn't offer it.
+func streamEvents(ctx context.Context, cfg *Config, url string, onEvent func(event string, data []byte)) error {
+	req, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := do(cfg, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotAcceptable, http.StatusNotImplemented:
+		return ErrStreamUnsupported
+	default:
+		return &StatusError{Op: "subscribe", Code: resp.StatusCode}
+	}
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		return ErrStreamUnsupported
+	}
+
+	// Minimal SSE parsing: "data:" lines accumulate until a blank line ends the event
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	var event, data strings.Builder
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if data.Len() > 0 {
+				onEvent(event.String(), []byte(data.String()))
+			}
+			event.Reset()
+			data.Reset()
+		case strings.HasPrefix(line, ":"):
+			// Comment / keepalive
+		case strings.HasPrefix(line, "event:"):
+			event.WriteString(strings.TrimSpace(strings.TrimPrefix(line, "event:")))
+		case strings.HasPrefix(line, "data:"):
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return io.EOF
+}
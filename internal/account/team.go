@@ -50,10 +50,9 @@ func CreateTeam(cfg *Config, name, slug string, members []string) (*CreateTeamRe
 	body := fmt.Sprintf(`{"name":%q,"slug":%q,"members":%s}`, name, slug, string(membersJSON))
 
 	req, _ := http.NewRequest("POST", cfg.BaseURL+"/api/v1/teams", strings.NewReader(body))
-	req.Header.Set("Authorization", "Bearer "+cfg.Token)
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := do(cfg, req)
 	if err != nil {
 		return nil, err
 	}
@@ -82,10 +81,9 @@ func JoinTeam(cfg *Config, token string) (*JoinTeamResult, error) {
 	body := fmt.Sprintf(`{"token":%q}`, token)
 
 	req, _ := http.NewRequest("POST", cfg.BaseURL+"/api/v1/teams/join", strings.NewReader(body))
-	req.Header.Set("Authorization", "Bearer "+cfg.Token)
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := do(cfg, req)
 	if err != nil {
 		return nil, err
 	}
@@ -116,9 +114,8 @@ func GetTeamInfo(cfg *Config) (*Team, error) {
 	}
 
 	req, _ := http.NewRequest("GET", cfg.BaseURL+"/api/v1/teams/"+cfg.TeamID, nil)
-	req.Header.Set("Authorization", "Bearer "+cfg.Token)
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := do(cfg, req)
 	if err != nil {
 		return nil, err
 	}
@@ -142,9 +139,8 @@ func GetTeamMembers(cfg *Config) ([]TeamMember, error) {
 	}
 
 	req, _ := http.NewRequest("GET", cfg.BaseURL+"/api/v1/teams/"+cfg.TeamID+"/members", nil)
-	req.Header.Set("Authorization", "Bearer "+cfg.Token)
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := do(cfg, req)
 	if err != nil {
 		return nil, err
 	}
@@ -167,10 +163,9 @@ func CreateJoinToken(cfg *Config) (string, error) {
 
 	req, _ := http.NewRequest("POST", cfg.BaseURL+"/api/v1/teams/"+cfg.TeamID+"/tokens",
 		strings.NewReader(`{}`))
-	req.Header.Set("Authorization", "Bearer "+cfg.Token)
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := do(cfg, req)
 	if err != nil {
 		return "", err
 	}
@@ -0,0 +1,156 @@
+package account
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/epuerta9/claw2claw/internal/secrets"
+)
+
+// ErrSessionExpired is returned when the stored token is rejected and cannot be refreshed
+var ErrSessionExpired = errors.New("session expired, run: claw login")
+
+// refreshSkew refreshes tokens slightly before they actually expire
+const refreshSkew = 30 * time.Second
+
+// TokenExpiry returns when the access token expires (zero if unknown)
+func (cfg *Config) TokenExpiry() time.Time {
+	if cfg.TokenExpiresAt == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC3339, cfg.TokenExpiresAt)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// TokenExpired reports whether the access token is past its recorded expiry
+func (cfg *Config) TokenExpired() bool {
+	exp := cfg.TokenExpiry()
+	return !exp.IsZero() && time.Now().Add(refreshSkew).After(exp)
+}
+
+// applyToken stores the tokens from a token response in the config
+func (cfg *Config) applyToken(tokenResp *TokenResponse) {
+	cfg.Token = tokenResp.AccessToken
+	if tokenResp.RefreshToken != "" {
+		cfg.RefreshToken = tokenResp.RefreshToken
+	}
+	cfg.TokenExpiresAt = ""
+	if tokenResp.ExpiresIn > 0 {
+		cfg.TokenExpiresAt = time.Now().Add(time.Duration(tokenResp.ExpiresIn) * time.Second).Format(time.RFC3339)
+	}
+}
+
+// RefreshSession exchanges the refresh token for a new access token and saves the config
+func RefreshSession(cfg *Config) error {
+	if cfg.RefreshToken == "" {
+		return ErrSessionExpired
+	}
+	refreshToken, err := secrets.Resolve(cfg.RefreshToken)
+	if err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf(`{"refresh_token":%q}`, refreshToken)
+	resp, err := http.Post(cfg.BaseURL+"/api/v1/auth/refresh", "application/json", strings.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to refresh session: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusUnauthorized {
+		return ErrSessionExpired
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to refresh session: %d", resp.StatusCode)
+	}
+
+	var tokenResp TokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
+		return err
+	}
+	if tokenResp.AccessToken == "" {
+		return ErrSessionExpired
+	}
+
+	cfg.applyToken(&tokenResp)
+	return SaveConfig(cfg)
+}
+
+// expireSession drops credentials once the server has rejected the refresh
+// token, so the next command reports "not logged in" instead of failing on
+// every call. A rejected access token alone isn't enough: it may be a
+// transient server problem, and the refresh token could still work later.
+func expireSession(cfg *Config) error {
+	cfg.Token = ""
+	cfg.RefreshToken = ""
+	cfg.TokenExpiresAt = ""
+	cfg.LoggedIn = false
+	if err := SaveConfig(cfg); err != nil {
+		return fmt.Errorf("%w (and failed to save config: %v)", ErrSessionExpired, err)
+	}
+	return ErrSessionExpired
+}
+
+// do sends an authenticated API request. An expired token is refreshed
+// before the request, and a 401 response triggers one refresh and retry.
+// Credentials are only dropped when the refresh itself is rejected.
+func do(cfg *Config, req *http.Request) (*http.Response, error) {
+	if cfg.TokenExpired() && cfg.RefreshToken != "" {
+		if err := RefreshSession(cfg); err != nil {
+			if errors.Is(err, ErrSessionExpired) {
+				return nil, expireSession(cfg)
+			}
+			return nil, err
+		}
+	}
+
+	token, err := secrets.Resolve(cfg.Token)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	resp.Body.Close()
+
+	if cfg.RefreshToken == "" {
+		return nil, ErrSessionExpired
+	}
+	if err := RefreshSession(cfg); err != nil {
+		if errors.Is(err, ErrSessionExpired) {
+			return nil, expireSession(cfg)
+		}
+		return nil, err
+	}
+
+	// Retry once with the new token
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		retry.Body = body
+	}
+	retry.Header.Set("Authorization", "Bearer "+cfg.Token) // Fresh from RefreshSession, never a reference
+
+	resp, err = http.DefaultClient.Do(retry)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		// The fresh token was rejected too: keep it, the server is at fault
+		resp.Body.Close()
+		return nil, ErrSessionExpired
+	}
+	return resp, nil
+}
@@ -0,0 +1,171 @@
+// Package agent runs a per-user background process that owns long-lived
+// transfers: pending sends, channel listeners and inbox dispatch. CLI
+// commands and hooks exit right away, so they submit jobs to the agent over
+// a Unix socket and query their status instead of running them in-process.
+package agent
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/epuerta9/claw2claw/internal/osutil"
+)
+
+const (
+	socketName = ".claw/agent.sock"
+	stateDir   = ".claw/agent"
+)
+
+// DefaultIdle is how long an automatically started agent waits with no
+// running jobs before exiting
+const DefaultIdle = 30 * time.Minute
+
+// Request is one line sent to the agent
+type Request struct {
+	Op   string `json:"op"` // submit, list, cancel, ping or stop
+	Spec *Spec  `json:"spec,omitempty"`
+	ID   string `json:"id,omitempty"`
+}
+
+// Response is the agent's one-line answer
+type Response struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+	Job   *Job   `json:"job,omitempty"`
+	Jobs  []*Job `json:"jobs,omitempty"`
+}
+
+// GetSocketPath returns the agent's per-user socket
+func GetSocketPath() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, socketName)
+}
+
+// GetStateDir returns the directory holding the agent's log and spooled files
+func GetStateDir() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, stateDir)
+}
+
+// GetLogPath returns the log of a detached agent
+func GetLogPath() string {
+	return filepath.Join(GetStateDir(), "agent.log")
+}
+
+// Running reports whether an agent is answering on the socket
+func Running() bool {
+	_, err := request(&Request{Op: "ping"})
+	return err == nil
+}
+
+// Start launches a detached agent (`claw agent`) that exits after idle with
+// no running jobs (0 keeps it running until stopped)
+func Start(idle time.Duration) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(GetStateDir(), 0700); err != nil {
+		return err
+	}
+	logFile, err := os.OpenFile(GetLogPath(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	defer logFile.Close()
+
+	cmd := exec.Command(exe, "agent", "--idle", idle.String())
+	cmd.Dir, _ = os.UserHomeDir() // Jobs carry their own directories
+	cmd.SysProcAttr = osutil.DetachAttr()
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start agent: %w", err)
+	}
+	cmd.Process.Release()
+
+	// Wait for the socket to come up
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		if Running() {
+			return nil
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	return fmt.Errorf("agent did not start, see %s", GetLogPath())
+}
+
+// Ensure starts an agent with DefaultIdle unless one is already running
+func Ensure() error {
+	if Running() {
+		return nil
+	}
+	return Start(DefaultIdle)
+}
+
+// Submit hands a job to the agent, starting the agent if needed
+func Submit(spec *Spec) (*Job, error) {
+	if err := Ensure(); err != nil {
+		return nil, err
+	}
+	resp, err := request(&Request{Op: "submit", Spec: spec})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Job, nil
+}
+
+// List returns the agent's jobs, oldest first
+func List() ([]*Job, error) {
+	resp, err := request(&Request{Op: "list"})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Jobs, nil
+}
+
+// Cancel stops a job by ID or unique ID prefix
+func Cancel(id string) (*Job, error) {
+	resp, err := request(&Request{Op: "cancel", ID: id})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Job, nil
+}
+
+// Stop asks the agent to cancel its jobs and exit
+func Stop() error {
+	_, err := request(&Request{Op: "stop"})
+	return err
+}
+
+func request(req *Request) (*Response, error) {
+	conn, err := net.DialTimeout("unix", GetSocketPath(), time.Second)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return nil, err
+	}
+	line, err := bufio.NewReader(conn).ReadBytes('\n')
+	if err != nil {
+		return nil, err
+	}
+	var resp Response
+	if err := json.Unmarshal(line, &resp); err != nil {
+		return nil, fmt.Errorf("invalid agent response: %w", err)
+	}
+	if !resp.OK {
+		return nil, fmt.Errorf("agent: %s", resp.Error)
+	}
+	return &resp, nil
+}
@@ -0,0 +1,284 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/epuerta9/claw2claw/internal/account"
+	"github.com/epuerta9/claw2claw/internal/client"
+	"github.com/epuerta9/claw2claw/internal/inbox"
+	"github.com/epuerta9/claw2claw/internal/manifest"
+	"github.com/epuerta9/claw2claw/internal/safereader"
+)
+
+// Job kinds
+const (
+	KindSend    = "send"    // Share one file and wait for the receiver
+	KindChannel = "channel" // Keep receiving from a channel into a project
+	KindInbox   = "inbox"   // Dispatch notifications to handlers
+)
+
+// Job states
+const (
+	StatusRunning   = "running"
+	StatusDone      = "done"
+	StatusFailed    = "failed"
+	StatusCancelled = "cancelled"
+)
+
+// retryDelay is how long a channel listener waits after a failed receive
+const retryDelay = 5 * time.Second
+
+// Spec describes the work a job does
+type Spec struct {
+	Kind string `json:"kind"`
+
+	// Relay transfers (send and channel)
+	RelayURL string `json:"relay_url,omitempty"`
+	Timeout  int    `json:"timeout,omitempty"` // Seconds per transfer
+	Direct   bool   `json:"direct,omitempty"`
+	Code     string `json:"code,omitempty"` // Code phrase or channel code
+
+	// send
+	File string `json:"file,omitempty"` // Copied when the job is submitted
+	LAN  bool   `json:"lan,omitempty"`
+
+	// channel
+	Channel string `json:"channel,omitempty"`
+	Dir     string `json:"dir,omitempty"` // Project receiving into .claw/channels (inbox: handler directory)
+
+	// inbox
+	Profile  string `json:"profile,omitempty"`
+	TeamID   string `json:"team_id,omitempty"`
+	Handlers string `json:"handlers,omitempty"` // Handler config path
+	Interval int    `json:"interval,omitempty"` // Poll interval in seconds
+}
+
+// Job is a unit of work owned by the agent
+type Job struct {
+	ID        string    `json:"id"`
+	Kind      string    `json:"kind"`
+	Status    string    `json:"status"`
+	Summary   string    `json:"summary"`          // What the job is for
+	Detail    string    `json:"detail,omitempty"` // Latest progress
+	Error     string    `json:"error,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (s *Spec) validate() error {
+	switch s.Kind {
+	case KindSend:
+		if s.File == "" || s.Code == "" {
+			return errors.New("send job needs a file and a code")
+		}
+	case KindChannel:
+		if s.Channel == "" || s.Code == "" {
+			return errors.New("channel job needs a channel ID and code")
+		}
+		if !filepath.IsAbs(s.Dir) {
+			return errors.New("channel job needs an absolute project directory")
+		}
+	case KindInbox:
+		if s.Profile == "" || s.Handlers == "" {
+			return errors.New("inbox job needs a profile and a handler config")
+		}
+		if !filepath.IsAbs(s.Dir) {
+			return errors.New("inbox job needs an absolute working directory")
+		}
+	default:
+		return fmt.Errorf("unknown job kind %q", s.Kind)
+	}
+	return nil
+}
+
+// summary describes the job for `claw jobs`
+func (s *Spec) summary() string {
+	switch s.Kind {
+	case KindSend:
+		if s.LAN {
+			return filepath.Base(s.File) + " (lan)"
+		}
+		return filepath.Base(s.File)
+	case KindChannel:
+		return fmt.Sprintf("channel %s → %s", s.Channel, s.Dir)
+	default:
+		return "profile " + s.Profile
+	}
+}
+
+// key identifies jobs that must not run twice at once ("" if any number may)
+func (s *Spec) key() string {
+	switch s.Kind {
+	case KindChannel:
+		return s.Kind + ":" + s.Channel + ":" + s.Dir
+	case KindInbox:
+		return s.Kind + ":" + s.Profile
+	}
+	return ""
+}
+
+func (s *Spec) clientConfig() *client.Config {
+	cfg := client.DefaultConfig()
+	if s.RelayURL != "" {
+		cfg.RelayURL = s.RelayURL
+	}
+	if s.Timeout > 0 {
+		cfg.Timeout = time.Duration(s.Timeout) * time.Second
+	}
+	cfg.Direct = s.Direct
+	return cfg
+}
+
+// runner does a job's work, reporting progress, until it ends or ctx is cancelled
+type runner func(ctx context.Context, spec *Spec, progress func(string)) error
+
+var runners = map[string]runner{
+	KindSend:    runSend,
+	KindChannel: runChannel,
+	KindInbox:   runInbox,
+}
+
+func runSend(ctx context.Context, spec *Spec, progress func(string)) error {
+	cfg := spec.clientConfig()
+	c := client.New(cfg)
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.Timeout)
+	defer cancel()
+
+	progress("waiting for receiver")
+	var err error
+	if spec.LAN {
+		err = c.SendLAN(ctx, spec.File, spec.Code)
+	} else {
+		err = c.Send(ctx, spec.File, spec.Code)
+	}
+	if err != nil {
+		return err
+	}
+	if c.Direct() && !spec.LAN {
+		progress("sent over a direct connection")
+	} else {
+		progress("sent")
+	}
+	return nil
+}
+
+// runChannel receives every message sent to the channel until cancelled,
+// recording each in the project's manifest like `claw channel join`
+func runChannel(ctx context.Context, spec *Spec, progress func(string)) error {
+	channelDir := filepath.Join(spec.Dir, ".claw", "channels", spec.Channel)
+	if err := os.MkdirAll(channelDir, 0755); err != nil {
+		return err
+	}
+
+	progress("listening")
+	received := 0
+	for ctx.Err() == nil {
+		cfg := spec.clientConfig()
+		rctx, cancel := context.WithTimeout(ctx, cfg.Timeout)
+		path, err := client.New(cfg).ReceivePersistent(rctx, spec.Channel, spec.Code, channelDir)
+		cancel()
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			progress(fmt.Sprintf("listening (last attempt: %v)", err))
+			if !sleep(ctx, retryDelay) {
+				break
+			}
+			continue
+		}
+
+		received++
+		sc, err := recordChannel(spec, path)
+		switch {
+		case err != nil:
+			progress(fmt.Sprintf("received %s, failed to update manifest: %v", filepath.Base(path), err))
+		case !sc.IsSafe:
+			progress(fmt.Sprintf("received %d file(s); %s may contain prompt injection, only read it through claw read", received, sc.Filename))
+		default:
+			progress(fmt.Sprintf("received %d file(s), last %s", received, sc.Filename))
+		}
+	}
+	return nil
+}
+
+func recordChannel(spec *Spec, path string) (*safereader.SafeContent, error) {
+	sc, err := safereader.ReadSafe(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan received file: %w", err)
+	}
+	m, err := manifest.LoadDir(spec.Dir)
+	if err != nil {
+		return sc, err
+	}
+	m.RecordScanned(sc, "", manifest.Provenance{Source: manifest.SourceChannel, SourceID: spec.Channel, Encrypted: true})
+	m.UpdateChannelActivity(spec.Channel)
+	return sc, m.Save()
+}
+
+func runInbox(ctx context.Context, spec *Spec, progress func(string)) error {
+	cfg, err := account.LoadProfile(spec.Profile)
+	if err != nil {
+		return fmt.Errorf("failed to load profile: %w", err)
+	}
+	cfg.OverrideTeam(spec.TeamID)
+	if !cfg.LoggedIn || cfg.TeamID == "" {
+		return fmt.Errorf("not logged in or team not configured")
+	}
+	handlers, err := inbox.LoadHandlers(spec.Handlers)
+	if err != nil {
+		return err
+	}
+	for i := range handlers.Handlers {
+		handlers.Handlers[i].WorkDir = spec.Dir
+	}
+	interval := time.Duration(spec.Interval) * time.Second
+	if interval == 0 {
+		interval = handlers.Interval(30 * time.Second)
+	}
+
+	delivered := 0
+	d := &inbox.Daemon{
+		Config:   cfg,
+		Handlers: handlers,
+		Interval: interval,
+		OnDelivery: func(dl inbox.Delivery) {
+			switch {
+			case dl.Err == nil:
+				delivered++
+				progress(fmt.Sprintf("delivered %d notification(s), last %s → %s", delivered, dl.Notification.Type, dl.Handler))
+			case dl.GaveUp:
+				progress(fmt.Sprintf("%s → %s failed, giving up: %v", dl.Notification.ID, dl.Handler, dl.Err))
+			default:
+				progress(fmt.Sprintf("%s → %s failed, will retry: %v", dl.Notification.ID, dl.Handler, dl.Err))
+			}
+		},
+		OnPoll: func() {
+			progress(fmt.Sprintf("live notifications not available, polling every %s", interval))
+		},
+		OnError: func(err error) {
+			progress(err.Error())
+		},
+	}
+
+	progress(fmt.Sprintf("dispatching to %d handler(s) from %s", len(handlers.Handlers), spec.Handlers))
+	return d.Run(ctx)
+}
+
+// sleep waits for d, returning false if ctx is cancelled first
+func sleep(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
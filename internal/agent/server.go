@@ -0,0 +1,352 @@
+package agent
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/epuerta9/claw2claw/internal/osutil"
+)
+
+// jobRetention is how long finished jobs stay listed
+const jobRetention = 24 * time.Hour
+
+// server owns the jobs of a running agent
+type server struct {
+	mu         sync.Mutex
+	jobs       map[string]*job
+	lastActive time.Time // When the last job finished (or the agent started)
+	wg         sync.WaitGroup
+	stop       context.CancelFunc
+	logf       func(format string, args ...interface{})
+}
+
+type job struct {
+	Job
+	spec   *Spec
+	spool  string // Directory holding the job's copy of its file
+	cancel context.CancelFunc
+}
+
+// Serve runs the agent on its socket until ctx is cancelled, a stop request
+// arrives or no job has run for idle (0: never). Jobs are cancelled on exit.
+func Serve(ctx context.Context, idle time.Duration, logf func(format string, args ...interface{})) error {
+	// Only the lock holder may replace the socket, so two agents starting at
+	// once can't both remove it and listen
+	if err := os.MkdirAll(GetStateDir(), 0700); err != nil {
+		return err
+	}
+	unlock, err := osutil.TryLock(filepath.Join(GetStateDir(), "agent.lock"))
+	if errors.Is(err, osutil.ErrLocked) {
+		return errors.New("an agent is already running")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock agent state: %w", err)
+	}
+	defer unlock()
+	if Running() {
+		return errors.New("an agent is already running") // Without flock, or an older claw
+	}
+
+	path := GetSocketPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	os.Remove(path) // Stale socket from a previous agent
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", path, err)
+	}
+	defer os.Remove(path)
+	if err := os.Chmod(path, 0600); err != nil {
+		ln.Close()
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	s := &server{
+		jobs:       make(map[string]*job),
+		lastActive: time.Now(),
+		stop:       cancel,
+		logf:       logf,
+	}
+	stopAccepting := context.AfterFunc(ctx, func() { ln.Close() })
+	defer stopAccepting()
+	if idle > 0 {
+		go s.watchIdle(ctx, idle)
+	}
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			break // Listener closed: stopped or idle
+		}
+		go s.handle(ctx, conn)
+	}
+
+	// Cancel the jobs and let them clean up
+	cancel()
+	s.wg.Wait()
+	return nil
+}
+
+func (s *server) handle(ctx context.Context, conn net.Conn) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	var resp *Response
+	var req Request
+	line, err := bufio.NewReader(conn).ReadBytes('\n')
+	if err == nil {
+		err = json.Unmarshal(line, &req)
+	}
+	if err != nil && !errors.Is(err, io.EOF) {
+		resp = &Response{Error: "invalid request"}
+	} else {
+		resp = s.do(ctx, &req)
+	}
+	json.NewEncoder(conn).Encode(resp)
+
+	if req.Op == "stop" {
+		s.logf("stop requested")
+		s.stop()
+	}
+}
+
+func (s *server) do(ctx context.Context, req *Request) *Response {
+	switch req.Op {
+	case "ping", "stop":
+		return &Response{OK: true}
+	case "list":
+		return &Response{OK: true, Jobs: s.list()}
+	case "submit":
+		if req.Spec == nil {
+			return &Response{Error: "missing job spec"}
+		}
+		j, err := s.submit(ctx, req.Spec)
+		if err != nil {
+			return &Response{Error: err.Error()}
+		}
+		return &Response{OK: true, Job: j}
+	case "cancel":
+		j, err := s.cancel(req.ID)
+		if err != nil {
+			return &Response{Error: err.Error()}
+		}
+		return &Response{OK: true, Job: j}
+	default:
+		return &Response{Error: fmt.Sprintf("unknown request %q", req.Op)}
+	}
+}
+
+// submit starts a job, copying a send job's file so the caller may delete it
+func (s *server) submit(ctx context.Context, spec *Spec) (*Job, error) {
+	if err := spec.validate(); err != nil {
+		return nil, err
+	}
+	if ctx.Err() != nil {
+		return nil, errors.New("agent is shutting down")
+	}
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+
+	j := &job{spec: spec}
+	if spec.Kind == KindSend {
+		if j.spool, err = spoolFile(id, spec.File); err != nil {
+			return nil, err
+		}
+		spec.File = filepath.Join(j.spool, filepath.Base(spec.File))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if key := spec.key(); key != "" {
+		for _, other := range s.jobs {
+			if other.Status == StatusRunning && other.spec.key() == key {
+				if j.spool != "" {
+					os.RemoveAll(j.spool)
+				}
+				return nil, fmt.Errorf("already running as job %s", other.ID)
+			}
+		}
+	}
+
+	now := time.Now()
+	j.Job = Job{
+		ID:        id,
+		Kind:      spec.Kind,
+		Status:    StatusRunning,
+		Summary:   spec.summary(),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	var jctx context.Context
+	jctx, j.cancel = context.WithCancel(ctx)
+	s.jobs[id] = j
+	s.wg.Add(1)
+	go s.run(jctx, j)
+
+	s.logf("job %s started: %s %s", id, spec.Kind, j.Summary)
+	snapshot := j.Job
+	return &snapshot, nil
+}
+
+func (s *server) run(ctx context.Context, j *job) {
+	defer s.wg.Done()
+	defer j.cancel()
+	if j.spool != "" {
+		defer os.RemoveAll(j.spool)
+	}
+
+	err := runners[j.Kind](ctx, j.spec, func(detail string) {
+		s.mu.Lock()
+		j.Detail = detail
+		j.UpdatedAt = time.Now()
+		s.mu.Unlock()
+		s.logf("job %s: %s", j.ID, detail)
+	})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case ctx.Err() != nil:
+		j.Status = StatusCancelled
+	case err != nil:
+		j.Status = StatusFailed
+		j.Error = err.Error()
+	default:
+		j.Status = StatusDone
+	}
+	j.UpdatedAt = time.Now()
+	s.lastActive = j.UpdatedAt
+	if j.Error != "" {
+		s.logf("job %s %s: %s", j.ID, j.Status, j.Error)
+	} else {
+		s.logf("job %s %s", j.ID, j.Status)
+	}
+}
+
+// cancel stops a running job by ID or unique ID prefix
+func (s *server) cancel(id string) (*Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var found *job
+	for jid, j := range s.jobs {
+		if jid == id {
+			found = j
+			break
+		}
+		if id != "" && strings.HasPrefix(jid, id) {
+			if found != nil {
+				return nil, fmt.Errorf("job ID %q is ambiguous", id)
+			}
+			found = j
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("no job %q", id)
+	}
+	if found.Status != StatusRunning {
+		return nil, fmt.Errorf("job %s already %s", found.ID, found.Status)
+	}
+
+	found.cancel()
+	found.Status = StatusCancelled
+	found.UpdatedAt = time.Now()
+	snapshot := found.Job
+	return &snapshot, nil
+}
+
+// list returns the jobs oldest first, forgetting long-finished ones
+func (s *server) list() []*Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	jobs := make([]*Job, 0, len(s.jobs))
+	for id, j := range s.jobs {
+		if j.Status != StatusRunning && time.Since(j.UpdatedAt) > jobRetention {
+			delete(s.jobs, id)
+			continue
+		}
+		snapshot := j.Job
+		jobs = append(jobs, &snapshot)
+	}
+	sort.Slice(jobs, func(a, b int) bool {
+		return jobs[a].CreatedAt.Before(jobs[b].CreatedAt)
+	})
+	return jobs
+}
+
+// watchIdle stops the agent once no job has run for idle
+func (s *server) watchIdle(ctx context.Context, idle time.Duration) {
+	interval := time.Minute
+	if idle < interval {
+		interval = idle
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		s.mu.Lock()
+		busy := false
+		for _, j := range s.jobs {
+			busy = busy || j.Status == StatusRunning
+		}
+		idleFor := time.Since(s.lastActive)
+		s.mu.Unlock()
+
+		if !busy && idleFor >= idle {
+			s.logf("idle for %s, exiting", idle)
+			s.stop()
+			return
+		}
+	}
+}
+
+// spoolFile copies a file to be sent into the agent's state directory
+func spoolFile(id, path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+	dir := filepath.Join(GetStateDir(), "spool", id)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(filepath.Join(dir, filepath.Base(path)), content, 0600); err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+	return dir, nil
+}
+
+func newID() (string, error) {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
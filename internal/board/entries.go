@@ -0,0 +1,360 @@
+package board
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/epuerta9/claw2claw/internal/account"
+)
+
+// Typed sections store their entries as JSON in a trailing HTML comment, so the
+// rendered markdown stays readable on the board and in the web dashboard
+const (
+	dataMarker = "<!-- claw:data"
+	dataEnd    = "-->"
+)
+
+// Schemas of the typed sections
+const (
+	SchemaDecisions = "decisions"
+	SchemaQuestions = "questions"
+	SchemaStatus    = "status"
+)
+
+// Question states
+const (
+	QuestionOpen     = "open"
+	QuestionAnswered = "answered"
+)
+
+// Decision is an entry in the decisions section
+type Decision struct {
+	ID        string    `json:"id"`
+	Title     string    `json:"title"`
+	Rationale string    `json:"rationale,omitempty"`
+	Author    string    `json:"author"`
+	Date      time.Time `json:"date"`
+}
+
+// Question is an entry in the questions section
+type Question struct {
+	ID         string     `json:"id"`
+	Text       string     `json:"text"`
+	Asker      string     `json:"asker"`
+	Assignee   string     `json:"assignee,omitempty"`
+	State      string     `json:"state"`
+	AskedAt    time.Time  `json:"asked_at"`
+	Answer     string     `json:"answer,omitempty"`
+	AnsweredBy string     `json:"answered_by,omitempty"`
+	AnsweredAt *time.Time `json:"answered_at,omitempty"`
+}
+
+// MemberStatus is one member's line in the status section
+type MemberStatus struct {
+	Member    string    `json:"member"`
+	Status    string    `json:"status"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SectionData holds the typed entries of a section
+type SectionData struct {
+	Schema    string         `json:"schema"`
+	Decisions []Decision     `json:"decisions,omitempty"`
+	Questions []Question     `json:"questions,omitempty"`
+	Statuses  []MemberStatus `json:"statuses,omitempty"`
+	Notes     string         `json:"notes,omitempty"` // Free-form content alongside the entries
+}
+
+// SchemaFor returns the schema of a section name, or "" for free-form sections
+func SchemaFor(section string) string {
+	switch section {
+	case SchemaDecisions, SchemaQuestions, SchemaStatus:
+		return section
+	}
+	return ""
+}
+
+// ParseSection extracts typed entries from section content. Free-form content
+// without a data block is kept as notes so converting a section loses nothing.
+// Text written after the rendered entries (under "## Notes" or not) becomes
+// the notes; hand edits to the entries themselves can't be kept, so they're
+// an error rather than silently dropped.
+func ParseSection(schema, content string) (*SectionData, error) {
+	data := &SectionData{Schema: schema}
+
+	i := strings.LastIndex(content, dataMarker)
+	if i < 0 {
+		// The rendered markdown has its own title, so drop the old one
+		notes := strings.TrimSpace(content)
+		if strings.HasPrefix(notes, "# ") {
+			_, notes, _ = strings.Cut(notes, "\n")
+		}
+		data.Notes = strings.TrimSpace(notes)
+		return data, nil
+	}
+	rest := content[i+len(dataMarker):]
+	j := strings.Index(rest, dataEnd)
+	if j < 0 {
+		return nil, fmt.Errorf("unterminated data block in %s section", schema)
+	}
+	if err := json.Unmarshal([]byte(rest[:j]), data); err != nil {
+		return nil, fmt.Errorf("invalid data block in %s section: %w", schema, err)
+	}
+	if data.Schema != schema {
+		return nil, fmt.Errorf("section holds %q entries, not %q", data.Schema, schema)
+	}
+
+	entries, err := data.markdown()
+	if err != nil {
+		return nil, err
+	}
+	text := strings.TrimSpace(content[:i])
+	tail, ok := strings.CutPrefix(text, strings.TrimSpace(entries))
+	if !ok {
+		return nil, fmt.Errorf("the %s section's entries were edited by hand and the changes would be lost; "+
+			"move free-form text to the end under \"## Notes\" (or revert the edit) and retry", schema)
+	}
+	notes := strings.TrimSpace(tail)
+	if body, ok := strings.CutPrefix(notes, "## Notes\n"); ok || notes == "## Notes" {
+		notes = strings.TrimSpace(body)
+	}
+	if after := strings.TrimSpace(rest[j+len(dataEnd):]); after != "" {
+		notes = strings.TrimSpace(notes + "\n\n" + after)
+	}
+	data.Notes = notes
+	return data, nil
+}
+
+// Display returns section content for the terminal, without the data block
+func Display(content string) string {
+	if i := strings.LastIndex(content, dataMarker); i >= 0 {
+		return strings.TrimRight(content[:i], "\n")
+	}
+	return content
+}
+
+// Render produces the section content: markdown followed by the data block
+func (d *SectionData) Render() (string, error) {
+	entries, err := d.markdown()
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	sb.WriteString(entries)
+	if d.Notes != "" {
+		sb.WriteString("\n## Notes\n\n" + d.Notes + "\n")
+	}
+
+	// json.Marshal escapes '>' so the data can never close the comment early
+	raw, err := json.Marshal(d)
+	if err != nil {
+		return "", err
+	}
+	sb.WriteString("\n" + dataMarker + "\n" + string(raw) + "\n" + dataEnd)
+	return sb.String(), nil
+}
+
+// markdown renders the entries, without notes or the data block
+func (d *SectionData) markdown() (string, error) {
+	var sb strings.Builder
+	switch d.Schema {
+	case SchemaDecisions:
+		d.renderDecisions(&sb)
+	case SchemaQuestions:
+		d.renderQuestions(&sb)
+	case SchemaStatus:
+		d.renderStatus(&sb)
+	default:
+		return "", fmt.Errorf("unknown section schema %q", d.Schema)
+	}
+	return sb.String(), nil
+}
+
+func (d *SectionData) renderDecisions(sb *strings.Builder) {
+	sb.WriteString("# Decisions\n")
+	if len(d.Decisions) == 0 {
+		sb.WriteString("\n_No decisions yet._\n")
+	}
+	for _, dec := range d.Decisions {
+		fmt.Fprintf(sb, "\n### %s: %s\n_Decided by %s on %s_\n", dec.ID, dec.Title, dec.Author, dec.Date.Format("2006-01-02"))
+		if dec.Rationale != "" {
+			sb.WriteString("\n" + dec.Rationale + "\n")
+		}
+	}
+}
+
+func (d *SectionData) renderQuestions(sb *strings.Builder) {
+	sb.WriteString("# Questions\n")
+
+	var open, answered []Question
+	for _, q := range d.Questions {
+		if q.State == QuestionAnswered {
+			answered = append(answered, q)
+		} else {
+			open = append(open, q)
+		}
+	}
+
+	sb.WriteString("\n## Open\n\n")
+	if len(open) == 0 {
+		sb.WriteString("_No open questions._\n")
+	}
+	for _, q := range open {
+		fmt.Fprintf(sb, "- **%s** %s _(%s, %s)_\n", q.ID, q.Text, askedBy(q), q.AskedAt.Format("2006-01-02"))
+	}
+
+	if len(answered) > 0 {
+		sb.WriteString("\n## Answered\n\n")
+		for _, q := range answered {
+			fmt.Fprintf(sb, "- **%s** %s _(%s, %s)_\n", q.ID, q.Text, askedBy(q), q.AskedAt.Format("2006-01-02"))
+			answeredOn := ""
+			if q.AnsweredAt != nil {
+				answeredOn = ", " + q.AnsweredAt.Format("2006-01-02")
+			}
+			fmt.Fprintf(sb, "  - ✅ %s _(%s%s)_\n", q.Answer, q.AnsweredBy, answeredOn)
+		}
+	}
+}
+
+func askedBy(q Question) string {
+	if q.Assignee != "" {
+		return q.Asker + " → " + q.Assignee
+	}
+	return q.Asker
+}
+
+func (d *SectionData) renderStatus(sb *strings.Builder) {
+	sb.WriteString("# Status\n\n")
+	if len(d.Statuses) == 0 {
+		sb.WriteString("_No status updates yet._\n")
+	}
+	for _, s := range d.Statuses {
+		fmt.Fprintf(sb, "- **%s**: %s _(%s)_\n", s.Member, s.Status, s.UpdatedAt.Format("2006-01-02 15:04"))
+	}
+}
+
+// nextID returns the next free ID with the given prefix ("D" -> "D4")
+func nextID(prefix string, ids []string) string {
+	max := 0
+	for _, id := range ids {
+		if n, err := strconv.Atoi(strings.TrimPrefix(id, prefix)); err == nil && n > max {
+			max = n
+		}
+	}
+	return prefix + strconv.Itoa(max+1)
+}
+
+// AddDecision records a decision and returns it
+func (d *SectionData) AddDecision(title, rationale, author string) *Decision {
+	ids := make([]string, len(d.Decisions))
+	for i, dec := range d.Decisions {
+		ids[i] = dec.ID
+	}
+	d.Decisions = append(d.Decisions, Decision{
+		ID:        nextID("D", ids),
+		Title:     title,
+		Rationale: rationale,
+		Author:    author,
+		Date:      time.Now().UTC(),
+	})
+	return &d.Decisions[len(d.Decisions)-1]
+}
+
+// AddQuestion records an open question and returns it
+func (d *SectionData) AddQuestion(text, asker, assignee string) *Question {
+	ids := make([]string, len(d.Questions))
+	for i, q := range d.Questions {
+		ids[i] = q.ID
+	}
+	d.Questions = append(d.Questions, Question{
+		ID:       nextID("Q", ids),
+		Text:     text,
+		Asker:    asker,
+		Assignee: assignee,
+		State:    QuestionOpen,
+		AskedAt:  time.Now().UTC(),
+	})
+	return &d.Questions[len(d.Questions)-1]
+}
+
+// AnswerQuestion marks a question answered. IDs match case-insensitively.
+func (d *SectionData) AnswerQuestion(id, answer, by string) (*Question, error) {
+	for i := range d.Questions {
+		q := &d.Questions[i]
+		if !strings.EqualFold(q.ID, id) {
+			continue
+		}
+		if q.State == QuestionAnswered {
+			return nil, fmt.Errorf("%s was already answered by %s", q.ID, q.AnsweredBy)
+		}
+		now := time.Now().UTC()
+		q.State = QuestionAnswered
+		q.Answer = answer
+		q.AnsweredBy = by
+		q.AnsweredAt = &now
+		return q, nil
+	}
+	return nil, fmt.Errorf("question %s not found", id)
+}
+
+// SetStatus sets a member's status line
+func (d *SectionData) SetStatus(member, status string) {
+	now := time.Now().UTC()
+	for i := range d.Statuses {
+		if d.Statuses[i].Member == member {
+			d.Statuses[i].Status = status
+			d.Statuses[i].UpdatedAt = now
+			return
+		}
+	}
+	d.Statuses = append(d.Statuses, MemberStatus{Member: member, Status: status, UpdatedAt: now})
+	sort.Slice(d.Statuses, func(i, j int) bool { return d.Statuses[i].Member < d.Statuses[j].Member })
+}
+
+// EditEntries applies fn to the typed entries of a section and saves it.
+// If the section changes concurrently, the latest version is fetched and fn
+// is applied again, so entries added by others are never overwritten.
+func EditEntries(cfg *account.Config, section string, fn func(*SectionData) error) (*account.BoardSection, error) {
+	schema := SchemaFor(section)
+	if schema == "" {
+		return nil, fmt.Errorf("section %q has no typed entries", section)
+	}
+
+	for attempt := 0; attempt < maxMergeAttempts; attempt++ {
+		current, err := account.GetBoardSection(cfg, section)
+		if err != nil {
+			return nil, err
+		}
+		if current == nil {
+			current = &account.BoardSection{Section: section}
+		}
+
+		data, err := ParseSection(schema, current.Content)
+		if err != nil {
+			return nil, err
+		}
+		if err := fn(data); err != nil {
+			return nil, err
+		}
+		content, err := data.Render()
+		if err != nil {
+			return nil, err
+		}
+
+		bs, err := account.PutBoardSection(cfg, section, content, current.Version)
+		if errors.Is(err, account.ErrVersionConflict) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		Apply([]account.BoardSection{*bs}, false) // Keep the local mirror current, best effort
+		return bs, nil
+	}
+	return nil, fmt.Errorf("%w: gave up after %d attempts", account.ErrVersionConflict, maxMergeAttempts)
+}
@@ -0,0 +1,53 @@
+package board
+
+import (
+	"strings"
+	"testing"
+)
+
+func renderDecisions(t *testing.T, notes string) string {
+	t.Helper()
+	d := &SectionData{Schema: SchemaDecisions, Notes: notes}
+	d.AddDecision("Use Postgres", "Team knows it", "alice")
+	content, err := d.Render()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return content
+}
+
+func TestParseSectionKeepsNotes(t *testing.T) {
+	content := renderDecisions(t, "Old notes")
+	i := strings.Index(content, dataMarker)
+
+	tests := []struct {
+		name, content, want string
+	}{
+		{"unchanged", content, "Old notes"},
+		{"notes edited", strings.Replace(content, "Old notes", "New notes", 1), "New notes"},
+		{"text appended", content[:i] + "Also this.\n" + content[i:], "Old notes\n\nAlso this."},
+		{"text after data block", content + "\nTrailing\n", "Old notes\n\nTrailing"},
+		{"notes removed", strings.Replace(content, "\n## Notes\n\nOld notes\n", "", 1), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := ParseSection(SchemaDecisions, tt.content)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if data.Notes != tt.want {
+				t.Errorf("notes = %q, want %q", data.Notes, tt.want)
+			}
+			if len(data.Decisions) != 1 {
+				t.Errorf("got %d decisions, want 1", len(data.Decisions))
+			}
+		})
+	}
+}
+
+func TestParseSectionRefusesEditedEntries(t *testing.T) {
+	content := strings.Replace(renderDecisions(t, ""), "Use Postgres", "Use MySQL", 1)
+	if _, err := ParseSection(SchemaDecisions, content); err == nil {
+		t.Fatal("hand-edited entry accepted")
+	}
+}
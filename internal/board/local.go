@@ -0,0 +1,181 @@
+// Package board keeps local copies of team board sections under .claw/board/
+package board
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/epuerta9/claw2claw/internal/account"
+)
+
+const (
+	boardDir  = ".claw/board"
+	baseDir   = ".claw/board/.base"
+	indexFile = ".claw/board/index.json"
+)
+
+// SectionMeta records the server version a local copy is based on
+type SectionMeta struct {
+	Section   string    `json:"section"`
+	File      string    `json:"file"`
+	Version   int       `json:"version"`
+	UpdatedBy string    `json:"updated_by"`
+	UpdatedAt time.Time `json:"updated_at"`
+	FetchedAt time.Time `json:"fetched_at"` // When this version was last confirmed with the server
+}
+
+// Index maps section names to their local metadata
+type Index struct {
+	Sections map[string]*SectionMeta `json:"sections"`
+	SyncedAt time.Time               `json:"synced_at,omitempty"` // Last full pull of the board
+}
+
+// LoadIndex loads .claw/board/index.json (empty if missing)
+func LoadIndex() (*Index, error) {
+	idx := &Index{Sections: make(map[string]*SectionMeta)}
+	data, err := os.ReadFile(indexFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return idx, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, idx); err != nil {
+		return nil, err
+	}
+	if idx.Sections == nil {
+		idx.Sections = make(map[string]*SectionMeta)
+	}
+	if err := idx.renameFiles(); err != nil {
+		return nil, err
+	}
+	return idx, nil
+}
+
+// renameFiles moves local copies written under an older FileName scheme to
+// their current names
+func (idx *Index) renameFiles() error {
+	renamed := false
+	for _, meta := range idx.Sections {
+		name := FileName(meta.Section)
+		if meta.File == name {
+			continue
+		}
+		for _, dir := range []string{boardDir, baseDir} {
+			if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+				continue // Never overwrite; sync fetches it again if needed
+			}
+			if err := os.Rename(filepath.Join(dir, meta.File), filepath.Join(dir, name)); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+		meta.File = name
+		renamed = true
+	}
+	if renamed {
+		return idx.Save()
+	}
+	return nil
+}
+
+// Save persists the index
+func (idx *Index) Save() error {
+	if err := os.MkdirAll(boardDir, 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(indexFile, data, 0644)
+}
+
+// FileName maps a section name to a safe markdown filename. Anything but
+// letters, digits, '.', '-' and '_' is percent-encoded, so distinct sections
+// never share a file ("context:eduardo" -> "context%3Aeduardo.md").
+func FileName(section string) string {
+	var sb strings.Builder
+	for i := 0; i < len(section); i++ {
+		c := section[i]
+		if 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '.' || c == '-' || c == '_' {
+			sb.WriteByte(c)
+		} else {
+			fmt.Fprintf(&sb, "%%%02X", c)
+		}
+	}
+	name := sb.String()
+	if strings.HasPrefix(name, ".") {
+		name = "%2E" + name[1:] // No hidden files (or "..")
+	}
+	return name + ".md"
+}
+
+// WorkingPath returns the editable local copy of a section
+func WorkingPath(section string) string {
+	return filepath.Join(boardDir, FileName(section))
+}
+
+// WriteWorking writes the editable local copy of a section
+func WriteWorking(section, content string) (string, error) {
+	if err := os.MkdirAll(boardDir, 0755); err != nil {
+		return "", err
+	}
+	path := WorkingPath(section)
+	return path, os.WriteFile(path, []byte(content), 0644)
+}
+
+// SaveBase records bs as the version later edits of this section are based on,
+// so a version conflict can be merged against a real common ancestor
+func SaveBase(bs *account.BoardSection) error {
+	idx, err := LoadIndex()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(baseDir, 0755); err != nil {
+		return err
+	}
+
+	name := FileName(bs.Section)
+	if err := os.WriteFile(filepath.Join(baseDir, name), []byte(bs.Content), 0644); err != nil {
+		return err
+	}
+	idx.Sections[bs.Section] = &SectionMeta{
+		Section:   bs.Section,
+		File:      name,
+		Version:   bs.Version,
+		UpdatedBy: bs.UpdatedBy,
+		UpdatedAt: bs.UpdatedAt,
+		FetchedAt: time.Now(),
+	}
+	return idx.Save()
+}
+
+// LoadBase returns the last version of a section seen locally, or nil if none
+func LoadBase(section string) (*account.BoardSection, error) {
+	idx, err := LoadIndex()
+	if err != nil {
+		return nil, err
+	}
+	meta, ok := idx.Sections[section]
+	if !ok {
+		return nil, nil
+	}
+	content, err := os.ReadFile(filepath.Join(baseDir, meta.File))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &account.BoardSection{
+		Section:   section,
+		Content:   string(content),
+		Version:   meta.Version,
+		UpdatedBy: meta.UpdatedBy,
+		UpdatedAt: meta.UpdatedAt,
+	}, nil
+}
@@ -0,0 +1,25 @@
+package board
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFileNameDistinct(t *testing.T) {
+	sections := []string{"context:eduardo", "context.eduardo", "context/eduardo", "context\\eduardo",
+		"context_eduardo", "context%3Aeduardo", ".hidden", "..", "notes"}
+	seen := make(map[string]string)
+	for _, s := range sections {
+		name := FileName(s)
+		if other, ok := seen[name]; ok {
+			t.Errorf("%q and %q both map to %s", s, other, name)
+		}
+		seen[name] = s
+		if strings.ContainsAny(name, `/\:`) || strings.HasPrefix(name, ".") {
+			t.Errorf("%q maps to unsafe name %s", s, name)
+		}
+	}
+	if got := FileName("context:eduardo"); got != "context%3Aeduardo.md" {
+		t.Errorf("FileName(context:eduardo) = %s", got)
+	}
+}
@@ -0,0 +1,131 @@
+package board
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/epuerta9/claw2claw/internal/account"
+	"github.com/epuerta9/claw2claw/internal/diff"
+)
+
+// maxMergeAttempts bounds retries when other agents keep updating a section
+const maxMergeAttempts = 3
+
+// ConflictError is returned when a merge could not be resolved automatically
+type ConflictError struct {
+	Section   string
+	Conflicts int
+	Theirs    *account.BoardSection
+	Merged    string // Content with conflict markers
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%d conflict(s) merging '%s' with v%d by %s",
+		e.Conflicts, e.Section, e.Theirs.Version, e.Theirs.UpdatedBy)
+}
+
+// Outcomes for a working copy that had unpushed edits of its own
+const (
+	LocalMerged   = "merged"   // Merged with the new version
+	LocalConflict = "conflict" // Merged, with conflict markers to resolve
+	LocalKept     = "kept"     // Left as it was (no base to merge against)
+)
+
+// UpdateResult describes a successful conflict-aware update
+type UpdateResult struct {
+	Section  *account.BoardSection
+	MergedBy []string // Authors whose concurrent changes were merged in
+	Local    string   // Set when the working copy had other unpushed edits
+}
+
+// Update writes content to a section based on base, the version the caller
+// last saw (nil to use the current version). On a version conflict it fetches
+// the competing revision, merges line-by-line against base and retries.
+func Update(cfg *account.Config, section, content string, base *account.BoardSection) (*UpdateResult, error) {
+	if base == nil {
+		current, err := account.GetBoardSection(cfg, section)
+		if err != nil {
+			return nil, err
+		}
+		base = current
+		if base == nil {
+			base = &account.BoardSection{Section: section}
+		}
+	}
+
+	result := &UpdateResult{}
+	ours := content
+	for attempt := 0; attempt < maxMergeAttempts; attempt++ {
+		bs, err := account.PutBoardSection(cfg, section, ours, base.Version)
+		if err == nil {
+			if result.Local, err = updateWorking(bs, content); err != nil {
+				return nil, fmt.Errorf("updated, but failed to write local copy: %w", err)
+			}
+			if err := SaveBase(bs); err != nil {
+				return nil, fmt.Errorf("updated, but failed to save local base: %w", err)
+			}
+			result.Section = bs
+			return result, nil
+		}
+		if !errors.Is(err, account.ErrVersionConflict) {
+			return nil, err
+		}
+
+		theirs, err := account.GetBoardSection(cfg, section)
+		if err != nil {
+			return nil, err
+		}
+		if theirs == nil {
+			theirs = &account.BoardSection{Section: section}
+		}
+
+		merged, conflicts := diff.Merge3(base.Content, ours, theirs.Content,
+			"yours", fmt.Sprintf("v%d by %s", theirs.Version, theirs.UpdatedBy))
+		if conflicts > 0 {
+			// The competing version becomes the base for the manual resolution
+			if err := SaveBase(theirs); err != nil {
+				return nil, err
+			}
+			return nil, &ConflictError{Section: section, Conflicts: conflicts, Theirs: theirs, Merged: merged}
+		}
+
+		result.MergedBy = append(result.MergedBy, theirs.UpdatedBy)
+		ours = merged
+		base = theirs
+	}
+	return nil, fmt.Errorf("%w: gave up after %d attempts", account.ErrVersionConflict, maxMergeAttempts)
+}
+
+// updateWorking brings the working copy up to the version just written. It is
+// replaced only if it holds nothing unpushed - it matches the mirror's base or
+// is what was pushed. Other edits are merged with the new version, or kept if
+// there is no base to merge against; the outcome is returned.
+func updateWorking(bs *account.BoardSection, pushed string) (string, error) {
+	working, hasWorking, err := readWorking(bs.Section)
+	if err != nil {
+		return "", err
+	}
+	base, err := LoadBase(bs.Section)
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case !hasWorking || sameContent(working, pushed) || sameContent(working, bs.Content) ||
+		base != nil && sameContent(working, base.Content):
+		_, err := WriteWorking(bs.Section, bs.Content)
+		return "", err
+	case base == nil:
+		return LocalKept, nil
+	}
+
+	merged, conflicts := diff.Merge3(base.Content, working, bs.Content,
+		"local", fmt.Sprintf("v%d by %s", bs.Version, bs.UpdatedBy))
+	if _, err := WriteWorking(bs.Section, merged); err != nil {
+		return "", err
+	}
+	if conflicts > 0 {
+		return LocalConflict, nil
+	}
+	return LocalMerged, nil
+}
@@ -0,0 +1,91 @@
+package board
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/epuerta9/claw2claw/internal/account"
+)
+
+func TestUpdateWorking(t *testing.T) {
+	const base = "one\ntwo\nthree\n"
+	tests := []struct {
+		name    string
+		working string // "" for no working copy
+		pushed  string
+		result  string // New version on the server
+		want    string
+		local   string
+	}{
+		{"no working copy", "", "one\ntwo\nthree\nfour\n", "one\ntwo\nthree\nfour\n", "one\ntwo\nthree\nfour\n", ""},
+		{"working copy pushed", "one\ntwo\nthree\nfour\n", "one\ntwo\nthree\nfour\n", "one\ntwo\nthree\nfour\n", "one\ntwo\nthree\nfour\n", ""},
+		{"working copy unchanged", base, "one\ntwo\nthree\nfour\n", "one\ntwo\nthree\nfour\n", "one\ntwo\nthree\nfour\n", ""},
+		{"unpushed edits merged", "zero\none\ntwo\nthree\n", "one\ntwo\nthree\nfour\n", "one\ntwo\nthree\nfour\n", "zero\none\ntwo\nthree\nfour\n", LocalMerged},
+		{"unpushed edits conflict", "one\nTWO\nthree\n", "one\n2\nthree\n", "one\n2\nthree\n", "", LocalConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chdirTemp(t)
+			if err := SaveBase(&account.BoardSection{Section: "status", Content: base, Version: 1}); err != nil {
+				t.Fatal(err)
+			}
+			if tt.working != "" {
+				if _, err := WriteWorking("status", tt.working); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			local, err := updateWorking(&account.BoardSection{Section: "status", Content: tt.result, Version: 2}, tt.pushed)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if local != tt.local {
+				t.Errorf("local = %q, want %q", local, tt.local)
+			}
+			got, err := os.ReadFile(WorkingPath("status"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.local == LocalConflict {
+				if !strings.Contains(string(got), "<<<<<<<") {
+					t.Errorf("working copy = %q, want conflict markers", got)
+				}
+			} else if string(got) != tt.want {
+				t.Errorf("working copy = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateWorkingKeepsEditsWithoutBase(t *testing.T) {
+	chdirTemp(t)
+	if _, err := WriteWorking("status", "my notes\n"); err != nil {
+		t.Fatal(err)
+	}
+	local, err := updateWorking(&account.BoardSection{Section: "status", Content: "pushed from elsewhere\n", Version: 1}, "pushed from elsewhere\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if local != LocalKept {
+		t.Errorf("local = %q, want %q", local, LocalKept)
+	}
+	if got, _ := os.ReadFile(WorkingPath("status")); string(got) != "my notes\n" {
+		t.Errorf("working copy = %q, want it left alone", got)
+	}
+}
+
+// chdirTemp runs the rest of the test in an empty directory, since the
+// mirror lives under the working directory
+func chdirTemp(t *testing.T) {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
@@ -0,0 +1,277 @@
+package board
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/epuerta9/claw2claw/internal/account"
+	"github.com/epuerta9/claw2claw/internal/diff"
+)
+
+// LocalSection is a section read from the mirror
+type LocalSection struct {
+	account.BoardSection
+	FetchedAt time.Time // When the base version was last confirmed with the server
+	Modified  bool      // Working copy has edits not yet pushed
+}
+
+// SyncReport summarizes a pull, push or full sync
+type SyncReport struct {
+	Pulled    []string // Sections updated from the server
+	Pushed    []string // Local edits written to the server
+	Merged    []string // Local edits merged with newer server versions
+	Conflicts []string // Sections left with conflict markers to resolve
+	Untracked []string // Markdown files in .claw/board/ that aren't a known section
+}
+
+// IsOffline reports whether err means the server could not be reached
+func IsOffline(err error) bool {
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
+// Apply brings the mirror up to date with sections fetched from the server.
+// Unmodified working copies are replaced; local edits are merged three-way
+// against the newer version, leaving conflict markers if they overlap.
+func Apply(sections []account.BoardSection, full bool) (*SyncReport, error) {
+	report := &SyncReport{}
+	for i := range sections {
+		theirs := &sections[i]
+
+		base, err := LoadBase(theirs.Section)
+		if err != nil {
+			return report, err
+		}
+		working, hasWorking, err := readWorking(theirs.Section)
+		if err != nil {
+			return report, err
+		}
+
+		modified := hasWorking && base != nil && !sameContent(working, base.Content)
+		switch {
+		case !modified:
+			if base == nil || base.Version != theirs.Version || !hasWorking {
+				if _, err := WriteWorking(theirs.Section, theirs.Content); err != nil {
+					return report, err
+				}
+				report.Pulled = append(report.Pulled, theirs.Section)
+			}
+		case theirs.Version != base.Version:
+			merged, conflicts := diff.Merge3(base.Content, working, theirs.Content,
+				"local", fmt.Sprintf("v%d by %s", theirs.Version, theirs.UpdatedBy))
+			if _, err := WriteWorking(theirs.Section, merged); err != nil {
+				return report, err
+			}
+			if conflicts > 0 {
+				report.Conflicts = append(report.Conflicts, theirs.Section)
+			} else {
+				report.Merged = append(report.Merged, theirs.Section)
+			}
+		}
+
+		if err := SaveBase(theirs); err != nil {
+			return report, err
+		}
+	}
+
+	if full {
+		idx, err := LoadIndex()
+		if err != nil {
+			return report, err
+		}
+		idx.SyncedAt = time.Now()
+		if err := idx.Save(); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+// Pull fetches the whole board into the mirror
+func Pull(cfg *account.Config) (*SyncReport, error) {
+	sections, err := account.GetBoard(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return Apply(sections, true)
+}
+
+// Push writes locally modified working copies to the server. Sections with
+// unresolved conflict markers are skipped.
+func Push(cfg *account.Config, report *SyncReport) error {
+	local, err := Mirror()
+	if err != nil {
+		return err
+	}
+	for _, ls := range local {
+		if !ls.Modified {
+			continue
+		}
+		if diff.HasConflictMarkers(ls.Content) {
+			if !contains(report.Conflicts, ls.Section) {
+				report.Conflicts = append(report.Conflicts, ls.Section)
+			}
+			continue
+		}
+
+		base, err := LoadBase(ls.Section)
+		if err != nil {
+			return err
+		}
+		result, err := Update(cfg, ls.Section, ls.Content, base)
+		if err != nil {
+			var conflict *ConflictError
+			if errors.As(err, &conflict) {
+				if _, err := WriteWorking(ls.Section, conflict.Merged); err != nil {
+					return err
+				}
+				report.Conflicts = append(report.Conflicts, ls.Section)
+				continue
+			}
+			return fmt.Errorf("failed to push %s: %w", ls.Section, err)
+		}
+		report.Pushed = append(report.Pushed, result.Section.Section)
+	}
+
+	untracked, err := untrackedFiles()
+	if err != nil {
+		return err
+	}
+	report.Untracked = untracked
+	return nil
+}
+
+// Sync pulls the board, merging with local edits, then pushes those edits
+func Sync(cfg *account.Config) (*SyncReport, error) {
+	report, err := Pull(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := Push(cfg, report); err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+// Mirror returns every section in the local mirror, sorted by name.
+// Content is the working copy, including edits that haven't been pushed.
+func Mirror() ([]LocalSection, error) {
+	idx, err := LoadIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(idx.Sections))
+	for name := range idx.Sections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sections []LocalSection
+	for _, name := range names {
+		ls, err := LoadLocal(name)
+		if err != nil {
+			return nil, err
+		}
+		if ls != nil {
+			sections = append(sections, *ls)
+		}
+	}
+	return sections, nil
+}
+
+// LoadLocal returns a section from the mirror, or nil if it isn't mirrored
+func LoadLocal(section string) (*LocalSection, error) {
+	idx, err := LoadIndex()
+	if err != nil {
+		return nil, err
+	}
+	meta, ok := idx.Sections[section]
+	if !ok {
+		return nil, nil
+	}
+	base, err := LoadBase(section)
+	if err != nil || base == nil {
+		return nil, err
+	}
+
+	ls := &LocalSection{BoardSection: *base, FetchedAt: meta.FetchedAt}
+	working, hasWorking, err := readWorking(section)
+	if err != nil {
+		return nil, err
+	}
+	if hasWorking && !sameContent(working, base.Content) {
+		ls.Content = strings.TrimSpace(working)
+		ls.Modified = true
+	}
+	return ls, nil
+}
+
+// SyncedAt returns when the whole board was last pulled (zero if never)
+func SyncedAt() time.Time {
+	idx, err := LoadIndex()
+	if err != nil {
+		return time.Time{}
+	}
+	return idx.SyncedAt
+}
+
+func readWorking(section string) (string, bool, error) {
+	data, err := os.ReadFile(WorkingPath(section))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+	return string(data), true, nil
+}
+
+// untrackedFiles lists markdown files that don't belong to a mirrored section
+func untrackedFiles() ([]string, error) {
+	idx, err := LoadIndex()
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[string]bool)
+	for _, meta := range idx.Sections {
+		known[meta.File] = true
+	}
+
+	entries, err := os.ReadDir(boardDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var untracked []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".md") || known[name] {
+			continue
+		}
+		untracked = append(untracked, filepath.Join(boardDir, name))
+	}
+	return untracked, nil
+}
+
+// sameContent ignores the trailing newline editors add
+func sameContent(a, b string) bool {
+	return strings.TrimSpace(a) == strings.TrimSpace(b)
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
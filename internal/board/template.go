@@ -0,0 +1,183 @@
+package board
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/epuerta9/claw2claw/internal/account"
+)
+
+//go:embed templates/*.json
+var builtinTemplates embed.FS
+
+const userTemplatesDir = ".claw/templates"
+
+// Template describes a board layout
+type Template struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Sections    []TemplateSection `json:"sections"`
+	Source      string            `json:"-"` // "built-in" or the file it was loaded from
+}
+
+// TemplateSection is one section created by a template. Section and Content may
+// use {member}, {team} and {date}; per-member sections are created once per member.
+// Typed sections (status, questions, decisions) with no content start empty.
+type TemplateSection struct {
+	Section   string `json:"section"`
+	Content   string `json:"content,omitempty"`
+	PerMember bool   `json:"per_member,omitempty"`
+}
+
+// GetTemplatesDir returns the directory for user-defined templates
+func GetTemplatesDir() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, userTemplatesDir)
+}
+
+// LoadTemplate loads a template by name (user templates shadow built-ins) or from a file path
+func LoadTemplate(nameOrPath string) (*Template, error) {
+	if strings.ContainsRune(nameOrPath, os.PathSeparator) || strings.HasSuffix(nameOrPath, ".json") {
+		return loadTemplateFile(nameOrPath)
+	}
+
+	userPath := filepath.Join(GetTemplatesDir(), nameOrPath+".json")
+	if _, err := os.Stat(userPath); err == nil {
+		return loadTemplateFile(userPath)
+	}
+
+	data, err := builtinTemplates.ReadFile("templates/" + nameOrPath + ".json")
+	if err != nil {
+		return nil, fmt.Errorf("template %q not found (see: claw board templates)", nameOrPath)
+	}
+	t, err := parseTemplate(data, nameOrPath)
+	if err != nil {
+		return nil, err
+	}
+	t.Source = "built-in"
+	return t, nil
+}
+
+func loadTemplateFile(path string) (*Template, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template: %w", err)
+	}
+	t, err := parseTemplate(data, strings.TrimSuffix(filepath.Base(path), ".json"))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	t.Source = path
+	return t, nil
+}
+
+func parseTemplate(data []byte, defaultName string) (*Template, error) {
+	var t Template
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	if t.Name == "" {
+		t.Name = defaultName
+	}
+	if len(t.Sections) == 0 {
+		return nil, fmt.Errorf("template %q has no sections", t.Name)
+	}
+	for _, s := range t.Sections {
+		if s.Section == "" {
+			return nil, fmt.Errorf("template %q has a section without a name", t.Name)
+		}
+		if s.PerMember != strings.Contains(s.Section, "{member}") {
+			return nil, fmt.Errorf("section %q: per_member sections must use {member} in the name (and only they may)", s.Section)
+		}
+	}
+	return &t, nil
+}
+
+// ListTemplates returns the built-in and user templates, sorted by name.
+// A user template with the same name as a built-in replaces it.
+func ListTemplates() ([]*Template, error) {
+	byName := make(map[string]*Template)
+
+	entries, err := builtinTemplates.ReadDir("templates")
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		t, err := LoadTemplate(strings.TrimSuffix(entry.Name(), ".json"))
+		if err != nil {
+			return nil, err
+		}
+		byName[t.Name] = t
+	}
+
+	userEntries, err := os.ReadDir(GetTemplatesDir())
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, entry := range userEntries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		t, err := loadTemplateFile(filepath.Join(GetTemplatesDir(), entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		byName[t.Name] = t
+	}
+
+	templates := make([]*Template, 0, len(byName))
+	for _, t := range byName {
+		templates = append(templates, t)
+	}
+	sort.Slice(templates, func(i, j int) bool { return templates[i].Name < templates[j].Name })
+	return templates, nil
+}
+
+// Expand renders the template into concrete sections for a team
+func (t *Template) Expand(teamID string, members []string) ([]account.BoardSection, error) {
+	date := time.Now().Format("2006-01-02")
+	var sections []account.BoardSection
+
+	add := func(ts TemplateSection, member string) error {
+		r := strings.NewReplacer("{member}", member, "{team}", teamID, "{date}", date)
+		name := r.Replace(ts.Section)
+		content := r.Replace(ts.Content)
+
+		if schema := SchemaFor(name); schema != "" && content == "" {
+			rendered, err := (&SectionData{Schema: schema}).Render()
+			if err != nil {
+				return err
+			}
+			content = rendered
+		}
+		if content == "" {
+			return fmt.Errorf("section %q has no content", name)
+		}
+		sections = append(sections, account.BoardSection{Section: name, Content: content})
+		return nil
+	}
+
+	for _, ts := range t.Sections {
+		if !ts.PerMember {
+			if err := add(ts, ""); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if len(members) == 0 {
+			return nil, fmt.Errorf("template %q has per-member sections, list the members", t.Name)
+		}
+		for _, m := range members {
+			if err := add(ts, m); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return sections, nil
+}
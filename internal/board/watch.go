@@ -0,0 +1,169 @@
+package board
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"time"
+
+	"github.com/epuerta9/claw2claw/internal/account"
+)
+
+// reconnectDelay is how long to wait before resubscribing after a dropped stream
+const reconnectDelay = 5 * time.Second
+
+// Change is a single observed section update
+type Change struct {
+	Previous *account.BoardSection // nil for a new section
+	Current  account.BoardSection
+}
+
+// WatchOptions configures Watch
+type WatchOptions struct {
+	Sections []string        // Only report these sections (all if empty)
+	Interval time.Duration   // Polling interval when streaming isn't available
+	OnPoll   func()          // Called when falling back to polling
+	OnError  func(err error) // Transient errors; watching continues
+}
+
+// transient reports whether watching should carry on after err: network
+// failures, dropped streams and temporary server errors are retried, while
+// an expired session or a refused request (e.g. 403) ends the watch
+func transient(err error) bool {
+	var status *account.StatusError
+	if errors.As(err, &status) {
+		return status.Temporary()
+	}
+	var netErr net.Error // Includes *url.Error, see IsOffline
+	return errors.As(err, &netErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// Watch reports section changes until ctx is cancelled. It subscribes to the
+// server's event stream when available and otherwise polls with ETags.
+// Transient errors are reported and retried; others end the watch.
+func Watch(ctx context.Context, cfg *account.Config, opts WatchOptions, onChange func(Change)) error {
+	w := &watcher{opts: opts, known: make(map[string]account.BoardSection), onChange: onChange}
+
+	// Seed with the current board so only later changes are reported
+	sections, etag, _, err := account.GetBoardIfChanged(cfg, "")
+	if err != nil {
+		return err
+	}
+	w.seed(sections)
+
+	for ctx.Err() == nil {
+		err := account.StreamBoard(ctx, cfg, w.observe)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if errors.Is(err, account.ErrStreamUnsupported) {
+			break
+		}
+		if !transient(err) {
+			return err
+		}
+		if !errors.Is(err, io.EOF) {
+			w.report(err)
+		}
+
+		// Catch up on anything missed while disconnected, then resubscribe
+		if !sleep(ctx, reconnectDelay) {
+			return nil
+		}
+		sections, newETag, changed, err := account.GetBoardIfChanged(cfg, etag)
+		if err != nil && !transient(err) {
+			return err
+		}
+		if err == nil && changed {
+			etag = newETag
+			w.observeAll(sections)
+		}
+	}
+
+	if opts.OnPoll != nil {
+		opts.OnPoll()
+	}
+	for sleep(ctx, opts.Interval) {
+		sections, newETag, changed, err := account.GetBoardIfChanged(cfg, etag)
+		if err != nil {
+			if !transient(err) {
+				return err
+			}
+			w.report(err)
+			continue
+		}
+		if changed {
+			etag = newETag
+			w.observeAll(sections)
+		}
+	}
+	return nil
+}
+
+type watcher struct {
+	opts     WatchOptions
+	known    map[string]account.BoardSection
+	onChange func(Change)
+}
+
+func (w *watcher) seed(sections []account.BoardSection) {
+	for _, s := range sections {
+		w.known[s.Section] = s
+	}
+}
+
+func (w *watcher) observeAll(sections []account.BoardSection) {
+	for _, s := range sections {
+		w.observe(s)
+	}
+}
+
+// observe reports a section if it is wanted and newer than the last seen version
+func (w *watcher) observe(s account.BoardSection) {
+	prev, seen := w.known[s.Section]
+	if seen && s.Version <= prev.Version {
+		return
+	}
+	w.known[s.Section] = s
+	if !w.wanted(s.Section) {
+		return
+	}
+
+	change := Change{Current: s}
+	if seen {
+		change.Previous = &prev
+	}
+	Apply([]account.BoardSection{s}, false) // Keep the local mirror current, best effort
+	w.onChange(change)
+}
+
+func (w *watcher) wanted(section string) bool {
+	if len(w.opts.Sections) == 0 {
+		return true
+	}
+	for _, s := range w.opts.Sections {
+		if s == section {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *watcher) report(err error) {
+	if err != nil && w.opts.OnError != nil {
+		w.opts.OnError(err)
+	}
+}
+
+// sleep waits for d, returning false if ctx is cancelled first
+func sleep(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
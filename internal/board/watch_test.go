@@ -0,0 +1,34 @@
+package board
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"testing"
+
+	"github.com/epuerta9/claw2claw/internal/account"
+)
+
+func TestTransient(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"stream ended", io.EOF, true},
+		{"connection dropped", io.ErrUnexpectedEOF, true},
+		{"offline", &url.Error{Op: "Get", URL: "https://x", Err: &net.OpError{Op: "dial", Err: errors.New("refused")}}, true},
+		{"server error", &account.StatusError{Op: "get board", Code: 502}, true},
+		{"rate limited", &account.StatusError{Op: "subscribe", Code: 429}, true},
+		{"forbidden", &account.StatusError{Op: "subscribe", Code: 403}, false},
+		{"session expired", account.ErrSessionExpired, false},
+		{"wrapped session expired", fmt.Errorf("%w (and failed to save config: x)", account.ErrSessionExpired), false},
+	}
+	for _, tt := range tests {
+		if got := transient(tt.err); got != tt.want {
+			t.Errorf("%s: transient() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,274 @@
+// Package clawpack implements .clawpack files: a gzipped tar holding a JSON
+// manifest (purpose, author, repository, open questions, TODOs) and the
+// related files, so a receiving agent gets organized context instead of a
+// pile of files
+package clawpack
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Ext is the file extension of a clawpack
+const Ext = ".clawpack"
+
+// Format and Version identify the manifest layout
+const (
+	Format  = "clawpack"
+	Version = 1
+)
+
+// Archive layout
+const (
+	manifestName = "clawpack.json"
+	filesPrefix  = "files/"
+)
+
+// Limits applied when reading a pack from someone else
+const (
+	maxManifestSize = 1 << 20
+	maxTotalSize    = 100 << 20
+	maxFiles        = 1000
+)
+
+// ErrNotPack is returned for data that isn't a clawpack
+var ErrNotPack = errors.New("not a clawpack")
+
+// Manifest describes a pack
+type Manifest struct {
+	Format     string      `json:"format"`
+	Version    int         `json:"version"`
+	Purpose    string      `json:"purpose"`
+	Author     string      `json:"author,omitempty"`
+	CreatedAt  time.Time   `json:"created_at"`
+	Repository *Repository `json:"repository,omitempty"`
+	Files      []File      `json:"files,omitempty"`
+	Questions  []string    `json:"questions,omitempty"`
+	TODOs      []string    `json:"todos,omitempty"`
+	Notes      string      `json:"notes,omitempty"`
+}
+
+// Repository is the git state the pack was made from
+type Repository struct {
+	Remote string `json:"remote,omitempty"`
+	Branch string `json:"branch,omitempty"`
+	Commit string `json:"commit,omitempty"`
+	Dirty  bool   `json:"dirty,omitempty"` // Uncommitted changes when packed
+}
+
+// File is a file included in the pack
+type File struct {
+	Path   string `json:"path"` // Slash-separated, relative to the pack root
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Pack is a clawpack read into memory
+type Pack struct {
+	Manifest *Manifest
+	Contents map[string][]byte // Keyed by File.Path
+}
+
+// Write builds a pack from files (paths relative to root) and writes it to
+// w. The manifest's file list is filled in from the files.
+func Write(w io.Writer, m *Manifest, root string, files []string) error {
+	m.Format, m.Version = Format, Version
+	if m.CreatedAt.IsZero() {
+		m.CreatedAt = time.Now()
+	}
+
+	type entry struct {
+		name string
+		data []byte
+	}
+	var entries []entry
+	m.Files = nil
+	seen := make(map[string]bool)
+	for _, f := range files {
+		name, err := cleanPath(filepath.ToSlash(f))
+		if err != nil {
+			return fmt.Errorf("%s: %w", f, err)
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(name)))
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(data)
+		m.Files = append(m.Files, File{Path: name, Size: int64(len(data)), SHA256: hex.EncodeToString(sum[:])})
+		entries = append(entries, entry{name, data})
+	}
+
+	manifestData, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	add := func(name string, data []byte) error {
+		hdr := &tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), ModTime: m.CreatedAt, Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		_, err := tw.Write(data)
+		return err
+	}
+
+	// The manifest goes first so readers can identify a pack cheaply
+	if err := add(manifestName, manifestData); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if err := add(filesPrefix+e.name, e.data); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// Read parses a pack, checking every file against the manifest. Paths are
+// validated and sizes bounded, since packs come from other users.
+func Read(r io.Reader) (*Pack, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, ErrNotPack
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+
+	p := &Pack{Contents: make(map[string][]byte)}
+	var total int64
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if p.Manifest == nil {
+				return nil, ErrNotPack
+			}
+			return nil, fmt.Errorf("corrupt clawpack: %w", err)
+		}
+
+		if p.Manifest == nil {
+			if hdr.Name != manifestName {
+				return nil, ErrNotPack
+			}
+			data, err := io.ReadAll(io.LimitReader(tr, maxManifestSize+1))
+			if err != nil || len(data) > maxManifestSize {
+				return nil, fmt.Errorf("invalid clawpack manifest")
+			}
+			var m Manifest
+			if err := json.Unmarshal(data, &m); err != nil || m.Format != Format {
+				return nil, ErrNotPack
+			}
+			if m.Version > Version {
+				return nil, fmt.Errorf("clawpack version %d is newer than this claw supports (%d)", m.Version, Version)
+			}
+			if len(m.Files) > maxFiles {
+				return nil, fmt.Errorf("clawpack lists too many files (%d)", len(m.Files))
+			}
+			p.Manifest = &m
+			continue
+		}
+
+		if hdr.Typeflag != tar.TypeReg || !strings.HasPrefix(hdr.Name, filesPrefix) {
+			return nil, fmt.Errorf("unexpected entry in clawpack: %q", hdr.Name)
+		}
+		name, err := cleanPath(strings.TrimPrefix(hdr.Name, filesPrefix))
+		if err != nil {
+			return nil, fmt.Errorf("unsafe path in clawpack: %q", hdr.Name)
+		}
+		total += hdr.Size
+		if hdr.Size < 0 || total > maxTotalSize {
+			return nil, fmt.Errorf("clawpack is too large")
+		}
+		data, err := io.ReadAll(io.LimitReader(tr, hdr.Size))
+		if err != nil {
+			return nil, fmt.Errorf("corrupt clawpack: %w", err)
+		}
+		p.Contents[name] = data
+	}
+	if p.Manifest == nil {
+		return nil, ErrNotPack
+	}
+
+	// Every listed file must be present and intact, and nothing else
+	listed := make(map[string]bool)
+	for _, f := range p.Manifest.Files {
+		data, ok := p.Contents[f.Path]
+		if !ok {
+			return nil, fmt.Errorf("clawpack is missing %s", f.Path)
+		}
+		sum := sha256.Sum256(data)
+		if hex.EncodeToString(sum[:]) != f.SHA256 {
+			return nil, fmt.Errorf("clawpack file %s does not match its checksum", f.Path)
+		}
+		listed[f.Path] = true
+	}
+	for name := range p.Contents {
+		if !listed[name] {
+			return nil, fmt.Errorf("clawpack contains unlisted file %s", name)
+		}
+	}
+	return p, nil
+}
+
+// Open reads a pack from disk
+func Open(filePath string) (*Pack, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Read(f)
+}
+
+// IsPack reports whether data looks like a clawpack: a gzipped tar whose
+// first entry is the manifest. It doesn't validate the pack; Read does.
+func IsPack(data []byte) bool {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return false
+	}
+	defer gz.Close()
+	hdr, err := tar.NewReader(gz).Next()
+	return err == nil && hdr.Name == manifestName
+}
+
+// cleanPath validates a relative slash path inside a pack
+func cleanPath(name string) (string, error) {
+	clean := path.Clean(name)
+	if name == "" || path.IsAbs(clean) || clean == "." || clean == ".." || strings.HasPrefix(clean, "../") || strings.ContainsAny(clean, "\\:\x00") {
+		return "", fmt.Errorf("invalid path")
+	}
+	return clean, nil
+}
+
+// SortedFiles returns the manifest's files ordered by path
+func (p *Pack) SortedFiles() []File {
+	files := append([]File(nil), p.Manifest.Files...)
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files
+}
@@ -0,0 +1,162 @@
+package clawpack
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWriteReadRoundTrip(t *testing.T) {
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, "docs"), 0755)
+	os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n"), 0644)
+	os.WriteFile(filepath.Join(root, "docs", "plan.md"), []byte("# Plan\n"), 0644)
+
+	m := &Manifest{Purpose: "handoff", Author: "alice", Questions: []string{"why?"}, TODOs: []string{"ship it"}}
+	var buf bytes.Buffer
+	if err := Write(&buf, m, root, []string{"main.go", "docs/plan.md", "./main.go"}); err != nil {
+		t.Fatal(err)
+	}
+	if !IsPack(buf.Bytes()) {
+		t.Fatal("IsPack() = false for a written pack")
+	}
+
+	p, err := Read(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Manifest.Purpose != "handoff" || p.Manifest.Author != "alice" || p.Manifest.Format != Format || p.Manifest.Version != Version {
+		t.Errorf("manifest = %+v", p.Manifest)
+	}
+	if !reflect.DeepEqual(p.Manifest.TODOs, []string{"ship it"}) || !reflect.DeepEqual(p.Manifest.Questions, []string{"why?"}) {
+		t.Errorf("questions/TODOs = %v / %v", p.Manifest.Questions, p.Manifest.TODOs)
+	}
+	want := map[string][]byte{"main.go": []byte("package main\n"), "docs/plan.md": []byte("# Plan\n")}
+	if !reflect.DeepEqual(p.Contents, want) {
+		t.Errorf("contents = %q, want %q", p.Contents, want)
+	}
+	if files := p.SortedFiles(); len(files) != 2 || files[0].Path != "docs/plan.md" {
+		t.Errorf("SortedFiles() = %v", files)
+	}
+}
+
+func TestWriteRejectsUnsafePaths(t *testing.T) {
+	for _, f := range []string{"../secret", "/etc/passwd", "a/../../b", ""} {
+		if err := Write(&bytes.Buffer{}, &Manifest{}, t.TempDir(), []string{f}); err == nil {
+			t.Errorf("Write(%q) succeeded", f)
+		}
+	}
+}
+
+// entry is a raw tar entry for building hostile packs
+type entry struct {
+	name string
+	data []byte
+	typ  byte  // tar.TypeReg if zero
+	size int64 // len(data) if zero; larger sizes write only the header
+}
+
+func build(t *testing.T, entries ...entry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.name, Mode: 0644, Typeflag: e.typ, Size: int64(len(e.data))}
+		if hdr.Typeflag == 0 {
+			hdr.Typeflag = tar.TypeReg
+		}
+		if hdr.Typeflag == tar.TypeSymlink {
+			hdr.Linkname, hdr.Size = "/etc/passwd", 0
+		}
+		if hdr.Typeflag == tar.TypeDir {
+			hdr.Size = 0
+		}
+		if e.size > 0 {
+			// Header only: the reader must refuse before reading the data
+			hdr.Size = e.size
+			if err := tw.WriteHeader(hdr); err != nil {
+				t.Fatal(err)
+			}
+			gz.Close()
+			return buf.Bytes()
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		tw.Write(e.data)
+	}
+	tw.Close()
+	gz.Close()
+	return buf.Bytes()
+}
+
+// manifest returns a manifest entry listing files with their checksums
+func manifest(files map[string]string) entry {
+	m := Manifest{Format: Format, Version: Version, Purpose: "test"}
+	for name, content := range files {
+		sum := sha256.Sum256([]byte(content))
+		m.Files = append(m.Files, File{Path: name, Size: int64(len(content)), SHA256: hex.EncodeToString(sum[:])})
+	}
+	data, _ := json.Marshal(m)
+	return entry{name: manifestName, data: data}
+}
+
+func TestReadRejectsHostilePacks(t *testing.T) {
+	ok := map[string]string{"a.txt": "hello"}
+	manyFiles := Manifest{Format: Format, Version: Version, Files: make([]File, maxFiles+1)}
+	manyData, _ := json.Marshal(manyFiles)
+	newer, _ := json.Marshal(Manifest{Format: Format, Version: Version + 1})
+
+	tests := []struct {
+		name string
+		pack []byte
+		want string // Substring of the error, "" for ErrNotPack
+	}{
+		{"parent traversal", build(t, manifest(ok), entry{name: "files/a.txt", data: []byte("hello")}, entry{name: "files/../../evil", data: []byte("x")}), "unsafe path"},
+		{"nested traversal", build(t, manifest(ok), entry{name: "files/a/../../../evil", data: []byte("x")}), "unsafe path"},
+		{"absolute path", build(t, manifest(ok), entry{name: "files//etc/passwd", data: []byte("x")}), "unsafe path"},
+		{"outside files/", build(t, manifest(ok), entry{name: "a.txt", data: []byte("hello")}), "unexpected entry"},
+		{"symlink", build(t, manifest(ok), entry{name: "files/a.txt", typ: tar.TypeSymlink}), "unexpected entry"},
+		{"directory", build(t, manifest(ok), entry{name: "files/dir/", typ: tar.TypeDir}), "unexpected entry"},
+		{"unlisted file", build(t, manifest(ok), entry{name: "files/a.txt", data: []byte("hello")}, entry{name: "files/extra.sh", data: []byte("rm -rf ~")}), "unlisted file"},
+		{"checksum mismatch", build(t, manifest(ok), entry{name: "files/a.txt", data: []byte("HELLO")}), "checksum"},
+		{"listed file missing", build(t, manifest(ok)), "missing a.txt"},
+		{"manifest not first", build(t, entry{name: "files/a.txt", data: []byte("hello")}, manifest(ok)), ""},
+		{"no manifest", build(t), ""},
+		{"not gzip", []byte("plain text"), ""},
+		{"manifest too large", build(t, entry{name: manifestName, data: bytes.Repeat([]byte(" "), maxManifestSize+1)}), "invalid clawpack manifest"},
+		{"too many files", build(t, entry{name: manifestName, data: manyData}), "too many files"},
+		{"newer version", build(t, entry{name: manifestName, data: newer}), "newer"},
+		{"total size cap", build(t, manifest(ok), entry{name: "files/a.txt", size: maxTotalSize + 1}), "too large"},
+	}
+	for _, tt := range tests {
+		_, err := Read(bytes.NewReader(tt.pack))
+		switch {
+		case err == nil:
+			t.Errorf("%s: Read() succeeded", tt.name)
+		case tt.want == "" && !errors.Is(err, ErrNotPack):
+			t.Errorf("%s: Read() error = %v, want ErrNotPack", tt.name, err)
+		case tt.want != "" && !strings.Contains(err.Error(), tt.want):
+			t.Errorf("%s: Read() error = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestIsPack(t *testing.T) {
+	if IsPack(build(t, entry{name: "files/a.txt", data: []byte("x")}, manifest(nil))) {
+		t.Error("IsPack() = true when the manifest isn't the first entry")
+	}
+	if IsPack([]byte("# notes")) {
+		t.Error("IsPack() = true for plain text")
+	}
+}
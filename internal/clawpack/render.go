@@ -0,0 +1,154 @@
+package clawpack
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/epuerta9/claw2claw/internal/gitpatch"
+	"github.com/epuerta9/claw2claw/internal/safereader"
+	"github.com/epuerta9/claw2claw/internal/transcript"
+)
+
+// maxInlineSize is the largest file shown in full in a briefing
+const maxInlineSize = 64 << 10
+
+// Render presents the pack as a structured briefing: purpose and
+// provenance first, then questions and TODOs, then the files
+func (p *Pack) Render() string {
+	m := p.Manifest
+	var sb strings.Builder
+
+	sb.WriteString("# Context Briefing (clawpack)\n\n")
+	fmt.Fprintf(&sb, "Purpose: %s\n", orNone(m.Purpose))
+	if m.Author != "" {
+		fmt.Fprintf(&sb, "Author: %s\n", m.Author)
+	}
+	fmt.Fprintf(&sb, "Created: %s\n", m.CreatedAt.Format("2006-01-02 15:04"))
+	if r := m.Repository; r != nil {
+		sb.WriteString("Repository:")
+		if r.Remote != "" {
+			sb.WriteString(" " + r.Remote)
+		}
+		if r.Branch != "" {
+			fmt.Fprintf(&sb, " (branch %s)", r.Branch)
+		}
+		if r.Commit != "" {
+			fmt.Fprintf(&sb, " at %s", shortCommit(r.Commit))
+		}
+		if r.Dirty {
+			sb.WriteString(", with uncommitted changes")
+		}
+		sb.WriteString("\n")
+	}
+
+	if m.Notes != "" {
+		sb.WriteString("\n## Notes\n\n")
+		sb.WriteString(strings.TrimRight(m.Notes, "\n") + "\n")
+	}
+	if len(m.Questions) > 0 {
+		sb.WriteString("\n## Open Questions\n\n")
+		for _, q := range m.Questions {
+			fmt.Fprintf(&sb, "- %s\n", q)
+		}
+	}
+	if len(m.TODOs) > 0 {
+		sb.WriteString("\n## TODOs\n\n")
+		for _, t := range m.TODOs {
+			fmt.Fprintf(&sb, "- [ ] %s\n", t)
+		}
+	}
+
+	files := p.SortedFiles()
+	if len(files) > 0 {
+		sb.WriteString("\n## Files\n\n")
+		for _, f := range files {
+			fmt.Fprintf(&sb, "- %s (%d bytes)\n", f.Path, f.Size)
+		}
+		for _, f := range files {
+			data := p.Contents[f.Path]
+			fmt.Fprintf(&sb, "\n### %s\n\n", f.Path)
+			switch {
+			case !isText(data):
+				sb.WriteString("(binary file, not shown)\n")
+			case len(data) > maxInlineSize:
+				fmt.Fprintf(&sb, "(%d bytes, too large to show; extract the pack to read it)\n", len(data))
+			default:
+				fence := fenceFor(data)
+				fmt.Fprintf(&sb, "%s\n%s\n%s\n", fence, strings.TrimRight(string(data), "\n"), fence)
+			}
+		}
+	}
+	return sb.String()
+}
+
+// ReadSafe reads a received file for an agent: clawpacks are rendered as a
+// briefing, git patches as their metadata and diff, and handoff transcripts
+// as a prior session summary before being scanned and wrapped; anything
+// else is scanned as it is
+func ReadSafe(filePath string) (*safereader.SafeContent, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	if patch, ok := gitpatch.Parse(data); ok {
+		info, err := os.Stat(filePath)
+		if err != nil {
+			return nil, err
+		}
+		return safereader.Scan(filePath, []byte(patch.Render()), info.ModTime()), nil
+	}
+	if !IsPack(data) {
+		return transcript.ReadSafe(filePath)
+	}
+
+	p, err := Read(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid clawpack: %w", err)
+	}
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return safereader.Scan(filePath, []byte(p.Render()), info.ModTime()), nil
+}
+
+// isText reports whether data is UTF-8 without NUL bytes
+func isText(data []byte) bool {
+	return utf8.Valid(data) && bytes.IndexByte(data, 0) < 0
+}
+
+// fenceFor returns a backtick fence longer than any run inside data
+func fenceFor(data []byte) string {
+	longest, run := 0, 0
+	for _, b := range data {
+		if b == '`' {
+			run++
+			if run > longest {
+				longest = run
+			}
+		} else {
+			run = 0
+		}
+	}
+	if longest < 3 {
+		return "```"
+	}
+	return strings.Repeat("`", longest+1)
+}
+
+func shortCommit(commit string) string {
+	if len(commit) > 12 {
+		return commit[:12]
+	}
+	return commit
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "(none given)"
+	}
+	return s
+}
@@ -0,0 +1,51 @@
+package clawpack
+
+import (
+	"os/exec"
+	"strings"
+)
+
+// DetectRepository describes the git repository containing dir, or returns
+// nil if dir isn't in one (or git isn't installed)
+func DetectRepository(dir string) *Repository {
+	commit, err := git(dir, "rev-parse", "HEAD")
+	if err != nil {
+		return nil
+	}
+	repo := &Repository{Commit: commit}
+	if branch, err := git(dir, "rev-parse", "--abbrev-ref", "HEAD"); err == nil && branch != "HEAD" {
+		repo.Branch = branch
+	}
+	if remote, err := git(dir, "remote", "get-url", "origin"); err == nil {
+		repo.Remote = stripCredentials(remote)
+	}
+	if status, err := git(dir, "status", "--porcelain", "--untracked-files=no"); err == nil {
+		repo.Dirty = status != ""
+	}
+	return repo
+}
+
+// GitAuthor returns git's configured user name, if any
+func GitAuthor(dir string) string {
+	name, _ := git(dir, "config", "user.name")
+	return name
+}
+
+func git(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	return strings.TrimSpace(string(out)), err
+}
+
+// stripCredentials removes user:password@ from an https remote URL
+func stripCredentials(remote string) string {
+	scheme, rest, ok := strings.Cut(remote, "://")
+	if !ok {
+		return remote
+	}
+	if at := strings.LastIndex(rest, "@"); at >= 0 && (strings.Index(rest, "/") < 0 || at < strings.Index(rest, "/")) {
+		rest = rest[at+1:]
+	}
+	return scheme + "://" + rest
+}
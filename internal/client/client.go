@@ -5,7 +5,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"io"
 	"os"
 	"path/filepath"
 	"sync"
@@ -13,6 +12,7 @@ import (
 
 	"github.com/epuerta9/claw2claw/internal/crypto"
 	"github.com/epuerta9/claw2claw/internal/protocol"
+	"github.com/epuerta9/claw2claw/internal/safereader"
 	"github.com/epuerta9/claw2claw/pkg/pake"
 	"github.com/gorilla/websocket"
 )
@@ -27,6 +27,7 @@ var (
 type Config struct {
 	RelayURL string
 	Timeout  time.Duration
+	Direct   bool // Try a direct TCP connection, using the relay for rendezvous only
 }
 
 // DefaultConfig returns default client configuration
@@ -40,9 +41,13 @@ func DefaultConfig() *Config {
 // Client is the claw2claw client for secure file transfer
 type Client struct {
 	config    *Config
-	conn      *websocket.Conn
+	conn      transport
 	connMu    sync.Mutex
 	sessionKey []byte
+	peerDirect bool // Receiver accepts a direct connection offer
+	direct     bool // Content went over a direct connection
+
+	candidates func(port int) []string // Addresses offered for direct connections
 }
 
 // New creates a new claw2claw client
@@ -53,6 +58,11 @@ func New(config *Config) *Client {
 	return &Client{config: config}
 }
 
+// Direct reports whether the last transfer bypassed the relay
+func (c *Client) Direct() bool {
+	return c.direct
+}
+
 // Send sends a file to a receiver using the given code phrase
 // Returns the code phrase to share with the receiver
 func (c *Client) Send(ctx context.Context, filePath string, codePhrase string) error {
@@ -93,33 +103,13 @@ func (c *Client) Send(ctx context.Context, filePath string, codePhrase string) e
 	}
 	c.sessionKey = sessionKey
 
-	// Encrypt and send content
-	encryptedContent, err := crypto.Encrypt(c.sessionKey, content)
-	if err != nil {
-		return fmt.Errorf("encryption failed: %w", err)
-	}
-
-	// Encrypt filename too
-	encryptedFilename, err := crypto.Encrypt(c.sessionKey, []byte(filename))
-	if err != nil {
-		return fmt.Errorf("filename encryption failed: %w", err)
-	}
-
-	// Send encrypted payload
-	payload := &protocol.EncryptedPayload{
-		Filename:   encryptedFilename,
-		Data:       encryptedContent,
-		TotalParts: 1,
-		PartNum:    0,
-	}
-
-	msg, _ := protocol.NewMessage(protocol.MsgEncrypted, codeHash, payload)
-	if err := c.sendMessage(msg); err != nil {
+	// Move to a direct connection if the receiver can reach us
+	if err := c.offerDirect(ctx, codeHash); err != nil {
 		return err
 	}
 
-	// Wait for ACK
-	return c.waitForAck(ctx)
+	// Encrypt and send content
+	return c.sendContent(ctx, codeHash, filename, content)
 }
 
 // Receive receives a file using the code phrase
@@ -149,46 +139,19 @@ func (c *Client) Receive(ctx context.Context, codePhrase string, outputDir strin
 	}
 	c.sessionKey = sessionKey
 
-	// Receive encrypted content
+	// Receive encrypted content, directly if the sender offers a connection
 	msg, err := c.receiveMessage(ctx)
 	if err != nil {
 		return "", err
 	}
-
-	if msg.Type != protocol.MsgEncrypted {
-		return "", fmt.Errorf("unexpected message type: %s", msg.Type)
-	}
-
-	var payload protocol.EncryptedPayload
-	if err := msg.GetPayload(&payload); err != nil {
-		return "", err
-	}
-
-	// Decrypt content
-	content, err := crypto.Decrypt(c.sessionKey, payload.Data)
-	if err != nil {
-		return "", fmt.Errorf("decryption failed: %w", err)
-	}
-
-	// Decrypt filename
-	filename, err := crypto.Decrypt(c.sessionKey, payload.Filename)
-	if err != nil {
-		return "", fmt.Errorf("filename decryption failed: %w", err)
-	}
-
-	// Write to output
-	outputPath := filepath.Join(outputDir, string(filename))
-	if err := os.WriteFile(outputPath, content, 0644); err != nil {
-		return "", fmt.Errorf("failed to write file: %w", err)
-	}
-
-	// Send ACK
-	ackMsg, _ := protocol.NewMessage(protocol.MsgAck, codeHash, nil)
-	if err := c.sendMessage(ackMsg); err != nil {
-		return "", err
+	if msg.Type == protocol.MsgDirectOffer {
+		if msg, err = c.acceptOffer(ctx, codeHash, msg); err != nil {
+			return "", err
+		}
 	}
 
-	return outputPath, nil
+	// Decrypt, write and acknowledge
+	return c.receiveContent(codeHash, msg, outputDir)
 }
 
 // SendPersistentWithCallback sends a file to a persistent room, calling onRoomCreated with the UUID
@@ -236,31 +199,13 @@ func (c *Client) SendPersistentWithCallback(ctx context.Context, filePath string
 	}
 	c.sessionKey = sessionKey
 
-	// Encrypt and send content
-	encryptedContent, err := crypto.Encrypt(c.sessionKey, content)
-	if err != nil {
-		return fmt.Errorf("encryption failed: %w", err)
-	}
-
-	encryptedFilename, err := crypto.Encrypt(c.sessionKey, []byte(filename))
-	if err != nil {
-		return fmt.Errorf("filename encryption failed: %w", err)
-	}
-
-	payload := &protocol.EncryptedPayload{
-		Filename:   encryptedFilename,
-		Data:       encryptedContent,
-		TotalParts: 1,
-		PartNum:    0,
-	}
-
-	msg, _ := protocol.NewMessage(protocol.MsgEncrypted, roomID, payload)
-	if err := c.sendMessage(msg); err != nil {
+	// Move to a direct connection if the receiver can reach us
+	if err := c.offerDirect(ctx, roomID); err != nil {
 		return err
 	}
 
-	// Wait for ACK
-	return c.waitForAck(ctx)
+	// Encrypt and send content
+	return c.sendContent(ctx, roomID, filename, content)
 }
 
 // ReceivePersistent receives a file from a persistent room using UUID
@@ -289,12 +234,53 @@ func (c *Client) ReceivePersistent(ctx context.Context, roomID string, codePhras
 	}
 	c.sessionKey = sessionKey
 
-	// Receive encrypted content
+	// Receive encrypted content, directly if the sender offers a connection
 	msg, err := c.receiveMessage(ctx)
 	if err != nil {
 		return "", err
 	}
+	if msg.Type == protocol.MsgDirectOffer {
+		if msg, err = c.acceptOffer(ctx, roomID, msg); err != nil {
+			return "", err
+		}
+	}
 
+	// Decrypt, write and acknowledge
+	return c.receiveContent(roomID, msg, outputDir)
+}
+
+// sendContent encrypts and sends a file to the peer, then waits for the ACK
+func (c *Client) sendContent(ctx context.Context, roomID string, filename string, content []byte) error {
+	encryptedContent, err := crypto.Encrypt(c.sessionKey, content)
+	if err != nil {
+		return fmt.Errorf("encryption failed: %w", err)
+	}
+
+	// Encrypt filename too
+	encryptedFilename, err := crypto.Encrypt(c.sessionKey, []byte(filename))
+	if err != nil {
+		return fmt.Errorf("filename encryption failed: %w", err)
+	}
+
+	payload := &protocol.EncryptedPayload{
+		Filename:   encryptedFilename,
+		Data:       encryptedContent,
+		TotalParts: 1,
+		PartNum:    0,
+	}
+
+	msg, _ := protocol.NewMessage(protocol.MsgEncrypted, roomID, payload)
+	if err := c.sendMessage(msg); err != nil {
+		return err
+	}
+
+	// Wait for ACK
+	return c.waitForAck(ctx)
+}
+
+// receiveContent decrypts an ENCRYPTED message, writes the file to outputDir
+// and acknowledges it
+func (c *Client) receiveContent(roomID string, msg *protocol.Message, outputDir string) (string, error) {
 	if msg.Type != protocol.MsgEncrypted {
 		return "", fmt.Errorf("unexpected message type: %s", msg.Type)
 	}
@@ -316,9 +302,9 @@ func (c *Client) ReceivePersistent(ctx context.Context, roomID string, codePhras
 		return "", fmt.Errorf("filename decryption failed: %w", err)
 	}
 
-	// Write to output
-	outputPath := filepath.Join(outputDir, string(filename))
-	if err := os.WriteFile(outputPath, content, 0644); err != nil {
+	// Write to output - the filename comes from the peer, so sanitize it and never overwrite
+	sc, err := safereader.WriteSafe(outputDir, string(filename), content)
+	if err != nil {
 		return "", fmt.Errorf("failed to write file: %w", err)
 	}
 
@@ -328,7 +314,7 @@ func (c *Client) ReceivePersistent(ctx context.Context, roomID string, codePhras
 		return "", err
 	}
 
-	return outputPath, nil
+	return sc.Path, nil
 }
 
 // connect establishes WebSocket connection to relay
@@ -344,11 +330,11 @@ func (c *Client) connect(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("failed to connect to relay: %w", err)
 	}
-	c.conn = conn
+	c.conn = &wsTransport{conn: conn}
 	return nil
 }
 
-// disconnect closes the WebSocket connection
+// disconnect closes the connection to the peer
 func (c *Client) disconnect() {
 	c.connMu.Lock()
 	defer c.connMu.Unlock()
@@ -498,6 +484,7 @@ func (c *Client) performPakeExchange(ctx context.Context, session *pake.Session,
 		if err := response.GetPayload(&pakePayload); err != nil {
 			return nil, err
 		}
+		c.peerDirect = pakePayload.Direct
 
 		if err := session.ProcessMessage(pakePayload.Data); err != nil {
 			return nil, ErrPakeExchangeFailed
@@ -520,7 +507,7 @@ func (c *Client) performPakeExchange(ctx context.Context, session *pake.Session,
 
 		// Send PAKE_B
 		pakeMsg, _ := session.GetMessage()
-		payload := &protocol.PakePayload{Data: pakeMsg}
+		payload := &protocol.PakePayload{Data: pakeMsg, Direct: c.config.Direct}
 		response, _ := protocol.NewMessage(protocol.MsgPakeB, codeHash, payload)
 		if err := c.sendMessage(response); err != nil {
 			return nil, err
@@ -530,7 +517,7 @@ func (c *Client) performPakeExchange(ctx context.Context, session *pake.Session,
 	return session.GetSharedKey()
 }
 
-// sendMessage sends a protocol message over WebSocket
+// sendMessage sends a protocol message to the peer
 func (c *Client) sendMessage(msg *protocol.Message) error {
 	c.connMu.Lock()
 	defer c.connMu.Unlock()
@@ -538,37 +525,19 @@ func (c *Client) sendMessage(msg *protocol.Message) error {
 	if c.conn == nil {
 		return ErrNotConnected
 	}
-
-	data, err := msg.Encode()
-	if err != nil {
-		return err
-	}
-
-	return c.conn.WriteMessage(websocket.TextMessage, data)
+	return c.conn.Send(msg)
 }
 
-// receiveMessage receives a protocol message from WebSocket
+// receiveMessage receives a protocol message from the peer
 func (c *Client) receiveMessage(ctx context.Context) (*protocol.Message, error) {
-	if c.conn == nil {
-		return nil, ErrNotConnected
-	}
-
-	// Set read deadline based on context
-	if deadline, ok := ctx.Deadline(); ok {
-		c.conn.SetReadDeadline(deadline)
-	} else {
-		c.conn.SetReadDeadline(time.Now().Add(c.config.Timeout))
-	}
+	c.connMu.Lock()
+	conn := c.conn
+	c.connMu.Unlock()
 
-	_, data, err := c.conn.ReadMessage()
-	if err != nil {
-		if err == io.EOF {
-			return nil, ErrNotConnected
-		}
-		return nil, err
+	if conn == nil {
+		return nil, ErrNotConnected
 	}
-
-	return protocol.DecodeMessage(data)
+	return conn.Receive(ctx, c.config.Timeout)
 }
 
 // waitForAck waits for acknowledgment from receiver
@@ -0,0 +1,296 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/epuerta9/claw2claw/internal/crypto"
+	"github.com/epuerta9/claw2claw/internal/protocol"
+)
+
+// Direct mode: after PAKE the sender listens on TCP and sends its addresses
+// and a one-time token over the relay, encrypted with the session key. The
+// receiver dials them and proves the key; the first connection to complete
+// the handshake replaces the relay for the rest of the transfer. If none
+// does, the receiver sends DIRECT_FAILED and both sides keep relaying.
+
+// directDialTimeout bounds the receiver's attempts to reach the sender
+const directDialTimeout = 5 * time.Second
+
+// Handshake prefixes keep a HELLO from being replayed as a READY
+const (
+	helloPrefix = "claw-direct-hello:"
+	readyPrefix = "claw-direct-ready:"
+)
+
+// offerDirect offers the receiver a direct connection and switches to it if
+// the receiver connects. Without a direct connection the relay carries on.
+func (c *Client) offerDirect(ctx context.Context, roomID string) error {
+	if !c.config.Direct || !c.peerDirect {
+		return nil
+	}
+
+	ln, err := net.Listen("tcp", ":0")
+	if err != nil {
+		return nil // Can't listen here: relay the content
+	}
+	defer ln.Close()
+
+	token, err := crypto.GenerateRandom(32)
+	if err != nil {
+		return err
+	}
+	candidates := c.candidates
+	if candidates == nil {
+		candidates = localAddresses
+	}
+	offer := &protocol.DirectOffer{
+		Addresses: candidates(ln.Addr().(*net.TCPAddr).Port),
+		Token:     token,
+	}
+	if err := c.sendDirect(protocol.MsgDirectOffer, roomID, offer); err != nil {
+		return err
+	}
+
+	// The receiver either completes a handshake on the listener or reports
+	// over the relay that it couldn't
+	c.connMu.Lock()
+	relay := c.conn
+	c.connMu.Unlock()
+	relayed := make(chan error, 1)
+	go func() {
+		msg, err := relay.Receive(ctx, c.config.Timeout)
+		if err == nil && msg.Type != protocol.MsgDirectFailed {
+			err = fmt.Errorf("expected DIRECT_FAILED, got %s", msg.Type)
+		}
+		relayed <- err
+	}()
+
+	accepted := make(chan *tcpTransport, 1)
+	go c.acceptDirect(ctx, ln, token, accepted)
+
+	select {
+	case t := <-accepted:
+		c.useDirect(t)
+		return nil
+	case err := <-relayed:
+		return err // nil: the receiver couldn't connect, keep relaying
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// acceptDirect accepts connections until one completes the handshake, which
+// is delivered on accepted. It stops when the listener is closed.
+func (c *Client) acceptDirect(ctx context.Context, ln net.Listener, token []byte, accepted chan<- *tcpTransport) {
+	var claimed atomic.Bool
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			t := newTCPTransport(conn)
+			hctx, cancel := context.WithTimeout(ctx, directDialTimeout)
+			defer cancel()
+
+			msg, err := t.Receive(hctx, directDialTimeout)
+			if err != nil || msg.Type != protocol.MsgDirectHello || !c.checkDirect(msg, helloPrefix, token) {
+				t.Close() // Not our receiver
+				return
+			}
+			if !claimed.CompareAndSwap(false, true) {
+				t.Close() // Already connected on another address
+				return
+			}
+			if err := c.sendDirectOn(t, protocol.MsgDirectReady, readyPrefix, token); err != nil {
+				t.Close()
+				return
+			}
+			accepted <- t
+		}()
+	}
+}
+
+// acceptOffer tries to connect to the sender's offered addresses. On success
+// the direct connection replaces the relay; otherwise the sender is told to
+// keep relaying. It returns the next message from whichever is in use.
+func (c *Client) acceptOffer(ctx context.Context, roomID string, msg *protocol.Message) (*protocol.Message, error) {
+	var offer protocol.DirectOffer
+	if err := c.openDirect(msg, &offer); err != nil {
+		return nil, err
+	}
+
+	if c.config.Direct {
+		if t := c.dialDirect(ctx, offer); t != nil {
+			c.useDirect(t)
+			return c.receiveMessage(ctx)
+		}
+	}
+
+	failed, _ := protocol.NewMessage(protocol.MsgDirectFailed, roomID, nil)
+	if err := c.sendMessage(failed); err != nil {
+		return nil, err
+	}
+	return c.receiveMessage(ctx)
+}
+
+// dialDirect dials every offered address at once and returns the first
+// connection to complete the handshake, or nil
+func (c *Client) dialDirect(ctx context.Context, offer protocol.DirectOffer) *tcpTransport {
+	ctx, cancel := context.WithTimeout(ctx, directDialTimeout)
+	defer cancel()
+
+	results := make(chan *tcpTransport, len(offer.Addresses))
+	var wg sync.WaitGroup
+	for _, addr := range offer.Addresses {
+		wg.Add(1)
+		go func(addr string) {
+			defer wg.Done()
+			t, err := c.handshake(ctx, addr, offer.Token)
+			if err != nil {
+				return
+			}
+			results <- t
+		}(addr)
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	var winner *tcpTransport
+	for t := range results {
+		if winner == nil {
+			winner = t
+			cancel() // Stop the other attempts
+		} else {
+			t.Close()
+		}
+	}
+	return winner
+}
+
+// handshake connects to addr and exchanges HELLO/READY with the sender
+func (c *Client) handshake(ctx context.Context, addr string, token []byte) (*tcpTransport, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	// Abort the exchange if another address wins or time runs out
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+
+	t := newTCPTransport(conn)
+	err = c.sendDirectOn(t, protocol.MsgDirectHello, helloPrefix, token)
+	if err == nil {
+		var msg *protocol.Message
+		if msg, err = t.Receive(ctx, directDialTimeout); err == nil &&
+			(msg.Type != protocol.MsgDirectReady || !c.checkDirect(msg, readyPrefix, token)) {
+			err = errors.New("unexpected direct handshake reply")
+		}
+	}
+	if !stop() || err != nil {
+		conn.Close()
+		if err == nil {
+			err = ctx.Err()
+		}
+		return nil, err
+	}
+	return t, nil
+}
+
+// useDirect switches the transfer to a direct connection and drops the relay
+func (c *Client) useDirect(t *tcpTransport) {
+	t.conn.SetDeadline(time.Time{})
+	t.limit = maxFrameSize // The peer has proven the session key
+
+	c.connMu.Lock()
+	defer c.connMu.Unlock()
+	if c.conn != nil {
+		c.conn.Close()
+	}
+	c.conn = t
+	c.direct = true
+}
+
+// sendDirect sends v encrypted with the session key over the relay
+func (c *Client) sendDirect(msgType protocol.MessageType, roomID string, v interface{}) error {
+	msg, err := c.directMessage(msgType, roomID, v)
+	if err != nil {
+		return err
+	}
+	return c.sendMessage(msg)
+}
+
+// sendDirectOn sends a handshake message proving the session key on t
+func (c *Client) sendDirectOn(t *tcpTransport, msgType protocol.MessageType, prefix string, token []byte) error {
+	msg, err := c.directMessage(msgType, "", append([]byte(prefix), token...))
+	if err != nil {
+		return err
+	}
+	return t.Send(msg)
+}
+
+// checkDirect reports whether a handshake message holds prefix+token
+// encrypted with the session key
+func (c *Client) checkDirect(msg *protocol.Message, prefix string, token []byte) bool {
+	var proof []byte
+	if err := c.openDirect(msg, &proof); err != nil {
+		return false
+	}
+	return bytes.Equal(proof, append([]byte(prefix), token...))
+}
+
+// directMessage builds a message carrying v encrypted with the session key
+func (c *Client) directMessage(msgType protocol.MessageType, roomID string, v interface{}) (*protocol.Message, error) {
+	plain, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	data, err := crypto.Encrypt(c.sessionKey, plain)
+	if err != nil {
+		return nil, fmt.Errorf("encryption failed: %w", err)
+	}
+	return protocol.NewMessage(msgType, roomID, &protocol.DirectPayload{Data: data})
+}
+
+// openDirect decrypts a direct payload into v
+func (c *Client) openDirect(msg *protocol.Message, v interface{}) error {
+	var payload protocol.DirectPayload
+	if err := msg.GetPayload(&payload); err != nil {
+		return err
+	}
+	plain, err := crypto.Decrypt(c.sessionKey, payload.Data)
+	if err != nil {
+		return fmt.Errorf("decryption failed: %w", err)
+	}
+	return json.Unmarshal(plain, v)
+}
+
+// localAddresses lists the addresses a peer might reach port on: loopback
+// first (same host), then every non-link-local unicast interface address
+func localAddresses(port int) []string {
+	p := strconv.Itoa(port)
+	addrs := []string{net.JoinHostPort("127.0.0.1", p)}
+	ifaceAddrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return addrs
+	}
+	for _, a := range ifaceAddrs {
+		ipnet, ok := a.(*net.IPNet)
+		if !ok || ipnet.IP.IsLoopback() || ipnet.IP.IsLinkLocalUnicast() || !ipnet.IP.IsGlobalUnicast() {
+			continue
+		}
+		addrs = append(addrs, net.JoinHostPort(ipnet.IP.String(), p))
+	}
+	return addrs
+}
@@ -0,0 +1,212 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/epuerta9/claw2claw/internal/protocol"
+	"github.com/gorilla/websocket"
+)
+
+// testRelay pairs a sender and receiver by code hash and forwards their
+// messages, recording the type of each one it forwards
+type testRelay struct {
+	mu        sync.Mutex
+	rooms     map[string]*websocket.Conn
+	peers     map[*websocket.Conn]*websocket.Conn
+	forwarded []protocol.MessageType
+}
+
+func newTestRelay(t *testing.T) (*testRelay, string) {
+	r := &testRelay{rooms: make(map[string]*websocket.Conn), peers: make(map[*websocket.Conn]*websocket.Conn)}
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+	return r, "ws" + strings.TrimPrefix(srv.URL, "http")
+}
+
+func (r *testRelay) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	upgrader := websocket.Upgrader{}
+	conn, err := upgrader.Upgrade(w, req, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			r.mu.Lock()
+			if peer := r.peers[conn]; peer != nil {
+				peer.Close()
+			}
+			r.mu.Unlock()
+			return
+		}
+		msg, err := protocol.DecodeMessage(data)
+		if err != nil {
+			return
+		}
+
+		r.mu.Lock()
+		switch msg.Type {
+		case protocol.MsgCreateRoom:
+			r.rooms[msg.RoomID] = conn
+			r.write(conn, protocol.MsgRoomJoined, msg.RoomID)
+		case protocol.MsgJoinRoom:
+			creator := r.rooms[msg.RoomID]
+			r.peers[conn], r.peers[creator] = creator, conn
+			r.write(conn, protocol.MsgRoomReady, msg.RoomID)
+			r.write(creator, protocol.MsgRoomReady, msg.RoomID)
+		default:
+			r.forwarded = append(r.forwarded, msg.Type)
+			r.peers[conn].WriteMessage(websocket.TextMessage, data)
+		}
+		r.mu.Unlock()
+	}
+}
+
+func (r *testRelay) write(conn *websocket.Conn, msgType protocol.MessageType, roomID string) {
+	msg, _ := protocol.NewMessage(msgType, roomID, nil)
+	data, _ := msg.Encode()
+	conn.WriteMessage(websocket.TextMessage, data)
+}
+
+func (r *testRelay) saw(msgType protocol.MessageType) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, t := range r.forwarded {
+		if t == msgType {
+			return true
+		}
+	}
+	return false
+}
+
+// transfer sends a file from sender to receiver through the relay and
+// returns the received content
+func transfer(t *testing.T, sender, receiver *Client) string {
+	t.Helper()
+	dir := t.TempDir()
+	src := filepath.Join(dir, "notes.md")
+	if err := os.WriteFile(src, []byte("direct transfer test"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(dir, "out")
+	if err := os.Mkdir(out, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	sent := make(chan error, 1)
+	go func() { sent <- sender.Send(ctx, src, "swift-tiger-gold-42") }()
+	time.Sleep(100 * time.Millisecond) // Let the sender create the room
+
+	path, err := receiver.Receive(ctx, "swift-tiger-gold-42", out)
+	if err != nil {
+		t.Fatalf("receive: %v", err)
+	}
+	if err := <-sent; err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func newTestClient(relayURL string, direct bool) *Client {
+	return New(&Config{RelayURL: relayURL, Timeout: 10 * time.Second, Direct: direct})
+}
+
+func TestDirectLoopback(t *testing.T) {
+	relay, url := newTestRelay(t)
+	sender, receiver := newTestClient(url, true), newTestClient(url, true)
+	sender.candidates = func(port int) []string { return localAddresses(port)[:1] }
+
+	if got := transfer(t, sender, receiver); got != "direct transfer test" {
+		t.Fatalf("content = %q", got)
+	}
+	if !sender.Direct() || !receiver.Direct() {
+		t.Fatalf("direct: sender %v, receiver %v", sender.Direct(), receiver.Direct())
+	}
+	if !relay.saw(protocol.MsgDirectOffer) {
+		t.Error("relay didn't carry the offer")
+	}
+	if relay.saw(protocol.MsgEncrypted) || relay.saw(protocol.MsgAck) {
+		t.Error("content went through the relay")
+	}
+}
+
+func TestDirectFallsBackToRelay(t *testing.T) {
+	relay, url := newTestRelay(t)
+	sender, receiver := newTestClient(url, true), newTestClient(url, true)
+	// Nothing listens on port 1
+	sender.candidates = func(int) []string { return []string{"127.0.0.1:1"} }
+
+	if got := transfer(t, sender, receiver); got != "direct transfer test" {
+		t.Fatalf("content = %q", got)
+	}
+	if sender.Direct() || receiver.Direct() {
+		t.Fatal("transfer should have been relayed")
+	}
+	if !relay.saw(protocol.MsgDirectFailed) || !relay.saw(protocol.MsgEncrypted) {
+		t.Errorf("relay forwarded %v", relay.forwarded)
+	}
+}
+
+func TestDirectNeedsBothPeers(t *testing.T) {
+	for _, tc := range []struct {
+		name             string
+		sender, receiver bool
+	}{
+		{"sender only", true, false},
+		{"receiver only", false, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			relay, url := newTestRelay(t)
+			sender, receiver := newTestClient(url, tc.sender), newTestClient(url, tc.receiver)
+
+			if got := transfer(t, sender, receiver); got != "direct transfer test" {
+				t.Fatalf("content = %q", got)
+			}
+			if relay.saw(protocol.MsgDirectOffer) || sender.Direct() || receiver.Direct() {
+				t.Error("no direct connection should be offered")
+			}
+		})
+	}
+}
+
+func TestDirectRejectsWrongKey(t *testing.T) {
+	ln := newTestClient("", true)
+	ln.sessionKey = make([]byte, 32)
+	other := newTestClient("", true)
+	other.sessionKey = make([]byte, 32)
+	other.sessionKey[0] = 1
+
+	token := []byte("token")
+	msg, err := other.directMessage(protocol.MsgDirectHello, "", append([]byte(helloPrefix), token...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ln.checkDirect(msg, helloPrefix, token) {
+		t.Error("hello under another key was accepted")
+	}
+	// A hello replayed as a ready must not verify
+	msg, _ = ln.directMessage(protocol.MsgDirectHello, "", append([]byte(helloPrefix), token...))
+	if ln.checkDirect(msg, readyPrefix, token) {
+		t.Error("hello accepted as ready")
+	}
+	if !ln.checkDirect(msg, helloPrefix, token) {
+		t.Error("valid hello rejected")
+	}
+}
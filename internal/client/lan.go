@@ -0,0 +1,188 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/epuerta9/claw2claw/internal/lan"
+	"github.com/epuerta9/claw2claw/internal/protocol"
+	"github.com/epuerta9/claw2claw/pkg/pake"
+)
+
+// LAN mode: the sender listens on TCP and publishes the share over mDNS under
+// a random ID. The receiver browses for shares and runs PAKE against each
+// until one accepts its code; the relay isn't involved at all. Guesses are
+// bounded by the sender's attempt limit since nothing published is derived
+// from the code.
+
+// maxLANAttempts bounds failed connections (e.g. code guesses) per share
+const maxLANAttempts = 5
+
+// lanHandshakeTimeout bounds PAKE and key confirmation on one connection
+const lanHandshakeTimeout = 10 * time.Second
+
+// SendLAN shares a file on the local network and waits for a receiver
+func (c *Client) SendLAN(ctx context.Context, filePath string, codePhrase string) error {
+	// Read file content
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+	filename := filepath.Base(filePath)
+
+	ln, err := net.Listen("tcp", ":0")
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+	defer ln.Close()
+	stop := context.AfterFunc(ctx, func() { ln.Close() })
+	defer stop()
+
+	session, err := pake.NewSession(codePhrase, pake.RoleSender)
+	if err != nil {
+		return fmt.Errorf("failed to create PAKE session: %w", err)
+	}
+	codeHash := session.GetCodeHashString()
+
+	id, err := lan.NewServiceID()
+	if err != nil {
+		return fmt.Errorf("failed to generate share ID: %w", err)
+	}
+	adv, err := lan.Advertise(id, ln.Addr().(*net.TCPAddr).Port)
+	if err != nil {
+		return err
+	}
+	defer adv.Close()
+
+	// Accept until a peer completes PAKE and proves it holds the key
+	for failed := 0; ; {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("failed to accept connection: %w", err)
+		}
+		if err := c.lanHandshake(ctx, conn, codePhrase, pake.RoleSender); err == nil {
+			break
+		}
+		conn.Close()
+		if failed++; failed >= maxLANAttempts {
+			return fmt.Errorf("giving up after %d failed connection attempts", failed)
+		}
+	}
+	defer c.disconnect()
+
+	// Stop advertising once connected
+	adv.Close()
+
+	// Encrypt and send content
+	return c.sendContent(ctx, codeHash, filename, content)
+}
+
+// ReceiveLAN finds a share on the local network and receives it
+func (c *Client) ReceiveLAN(ctx context.Context, codePhrase string, outputDir string) (string, error) {
+	session, err := pake.NewSession(codePhrase, pake.RoleReceiver)
+	if err != nil {
+		return "", fmt.Errorf("failed to create PAKE session: %w", err)
+	}
+	codeHash := session.GetCodeHashString()
+
+	// Try each share that appears until one completes the handshake; after
+	// that its result is final
+	var path string
+	var recvErr, lastErr error
+	err = lan.Browse(ctx, func(share lan.Share) bool {
+		for _, addr := range share.Addrs {
+			var dialer net.Dialer
+			dctx, cancel := context.WithTimeout(ctx, directDialTimeout)
+			conn, err := dialer.DialContext(dctx, "tcp", addr)
+			cancel()
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			if lastErr = c.lanHandshake(ctx, conn, codePhrase, pake.RoleReceiver); lastErr != nil {
+				conn.Close()
+				return false // Another share, or a wrong code: keep looking
+			}
+			defer c.disconnect()
+
+			msg, err := c.receiveMessage(ctx)
+			if err != nil {
+				recvErr = err
+				return true
+			}
+			// Decrypt, write and acknowledge
+			path, recvErr = c.receiveContent(codeHash, msg, outputDir)
+			return true
+		}
+		return false
+	})
+	if err != nil {
+		if errors.Is(err, lan.ErrNotFound) && lastErr != nil {
+			return "", fmt.Errorf("%w (last attempt: %v)", err, lastErr)
+		}
+		return "", err
+	}
+	return path, recvErr
+}
+
+// lanHandshake runs PAKE over conn, then each side proves it derived the
+// same key (PAKE alone doesn't confirm it): the receiver sends a hello and
+// the sender answers with ready, so a receiver that reached someone else's
+// share finds out at once. On success conn becomes the client's connection.
+func (c *Client) lanHandshake(ctx context.Context, conn net.Conn, codePhrase string, role pake.Role) error {
+	ctx, cancel := context.WithTimeout(ctx, lanHandshakeTimeout)
+	defer cancel()
+
+	session, err := pake.NewSession(codePhrase, role)
+	if err != nil {
+		return err
+	}
+	token := session.GetCodeHash()
+
+	t := newTCPTransport(conn)
+	c.connMu.Lock()
+	c.conn = t
+	c.connMu.Unlock()
+
+	isSender := role == pake.RoleSender
+	if c.sessionKey, err = c.performPakeExchange(ctx, session, isSender); err != nil {
+		return err
+	}
+
+	if isSender {
+		msg, err := t.Receive(ctx, lanHandshakeTimeout)
+		if err != nil {
+			return err
+		}
+		if msg.Type != protocol.MsgDirectHello || !c.checkDirect(msg, helloPrefix, token) {
+			return errors.New("peer failed key confirmation")
+		}
+		if err := c.sendDirectOn(t, protocol.MsgDirectReady, readyPrefix, token); err != nil {
+			return err
+		}
+	} else {
+		if err := c.sendDirectOn(t, protocol.MsgDirectHello, helloPrefix, token); err != nil {
+			return err
+		}
+		msg, err := t.Receive(ctx, lanHandshakeTimeout)
+		if err != nil {
+			return err
+		}
+		if msg.Type != protocol.MsgDirectReady || !c.checkDirect(msg, readyPrefix, token) {
+			return errors.New("share did not accept the code")
+		}
+	}
+
+	t.conn.SetDeadline(time.Time{})
+	t.limit = maxFrameSize // The peer has proven the session key
+	c.direct = true
+	return nil
+}
@@ -0,0 +1,116 @@
+package client
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"github.com/epuerta9/claw2claw/internal/protocol"
+	"github.com/gorilla/websocket"
+)
+
+// Frame limits for direct connections. Until the peer has proven the session
+// key only small handshake frames are accepted.
+const (
+	maxHandshakeFrame = 64 << 10
+	maxFrameSize      = 1 << 30
+)
+
+// transport carries protocol messages to the peer, through the relay or a
+// direct connection
+type transport interface {
+	Send(msg *protocol.Message) error
+	Receive(ctx context.Context, timeout time.Duration) (*protocol.Message, error)
+	Close() error
+}
+
+// wsTransport sends messages as WebSocket text frames via the relay
+type wsTransport struct {
+	conn *websocket.Conn
+}
+
+func (t *wsTransport) Send(msg *protocol.Message) error {
+	data, err := msg.Encode()
+	if err != nil {
+		return err
+	}
+	return t.conn.WriteMessage(websocket.TextMessage, data)
+}
+
+func (t *wsTransport) Receive(ctx context.Context, timeout time.Duration) (*protocol.Message, error) {
+	// Set read deadline based on context
+	if deadline, ok := ctx.Deadline(); ok {
+		t.conn.SetReadDeadline(deadline)
+	} else {
+		t.conn.SetReadDeadline(time.Now().Add(timeout))
+	}
+
+	_, data, err := t.conn.ReadMessage()
+	if err != nil {
+		if err == io.EOF {
+			return nil, ErrNotConnected
+		}
+		return nil, err
+	}
+
+	return protocol.DecodeMessage(data)
+}
+
+func (t *wsTransport) Close() error {
+	return t.conn.Close()
+}
+
+// tcpTransport sends messages over a direct TCP connection, each as a 4-byte
+// big-endian length followed by the JSON message
+type tcpTransport struct {
+	conn  net.Conn
+	limit uint32 // Largest frame accepted
+}
+
+func newTCPTransport(conn net.Conn) *tcpTransport {
+	return &tcpTransport{conn: conn, limit: maxHandshakeFrame}
+}
+
+func (t *tcpTransport) Send(msg *protocol.Message) error {
+	data, err := msg.Encode()
+	if err != nil {
+		return err
+	}
+	if len(data) > maxFrameSize {
+		return fmt.Errorf("message too large for direct connection: %d bytes", len(data))
+	}
+	frame := make([]byte, 4+len(data))
+	binary.BigEndian.PutUint32(frame, uint32(len(data)))
+	copy(frame[4:], data)
+	_, err = t.conn.Write(frame)
+	return err
+}
+
+func (t *tcpTransport) Receive(ctx context.Context, timeout time.Duration) (*protocol.Message, error) {
+	if deadline, ok := ctx.Deadline(); ok {
+		t.conn.SetReadDeadline(deadline)
+	} else {
+		t.conn.SetReadDeadline(time.Now().Add(timeout))
+	}
+
+	var header [4]byte
+	if _, err := io.ReadFull(t.conn, header[:]); err != nil {
+		return nil, err
+	}
+	size := binary.BigEndian.Uint32(header[:])
+	if size > t.limit {
+		return nil, fmt.Errorf("direct connection frame too large: %d bytes", size)
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(t.conn, data); err != nil {
+		return nil, err
+	}
+	return protocol.DecodeMessage(data)
+}
+
+func (t *tcpTransport) Close() error {
+	return t.conn.Close()
+}
@@ -0,0 +1,95 @@
+package crypto
+
+import (
+	"crypto/ecdh"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+// keyWrapInfo binds wrapped keys to this protocol version
+const keyWrapInfo = "claw2claw key wrap v1"
+
+var ErrInvalidPublicKey = errors.New("invalid public key")
+
+// GenerateKeyPair creates an X25519 key pair for receiving wrapped keys
+func GenerateKeyPair() (privateKey, publicKey []byte, err error) {
+	priv, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	return priv.Bytes(), priv.PublicKey().Bytes(), nil
+}
+
+// PublicKey returns the X25519 public key for a private key
+func PublicKey(privateKey []byte) ([]byte, error) {
+	priv, err := ecdh.X25519().NewPrivateKey(privateKey)
+	if err != nil {
+		return nil, ErrInvalidKey
+	}
+	return priv.PublicKey().Bytes(), nil
+}
+
+// WrapKey encrypts key to a recipient's X25519 public key using an ephemeral
+// key pair (ECIES: X25519 + HKDF-SHA256 + AES-256-GCM)
+func WrapKey(recipientPublicKey, key []byte) (ephemeralPublicKey, wrapped []byte, err error) {
+	recipient, err := ecdh.X25519().NewPublicKey(recipientPublicKey)
+	if err != nil {
+		return nil, nil, ErrInvalidPublicKey
+	}
+	ephemeral, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	shared, err := ephemeral.ECDH(recipient)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ephemeralPublicKey = ephemeral.PublicKey().Bytes()
+	kek, err := DeriveKey(shared, append(append([]byte{}, ephemeralPublicKey...), recipientPublicKey...), keyWrapInfo)
+	if err != nil {
+		return nil, nil, err
+	}
+	wrapped, err = Encrypt(kek, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ephemeralPublicKey, wrapped, nil
+}
+
+// UnwrapKey recovers a key wrapped with WrapKey
+func UnwrapKey(privateKey, ephemeralPublicKey, wrapped []byte) ([]byte, error) {
+	priv, err := ecdh.X25519().NewPrivateKey(privateKey)
+	if err != nil {
+		return nil, ErrInvalidKey
+	}
+	ephemeral, err := ecdh.X25519().NewPublicKey(ephemeralPublicKey)
+	if err != nil {
+		return nil, ErrInvalidPublicKey
+	}
+	shared, err := priv.ECDH(ephemeral)
+	if err != nil {
+		return nil, err
+	}
+
+	salt := append(append([]byte{}, ephemeralPublicKey...), priv.PublicKey().Bytes()...)
+	kek, err := DeriveKey(shared, salt, keyWrapInfo)
+	if err != nil {
+		return nil, err
+	}
+	return Decrypt(kek, wrapped)
+}
+
+// Fingerprint returns a short, human-comparable digest of a public key
+func Fingerprint(publicKey []byte) string {
+	sum := sha256.Sum256(publicKey)
+	h := hex.EncodeToString(sum[:10])
+	var groups []string
+	for i := 0; i < len(h); i += 4 {
+		groups = append(groups, h[i:i+4])
+	}
+	return strings.Join(groups, " ")
+}
@@ -0,0 +1,79 @@
+package crypto
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWrapKeyRoundTrip(t *testing.T) {
+	priv, pub, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := PublicKey(priv); err != nil || !bytes.Equal(got, pub) {
+		t.Fatalf("PublicKey() = %x, %v, want %x", got, err, pub)
+	}
+
+	key, _ := GenerateRandom(KeySize)
+	ephemeral, wrapped, err := WrapKey(pub, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := UnwrapKey(priv, ephemeral, wrapped)
+	if err != nil {
+		t.Fatalf("UnwrapKey() error: %v", err)
+	}
+	if !bytes.Equal(got, key) {
+		t.Errorf("UnwrapKey() = %x, want %x", got, key)
+	}
+}
+
+func TestUnwrapKeyRejects(t *testing.T) {
+	priv, pub, _ := GenerateKeyPair()
+	otherPriv, _, _ := GenerateKeyPair()
+	key, _ := GenerateRandom(KeySize)
+	ephemeral, wrapped, err := WrapKey(pub, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, otherEphemeral, _ := GenerateKeyPair()
+
+	flip := func(b []byte, i int) []byte {
+		b = append([]byte{}, b...)
+		b[i] ^= 0x01
+		return b
+	}
+	tests := []struct {
+		name      string
+		priv      []byte
+		ephemeral []byte
+		wrapped   []byte
+	}{
+		{"wrong private key", otherPriv, ephemeral, wrapped},
+		{"substituted ephemeral key", priv, otherEphemeral, wrapped},
+		{"tampered wrapped key", priv, ephemeral, flip(wrapped, len(wrapped)-1)},
+		{"tampered nonce", priv, ephemeral, flip(wrapped, 0)},
+		{"truncated wrapped key", priv, ephemeral, wrapped[:8]},
+	}
+	for _, tt := range tests {
+		if got, err := UnwrapKey(tt.priv, tt.ephemeral, tt.wrapped); err == nil {
+			t.Errorf("%s: UnwrapKey() = %x, want error", tt.name, got)
+		}
+	}
+
+	if _, _, err := WrapKey([]byte("short"), key); err != ErrInvalidPublicKey {
+		t.Errorf("WrapKey(invalid key) error = %v, want ErrInvalidPublicKey", err)
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	_, pub, _ := GenerateKeyPair()
+	_, other, _ := GenerateKeyPair()
+	fp := Fingerprint(pub)
+	if len(fp) != 24 || fp != Fingerprint(pub) { // 5 groups of 4 hex digits
+		t.Errorf("Fingerprint() = %q, want a stable 5x4 digest", fp)
+	}
+	if fp == Fingerprint(other) {
+		t.Errorf("different keys share fingerprint %q", fp)
+	}
+}
@@ -0,0 +1,133 @@
+package crypto
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"sort"
+)
+
+// SealedMagic prefixes content sealed to a set of recipients
+const SealedMagic = "CLAWSEALED1\n"
+
+// maxSealedHeader bounds the recipient header of a sealed blob
+const maxSealedHeader = 1 << 20
+
+var ErrNotRecipient = errors.New("not a recipient of this content")
+
+// WrappedKey is a content key wrapped to one recipient's public key
+type WrappedKey struct {
+	Recipient string `json:"recipient"`
+	Ephemeral []byte `json:"ephemeral"`
+	Key       []byte `json:"key"`
+}
+
+type sealedHeader struct {
+	Recipients []WrappedKey `json:"recipients"`
+}
+
+// Seal encrypts plaintext with a random content key and wraps that key to each
+// recipient's public key. Layout: magic || header length (uint32) || header JSON || ciphertext
+func Seal(plaintext []byte, recipients map[string][]byte) ([]byte, error) {
+	if len(recipients) == 0 {
+		return nil, errors.New("no recipients")
+	}
+	contentKey, err := GenerateRandom(KeySize)
+	if err != nil {
+		return nil, err
+	}
+
+	var header sealedHeader
+	for id, pub := range recipients {
+		ephemeral, wrapped, err := WrapKey(pub, contentKey)
+		if err != nil {
+			return nil, err
+		}
+		header.Recipients = append(header.Recipients, WrappedKey{Recipient: id, Ephemeral: ephemeral, Key: wrapped})
+	}
+	sort.Slice(header.Recipients, func(i, j int) bool {
+		return header.Recipients[i].Recipient < header.Recipients[j].Recipient
+	})
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertext, err := Encrypt(contentKey, plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(SealedMagic)
+	binary.Write(&buf, binary.BigEndian, uint32(len(headerJSON)))
+	buf.Write(headerJSON)
+	buf.Write(ciphertext)
+	return buf.Bytes(), nil
+}
+
+// IsSealed reports whether data was produced by Seal
+func IsSealed(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(SealedMagic))
+}
+
+// SealedRecipients lists the recipient IDs of sealed content
+func SealedRecipients(data []byte) ([]string, error) {
+	header, _, err := parseSealed(data)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, r := range header.Recipients {
+		ids = append(ids, r.Recipient)
+	}
+	return ids, nil
+}
+
+// Open decrypts sealed content with a recipient's private key. The entry for
+// recipient is tried first, then any other entry (IDs may differ per server).
+func Open(data []byte, recipient string, privateKey []byte) ([]byte, error) {
+	header, ciphertext, err := parseSealed(data)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]WrappedKey, 0, len(header.Recipients))
+	for _, r := range header.Recipients {
+		if r.Recipient == recipient {
+			entries = append([]WrappedKey{r}, entries...)
+		} else {
+			entries = append(entries, r)
+		}
+	}
+	for _, r := range entries {
+		contentKey, err := UnwrapKey(privateKey, r.Ephemeral, r.Key)
+		if err != nil {
+			continue
+		}
+		return Decrypt(contentKey, ciphertext)
+	}
+	return nil, ErrNotRecipient
+}
+
+func parseSealed(data []byte) (*sealedHeader, []byte, error) {
+	if !IsSealed(data) {
+		return nil, nil, errors.New("not sealed content")
+	}
+	rest := data[len(SealedMagic):]
+	if len(rest) < 4 {
+		return nil, nil, errors.New("truncated sealed content")
+	}
+	n := binary.BigEndian.Uint32(rest)
+	rest = rest[4:]
+	if n > maxSealedHeader || int(n) > len(rest) {
+		return nil, nil, errors.New("invalid sealed header")
+	}
+
+	var header sealedHeader
+	if err := json.Unmarshal(rest[:n], &header); err != nil {
+		return nil, nil, errors.New("invalid sealed header")
+	}
+	return &header, rest[n:], nil
+}
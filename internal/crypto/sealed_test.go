@@ -0,0 +1,120 @@
+package crypto
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type keyPair struct{ priv, pub []byte }
+
+func newKeyPairs(t *testing.T, n int) []keyPair {
+	t.Helper()
+	pairs := make([]keyPair, n)
+	for i := range pairs {
+		priv, pub, err := GenerateKeyPair()
+		if err != nil {
+			t.Fatal(err)
+		}
+		pairs[i] = keyPair{priv, pub}
+	}
+	return pairs
+}
+
+func TestSealOpenRoundTrip(t *testing.T) {
+	keys := newKeyPairs(t, 2)
+	plaintext := []byte("deploy notes for the team")
+	sealed, err := Seal(plaintext, map[string][]byte{"alice": keys[0].pub, "bob": keys[1].pub})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsSealed(sealed) {
+		t.Fatal("IsSealed() = false for sealed content")
+	}
+	if bytes.Contains(sealed, plaintext) {
+		t.Error("sealed content contains the plaintext")
+	}
+
+	ids, err := SealedRecipients(sealed)
+	if err != nil || !reflect.DeepEqual(ids, []string{"alice", "bob"}) {
+		t.Errorf("SealedRecipients() = %v, %v, want [alice bob]", ids, err)
+	}
+
+	for i, id := range []string{"alice", "bob"} {
+		got, err := Open(sealed, id, keys[i].priv)
+		if err != nil {
+			t.Fatalf("Open(%s) error: %v", id, err)
+		}
+		if !bytes.Equal(got, plaintext) {
+			t.Errorf("Open(%s) = %q, want %q", id, got, plaintext)
+		}
+	}
+
+	// A recipient listed under another ID still opens it
+	if got, err := Open(sealed, "bob@other-server", keys[1].priv); err != nil || !bytes.Equal(got, plaintext) {
+		t.Errorf("Open(unknown ID) = %q, %v, want the plaintext", got, err)
+	}
+}
+
+func TestOpenNonRecipient(t *testing.T) {
+	keys := newKeyPairs(t, 2)
+	sealed, err := Seal([]byte("secret"), map[string][]byte{"alice": keys[0].pub})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(sealed, "alice", keys[1].priv); !errors.Is(err, ErrNotRecipient) {
+		t.Errorf("Open(non-recipient) error = %v, want ErrNotRecipient", err)
+	}
+}
+
+func TestOpenRejectsTampering(t *testing.T) {
+	keys := newKeyPairs(t, 1)
+	sealed, err := Seal([]byte("secret"), map[string][]byte{"alice": keys[0].pub})
+	if err != nil {
+		t.Fatal(err)
+	}
+	headerLen := int(binary.BigEndian.Uint32(sealed[len(SealedMagic):]))
+	headerStart := len(SealedMagic) + 4
+	headerEnd := headerStart + headerLen
+
+	tamper := func(f func(b []byte) []byte) []byte {
+		return f(append([]byte{}, sealed...))
+	}
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"ciphertext bit flipped", tamper(func(b []byte) []byte { b[len(b)-1] ^= 0x01; return b })},
+		{"ciphertext truncated", sealed[:headerEnd+4]},
+		{"wrapped key altered", tamper(func(b []byte) []byte {
+			// Base64 inside the JSON header: swap a character of the last field
+			i := bytes.LastIndex(b[:headerEnd], []byte(`"}`)) - 2
+			if b[i] == 'A' {
+				b[i] = 'B'
+			} else {
+				b[i] = 'A'
+			}
+			return b
+		})},
+		{"header not JSON", tamper(func(b []byte) []byte { b[headerStart] = '['; return b })},
+		{"header length too large", tamper(func(b []byte) []byte {
+			binary.BigEndian.PutUint32(b[len(SealedMagic):], uint32(len(b)))
+			return b
+		})},
+		{"magic missing", sealed[1:]},
+		{"truncated before header", sealed[:len(SealedMagic)+2]},
+	}
+	for _, tt := range tests {
+		if got, err := Open(tt.data, "alice", keys[0].priv); err == nil {
+			t.Errorf("%s: Open() = %q, want error", tt.name, got)
+		}
+	}
+}
+
+func TestSealNoRecipients(t *testing.T) {
+	if _, err := Seal([]byte("x"), nil); err == nil {
+		t.Error("Seal() with no recipients succeeded")
+	}
+}
@@ -0,0 +1,222 @@
+// Package diff provides line-based diffs and unified diff rendering for board content
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Op is the kind of a diff edit
+type Op int
+
+const (
+	Equal Op = iota
+	Delete
+	Insert
+)
+
+// Edit is a single line of a diff
+type Edit struct {
+	Op   Op
+	Line string
+	A    int // Line index in a (Equal, Delete), -1 otherwise
+	B    int // Line index in b (Equal, Insert), -1 otherwise
+}
+
+// Lines splits text into lines without their trailing newlines
+func Lines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// Compute returns the shortest edit script turning a into b (Myers' algorithm)
+func Compute(a, b []string) []Edit {
+	n, m := len(a), len(b)
+	max := n + m
+	if max == 0 {
+		return nil
+	}
+
+	offset := max
+	v := make([]int, 2*max+2)
+	var trace [][]int
+
+	for d := 0; d <= max; d++ {
+		snapshot := make([]int, len(v))
+		copy(snapshot, v)
+		trace = append(trace, snapshot)
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1] // Move down (insert)
+			} else {
+				x = v[offset+k-1] + 1 // Move right (delete)
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrack(a, b, trace, offset)
+			}
+		}
+	}
+	return nil
+}
+
+// backtrack walks the Myers trace from the end to recover the edit script
+func backtrack(a, b []string, trace [][]int, offset int) []Edit {
+	x, y := len(a), len(b)
+	var edits []Edit
+
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			edits = append(edits, Edit{Op: Equal, Line: a[x], A: x, B: y})
+		}
+		if d > 0 {
+			if x == prevX {
+				y--
+				edits = append(edits, Edit{Op: Insert, Line: b[y], A: -1, B: y})
+			} else {
+				x--
+				edits = append(edits, Edit{Op: Delete, Line: a[x], A: x, B: -1})
+			}
+		}
+	}
+
+	// Reverse into forward order
+	for i, j := 0, len(edits)-1; i < j; i, j = i+1, j-1 {
+		edits[i], edits[j] = edits[j], edits[i]
+	}
+	return edits
+}
+
+// Stats counts inserted and deleted lines in an edit script
+func Stats(edits []Edit) (added, removed int) {
+	for _, e := range edits {
+		switch e.Op {
+		case Insert:
+			added++
+		case Delete:
+			removed++
+		}
+	}
+	return added, removed
+}
+
+// Unified renders a unified diff between two texts with the given lines of context.
+// Returns an empty string when the texts are identical.
+func Unified(aName, bName, aText, bText string, context int) string {
+	a, b := Lines(aText), Lines(bText)
+	edits := Compute(a, b)
+
+	changed := false
+	for _, e := range edits {
+		if e.Op != Equal {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("--- %s\n+++ %s\n", aName, bName))
+
+	for _, h := range hunks(edits, context) {
+		sb.WriteString(hunkHeader(edits, h[0], h[1]))
+		for _, e := range edits[h[0]:h[1]] {
+			switch e.Op {
+			case Equal:
+				sb.WriteString(" " + e.Line + "\n")
+			case Delete:
+				sb.WriteString("-" + e.Line + "\n")
+			case Insert:
+				sb.WriteString("+" + e.Line + "\n")
+			}
+		}
+	}
+	return sb.String()
+}
+
+// hunks groups changes into [start, end) ranges of edits, each surrounded
+// by up to context unchanged lines. Nearby changes share a hunk.
+func hunks(edits []Edit, context int) [][2]int {
+	var result [][2]int
+	for i, e := range edits {
+		if e.Op == Equal {
+			continue
+		}
+		start := i - context
+		if start < 0 {
+			start = 0
+		}
+		end := i + 1 + context
+		if end > len(edits) {
+			end = len(edits)
+		}
+		if n := len(result); n > 0 && start <= result[n-1][1] {
+			result[n-1][1] = end
+		} else {
+			result = append(result, [2]int{start, end})
+		}
+	}
+	return result
+}
+
+// hunkHeader formats the @@ line for edits[start:end]
+func hunkHeader(edits []Edit, start, end int) string {
+	aStart, bStart := 0, 0
+	for _, e := range edits[:start] {
+		if e.Op != Insert {
+			aStart++
+		}
+		if e.Op != Delete {
+			bStart++
+		}
+	}
+	aLen, bLen := 0, 0
+	for _, e := range edits[start:end] {
+		if e.Op != Insert {
+			aLen++
+		}
+		if e.Op != Delete {
+			bLen++
+		}
+	}
+	// Non-empty ranges are 1-based; empty ranges point at the line before
+	if aLen > 0 {
+		aStart++
+	}
+	if bLen > 0 {
+		bStart++
+	}
+	return fmt.Sprintf("@@ -%s +%s @@\n", formatRange(aStart, aLen), formatRange(bStart, bLen))
+}
+
+func formatRange(start, length int) string {
+	if length == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, length)
+}
@@ -0,0 +1,105 @@
+package diff
+
+import "strings"
+
+// Conflict markers written into merged text
+const (
+	MarkerOurs   = "<<<<<<<"
+	MarkerSep    = "======="
+	MarkerTheirs = ">>>>>>>"
+)
+
+// Merge3 performs a line-based three-way merge of ours and theirs against
+// their common ancestor base. Changes made on only one side are taken
+// automatically; overlapping changes are written with conflict markers.
+// Returns the merged text and the number of conflicts.
+func Merge3(base, ours, theirs, oursLabel, theirsLabel string) (string, int) {
+	b, o, t := Lines(base), Lines(ours), Lines(theirs)
+	oMatch := matches(b, o)
+	tMatch := matches(b, t)
+
+	var out []string
+	conflicts := 0
+	i, oi, ti := 0, 0, 0
+
+	for i < len(b) || oi < len(o) || ti < len(t) {
+		// Next base line left unchanged by both sides
+		j := i
+		for j < len(b) && (oMatch[j] < 0 || tMatch[j] < 0) {
+			j++
+		}
+
+		if j == i && i < len(b) && oMatch[i] == oi && tMatch[i] == ti {
+			out = append(out, b[i])
+			i, oi, ti = i+1, oi+1, ti+1
+			continue
+		}
+
+		// Unstable chunk up to the next stable line (or the end)
+		oEnd, tEnd := len(o), len(t)
+		if j < len(b) {
+			oEnd, tEnd = oMatch[j], tMatch[j]
+		}
+		bChunk, oChunk, tChunk := b[i:j], o[oi:oEnd], t[ti:tEnd]
+
+		switch {
+		case equalLines(oChunk, bChunk):
+			out = append(out, tChunk...)
+		case equalLines(tChunk, bChunk), equalLines(oChunk, tChunk):
+			out = append(out, oChunk...)
+		default:
+			conflicts++
+			out = append(out, MarkerOurs+" "+oursLabel)
+			out = append(out, oChunk...)
+			out = append(out, MarkerSep)
+			out = append(out, tChunk...)
+			out = append(out, MarkerTheirs+" "+theirsLabel)
+		}
+		i, oi, ti = j, oEnd, tEnd
+	}
+
+	if len(out) == 0 {
+		return "", conflicts
+	}
+	return strings.Join(out, "\n") + "\n", conflicts
+}
+
+// HasConflictMarkers reports whether text still contains unresolved merge markers
+func HasConflictMarkers(text string) bool {
+	hasOurs, hasTheirs := false, false
+	for _, line := range Lines(text) {
+		if strings.HasPrefix(line, MarkerOurs+" ") {
+			hasOurs = true
+		}
+		if strings.HasPrefix(line, MarkerTheirs+" ") {
+			hasTheirs = true
+		}
+	}
+	return hasOurs && hasTheirs
+}
+
+// matches maps each base line index to its index in other, or -1 if changed
+func matches(base, other []string) []int {
+	m := make([]int, len(base))
+	for i := range m {
+		m[i] = -1
+	}
+	for _, e := range Compute(base, other) {
+		if e.Op == Equal {
+			m[e.A] = e.B
+		}
+	}
+	return m
+}
+
+func equalLines(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,74 @@
+package diff
+
+import "testing"
+
+func TestMerge3(t *testing.T) {
+	const base = "a\nb\nc\nd\ne\n"
+	tests := []struct {
+		name          string
+		ours, theirs  string
+		want          string
+		wantConflicts int
+	}{
+		{
+			name: "unchanged",
+			ours: base, theirs: base,
+			want: base,
+		},
+		{
+			name: "only ours",
+			ours: "a\nB\nc\nd\ne\n", theirs: base,
+			want: "a\nB\nc\nd\ne\n",
+		},
+		{
+			name: "only theirs",
+			ours: base, theirs: "a\nb\nc\nD\ne\n",
+			want: "a\nb\nc\nD\ne\n",
+		},
+		{
+			name: "separate edits",
+			ours: "a\nB\nc\nd\ne\n", theirs: "a\nb\nc\nD\ne\n",
+			want: "a\nB\nc\nD\ne\n",
+		},
+		{
+			name: "both append",
+			ours: base + "ours\n", theirs: "start\n" + base,
+			want: "start\n" + base + "ours\n",
+		},
+		{
+			name: "same edit on both sides",
+			ours: "a\nB\nc\nd\ne\n", theirs: "a\nB\nc\nd\ne\n",
+			want: "a\nB\nc\nd\ne\n",
+		},
+		{
+			// Like diff3, edits with no unchanged line between them conflict
+			name: "adjacent lines",
+			ours: "a\nB\nc\nd\ne\n", theirs: "a\nb\nC\nd\ne\n",
+			want:          "a\n<<<<<<< ours\nB\nc\n=======\nb\nC\n>>>>>>> theirs\nd\ne\n",
+			wantConflicts: 1,
+		},
+		{
+			name: "overlapping edits",
+			ours: "a\nours\nc\nd\ne\n", theirs: "a\ntheirs\nc\nd\ne\n",
+			want:          "a\n<<<<<<< ours\nours\n=======\ntheirs\n>>>>>>> theirs\nc\nd\ne\n",
+			wantConflicts: 1,
+		},
+		{
+			name: "ours deletes what theirs edits",
+			ours: "a\nc\nd\ne\n", theirs: "a\nB\nc\nd\ne\n",
+			want:          "a\n<<<<<<< ours\n=======\nB\n>>>>>>> theirs\nc\nd\ne\n",
+			wantConflicts: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, conflicts := Merge3(base, tt.ours, tt.theirs, "ours", "theirs")
+			if got != tt.want || conflicts != tt.wantConflicts {
+				t.Errorf("Merge3 = %q (%d conflicts), want %q (%d)", got, conflicts, tt.want, tt.wantConflicts)
+			}
+			if HasConflictMarkers(got) != (conflicts > 0) {
+				t.Errorf("HasConflictMarkers = %v with %d conflicts", !(conflicts > 0), conflicts)
+			}
+		})
+	}
+}
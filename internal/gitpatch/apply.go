@@ -0,0 +1,150 @@
+package gitpatch
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ApplyOptions controls where a patch is applied
+type ApplyOptions struct {
+	Branch string // New branch name (default claw/<branch>-<base>)
+	Dir    string // Worktree directory (default <git dir>/claw/worktrees/<branch>)
+}
+
+// ApplyResult describes an applied patch
+type ApplyResult struct {
+	Branch    string
+	Worktree  string
+	Committed bool     // False when conflicts or a commit failure left changes uncommitted
+	Conflicts []string // Files with conflict markers to resolve in the worktree
+}
+
+// MissingBaseError is returned when the repository lacks the patch's base commit
+type MissingBaseError struct {
+	Base   string
+	Remote string
+}
+
+func (e *MissingBaseError) Error() string {
+	msg := fmt.Sprintf("base commit %s is not in this repository", ShortHash(e.Base))
+	if e.Remote != "" {
+		msg += fmt.Sprintf(" (fetch it from %s)", e.Remote)
+	} else {
+		msg += " (try git fetch)"
+	}
+	return msg
+}
+
+// Apply applies the patch with git apply --3way on a new branch, checked out
+// in its own worktree so the user's checkout is never touched
+func Apply(r *Repo, p *Patch, opts ApplyOptions) (*ApplyResult, error) {
+	if !r.HasCommit(p.Base) {
+		return nil, &MissingBaseError{Base: p.Base, Remote: p.Remote}
+	}
+
+	branch := opts.Branch
+	if branch == "" {
+		branch = r.uniqueBranch(p.DefaultBranch())
+	} else if r.branchExists(branch) {
+		return nil, fmt.Errorf("branch %s already exists", branch)
+	}
+	if _, err := r.git("check-ref-format", "--branch", branch); err != nil {
+		return nil, fmt.Errorf("invalid branch name: %s", branch)
+	}
+
+	dir := opts.Dir
+	if dir == "" {
+		gitDir, err := r.gitDir()
+		if err != nil {
+			return nil, err
+		}
+		dir = filepath.Join(gitDir, "claw", "worktrees", strings.ReplaceAll(branch, "/", "-"))
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(dir); err == nil {
+		return nil, fmt.Errorf("%s already exists", dir)
+	}
+
+	if _, err := r.git("worktree", "add", "--quiet", "-b", branch, dir, p.Base); err != nil {
+		return nil, fmt.Errorf("failed to create worktree: %w", err)
+	}
+	result := &ApplyResult{Branch: branch, Worktree: dir}
+	wt := &Repo{Dir: dir}
+
+	if _, applyErr := wt.gitRaw([]byte(p.Diff), "apply", "--3way", "--whitespace=nowarn", "-"); applyErr != nil {
+		conflicts, _ := wt.git("diff", "--name-only", "--diff-filter=U")
+		if conflicts == "" {
+			// Nothing was applied: drop the worktree and branch again
+			r.git("worktree", "remove", "--force", dir)
+			r.git("branch", "-D", branch)
+			return nil, fmt.Errorf("patch does not apply: %w", applyErr)
+		}
+		result.Conflicts = strings.Split(conflicts, "\n")
+		return result, nil
+	}
+
+	if _, err := wt.git("commit", "--quiet", "--no-verify", "-m", p.CommitMessage()); err == nil {
+		result.Committed = true
+	}
+	return result, nil
+}
+
+// uniqueBranch returns name, or name-2, name-3... if it's taken
+func (r *Repo) uniqueBranch(name string) string {
+	candidate := name
+	for n := 2; r.branchExists(candidate); n++ {
+		candidate = fmt.Sprintf("%s-%d", name, n)
+	}
+	return candidate
+}
+
+// DefaultBranch names the branch a patch is applied on
+func (p *Patch) DefaultBranch() string {
+	name := p.Branch
+	if name == "" {
+		name = "patch"
+	}
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.', r == '/':
+			return r
+		}
+		return '-'
+	}, name)
+	name = strings.Trim(strings.ReplaceAll(name, "..", "."), "/.-")
+	if name == "" {
+		name = "patch"
+	}
+	return fmt.Sprintf("claw/%s-%s", name, ShortHash(p.Base)[:7])
+}
+
+// CommitMessage describes the applied patch, listing the original commits
+func (p *Patch) CommitMessage() string {
+	var sb strings.Builder
+	switch {
+	case len(p.Commits) == 1:
+		sb.WriteString(p.Commits[0].Subject)
+	case p.Branch != "":
+		fmt.Fprintf(&sb, "Apply %s changes from %s", p.Mode, p.Branch)
+	default:
+		fmt.Fprintf(&sb, "Apply %s changes", p.Mode)
+	}
+	sb.WriteString("\n\n")
+	if p.Author != "" {
+		fmt.Fprintf(&sb, "Received via claw2claw from %s.\n", p.Author)
+	} else {
+		sb.WriteString("Received via claw2claw.\n")
+	}
+	if len(p.Commits) > 1 {
+		sb.WriteString("\n")
+		for _, c := range p.Commits {
+			fmt.Fprintf(&sb, "%s %s (%s)\n", ShortHash(c.Hash), c.Subject, c.Author)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,85 @@
+package gitpatch
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Repo runs git commands in a working tree
+type Repo struct {
+	Dir string
+}
+
+// OpenRepo finds the repository containing dir
+func OpenRepo(dir string) (*Repo, error) {
+	r := &Repo{Dir: dir}
+	top, err := r.git("rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, fmt.Errorf("not a git repository: %s", dir)
+	}
+	return &Repo{Dir: top}, nil
+}
+
+// git runs a command and returns its trimmed stdout
+func (r *Repo) git(args ...string) (string, error) {
+	out, err := r.gitRaw(nil, args...)
+	return strings.TrimSpace(string(out)), err
+}
+
+// gitRaw runs a command with optional stdin and returns stdout untouched.
+// Errors include git's stderr.
+func (r *Repo) gitRaw(stdin []byte, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = r.Dir
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return out, fmt.Errorf("git %s: %s", args[0], msg)
+	}
+	return out, nil
+}
+
+// resolve returns the full commit hash of rev
+func (r *Repo) resolve(rev string) (string, error) {
+	hash, err := r.git("rev-parse", "--verify", "--quiet", rev+"^{commit}")
+	if err != nil || hash == "" {
+		return "", fmt.Errorf("unknown revision: %s", rev)
+	}
+	return hash, nil
+}
+
+// HasCommit reports whether the repository has the commit
+func (r *Repo) HasCommit(hash string) bool {
+	_, err := r.git("cat-file", "-e", hash+"^{commit}")
+	return err == nil
+}
+
+// currentBranch returns the checked-out branch, or "" when detached
+func (r *Repo) currentBranch() string {
+	branch, err := r.git("symbolic-ref", "--short", "-q", "HEAD")
+	if err != nil {
+		return ""
+	}
+	return branch
+}
+
+// branchExists reports whether a local branch exists
+func (r *Repo) branchExists(name string) bool {
+	_, err := r.git("show-ref", "--verify", "--quiet", "refs/heads/"+name)
+	return err == nil
+}
+
+// gitDir returns the repository's common .git directory, shared by worktrees
+func (r *Repo) gitDir() (string, error) {
+	return r.git("rev-parse", "--path-format=absolute", "--git-common-dir")
+}
@@ -0,0 +1,287 @@
+// Package gitpatch packages git work in progress (a commit range, staged
+// changes or the whole working tree) as a patch bundle with the base commit
+// and branch it applies to, and applies received bundles on a new branch
+package gitpatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Kind identifies a patch bundle file
+const Kind = "claw2claw/gitpatch"
+
+// Ext is the file extension patch bundles are sent with
+const Ext = ".clawpatch"
+
+// Modes: what a bundle was made from
+const (
+	ModeRange    = "range"    // Commits between two revisions
+	ModeStaged   = "staged"   // Staged changes against HEAD
+	ModeWorktree = "worktree" // Staged and unstaged changes against HEAD
+)
+
+// Patch is a diff with the context needed to apply it
+type Patch struct {
+	Kind      string    `json:"kind"`
+	Version   int       `json:"version"`
+	Mode      string    `json:"mode"`
+	Range     string    `json:"range,omitempty"` // As given, for range mode
+	Base      string    `json:"base"`            // Commit the diff applies to
+	Head      string    `json:"head,omitempty"`  // Last commit, for range mode
+	Branch    string    `json:"branch,omitempty"`
+	Remote    string    `json:"remote,omitempty"`
+	Author    string    `json:"author,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	Commits   []Commit  `json:"commits,omitempty"`
+	Files     []Change  `json:"files"`
+	Untracked []string  `json:"untracked,omitempty"` // Untracked files left out of a worktree patch
+	Diff      string    `json:"diff"`
+}
+
+// Commit is a commit included in a range patch
+type Commit struct {
+	Hash    string `json:"hash"`
+	Subject string `json:"subject"`
+	Author  string `json:"author"`
+}
+
+// Change is one file's line counts in the diff (-1 for binary files)
+type Change struct {
+	Path    string `json:"path"`
+	Added   int    `json:"added"`
+	Removed int    `json:"removed"`
+}
+
+// Options selects what to package
+type Options struct {
+	Mode  string
+	Range string // For ModeRange: "A..B", "A.." or "A" (A..HEAD)
+}
+
+// Create builds a patch bundle from the repository
+func Create(r *Repo, opts Options) (*Patch, error) {
+	p := &Patch{Kind: Kind, Version: 1, Mode: opts.Mode, CreatedAt: time.Now()}
+	p.Branch = r.currentBranch()
+	if remote, err := r.git("remote", "get-url", "origin"); err == nil {
+		p.Remote = stripCredentials(remote)
+	}
+	p.Author, _ = r.git("config", "user.name")
+
+	var diffArgs []string
+	switch opts.Mode {
+	case ModeRange:
+		from, to, ok := strings.Cut(opts.Range, "..")
+		if strings.HasPrefix(to, ".") {
+			return nil, fmt.Errorf("symmetric ranges (A...B) aren't supported, use A..B")
+		}
+		if !ok || to == "" {
+			to = "HEAD"
+		}
+		base, err := r.resolve(from)
+		if err != nil {
+			return nil, err
+		}
+		head, err := r.resolve(to)
+		if err != nil {
+			return nil, err
+		}
+		p.Range, p.Base, p.Head = opts.Range, base, head
+		if p.Commits, err = r.commits(base, head); err != nil {
+			return nil, err
+		}
+		diffArgs = []string{base, head}
+
+	case ModeStaged, ModeWorktree:
+		base, err := r.resolve("HEAD")
+		if err != nil {
+			return nil, fmt.Errorf("repository has no commits yet")
+		}
+		p.Base = base
+		if opts.Mode == ModeStaged {
+			diffArgs = []string{"--cached", base}
+		} else {
+			diffArgs = []string{base}
+			if out, err := r.git("ls-files", "--others", "--exclude-standard"); err == nil && out != "" {
+				p.Untracked = strings.Split(out, "\n")
+			}
+		}
+
+	default:
+		return nil, fmt.Errorf("unknown patch mode %q", opts.Mode)
+	}
+
+	diff, err := r.gitRaw(nil, append([]string{"diff", "--binary", "--no-color", "--no-ext-diff"}, diffArgs...)...)
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(diff)) == 0 {
+		return nil, fmt.Errorf("no changes to send")
+	}
+	p.Diff = string(diff)
+
+	numstat, err := r.git(append([]string{"diff", "--numstat", "--no-color", "--no-ext-diff"}, diffArgs...)...)
+	if err != nil {
+		return nil, err
+	}
+	p.Files = parseNumstat(numstat)
+	return p, nil
+}
+
+// commits lists the commits in base..head, oldest first
+func (r *Repo) commits(base, head string) ([]Commit, error) {
+	out, err := r.git("log", "--reverse", "--format=%H%x1f%s%x1f%an", base+".."+head)
+	if err != nil {
+		return nil, err
+	}
+	var commits []Commit
+	for _, line := range strings.Split(out, "\n") {
+		parts := strings.Split(line, "\x1f")
+		if len(parts) == 3 {
+			commits = append(commits, Commit{Hash: parts[0], Subject: parts[1], Author: parts[2]})
+		}
+	}
+	return commits, nil
+}
+
+func parseNumstat(out string) []Change {
+	var changes []Change
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		c := Change{Path: fields[2], Added: -1, Removed: -1}
+		fmt.Sscanf(fields[0], "%d", &c.Added)
+		fmt.Sscanf(fields[1], "%d", &c.Removed)
+		changes = append(changes, c)
+	}
+	return changes
+}
+
+// Marshal encodes the bundle for sending
+func (p *Patch) Marshal() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(p); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Parse decodes a patch bundle. ok is false if data isn't one.
+func Parse(data []byte) (p *Patch, ok bool) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return nil, false
+	}
+	if err := json.Unmarshal(trimmed, &p); err != nil || p.Kind != Kind || !isHash(p.Base) {
+		return nil, false
+	}
+	return p, true
+}
+
+// isHash reports whether s is a full SHA-1 or SHA-256 commit hash
+func isHash(s string) bool {
+	if len(s) != 40 && len(s) != 64 {
+		return false
+	}
+	for _, c := range s {
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// FileName names a bundle after its branch and base
+func (p *Patch) FileName() string {
+	name := p.Branch
+	if name == "" {
+		name = "patch"
+	}
+	name = strings.NewReplacer("/", "-", "\\", "-", " ", "-").Replace(name)
+	return fmt.Sprintf("%s-%s-%s%s", name, p.Mode, ShortHash(p.Base), Ext)
+}
+
+// Summary describes the bundle on one line
+func (p *Patch) Summary() string {
+	added, removed := 0, 0
+	for _, f := range p.Files {
+		if f.Added > 0 {
+			added += f.Added
+		}
+		if f.Removed > 0 {
+			removed += f.Removed
+		}
+	}
+	desc := fmt.Sprintf("%s changes", p.Mode)
+	if p.Mode == ModeRange {
+		desc = fmt.Sprintf("%d commit(s) %s", len(p.Commits), p.Range)
+	}
+	return fmt.Sprintf("%s on %s, %d file(s) +%d -%d", desc, ShortHash(p.Base), len(p.Files), added, removed)
+}
+
+// Render presents the bundle for review: metadata, then the diff
+func (p *Patch) Render() string {
+	var sb strings.Builder
+	sb.WriteString("# Git Patch\n\n")
+	fmt.Fprintf(&sb, "Change: %s\n", p.Summary())
+	if p.Author != "" {
+		fmt.Fprintf(&sb, "Author: %s\n", p.Author)
+	}
+	if p.Branch != "" {
+		fmt.Fprintf(&sb, "Branch: %s\n", p.Branch)
+	}
+	if p.Remote != "" {
+		fmt.Fprintf(&sb, "Remote: %s\n", p.Remote)
+	}
+	fmt.Fprintf(&sb, "Base commit: %s\n", p.Base)
+	fmt.Fprintf(&sb, "Created: %s\n", p.CreatedAt.Format("2006-01-02 15:04"))
+
+	if len(p.Commits) > 0 {
+		sb.WriteString("\nCommits:\n")
+		for _, c := range p.Commits {
+			fmt.Fprintf(&sb, "  %s %s (%s)\n", ShortHash(c.Hash), c.Subject, c.Author)
+		}
+	}
+	sb.WriteString("\nFiles:\n")
+	for _, f := range p.Files {
+		if f.Added < 0 {
+			fmt.Fprintf(&sb, "  %s (binary)\n", f.Path)
+		} else {
+			fmt.Fprintf(&sb, "  %s +%d -%d\n", f.Path, f.Added, f.Removed)
+		}
+	}
+	if len(p.Untracked) > 0 {
+		fmt.Fprintf(&sb, "  (%d untracked file(s) not included)\n", len(p.Untracked))
+	}
+	sb.WriteString("\n")
+	sb.WriteString(p.Diff)
+	return sb.String()
+}
+
+// ShortHash abbreviates a commit hash
+func ShortHash(hash string) string {
+	if len(hash) > 10 {
+		return hash[:10]
+	}
+	return hash
+}
+
+// stripCredentials drops user info from a URL-style remote; scp-style
+// remotes (git@host:path) carry no password and are kept as they are
+func stripCredentials(remote string) string {
+	u, err := url.Parse(remote)
+	if err != nil || u.User == nil || u.Host == "" {
+		return remote
+	}
+	u.User = nil
+	return u.String()
+}
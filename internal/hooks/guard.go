@@ -0,0 +1,437 @@
+package hooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const guardConfigFile = ".claw/guard.json"
+
+// DefaultGuardPaths are the directories holding untrusted content
+var DefaultGuardPaths = []string{".claw/received", ".claw/channels", ".claw/shared"}
+
+// DefaultGuardAllow are commands that may touch guarded paths: claw reads
+// content through safereader, and listing a directory doesn't expose content
+var DefaultGuardAllow = []string{"claw", "c2c", "ls"}
+
+// GuardConfig selects what claw guard protects. Paths are relative to the
+// project (or absolute) and may use glob patterns, e.g. ".claw/channels/*".
+type GuardConfig struct {
+	Paths []string `json:"paths,omitempty"`
+	Allow []string `json:"allow_commands,omitempty"`
+}
+
+// GuardInput is the PreToolUse hook payload sent by Claude Code
+type GuardInput struct {
+	SessionID     string          `json:"session_id,omitempty"`
+	HookEventName string          `json:"hook_event_name"`
+	ToolName      string          `json:"tool_name"`
+	ToolInput     json.RawMessage `json:"tool_input"`
+	Cwd           string          `json:"cwd"`
+}
+
+// GuardOutput is the hook response that denies a tool call
+type GuardOutput struct {
+	HookSpecificOutput GuardDecision `json:"hookSpecificOutput"`
+}
+
+// GuardDecision is a PreToolUse permission decision
+type GuardDecision struct {
+	HookEventName            string `json:"hookEventName"`
+	PermissionDecision       string `json:"permissionDecision"`
+	PermissionDecisionReason string `json:"permissionDecisionReason"`
+}
+
+// LoadGuardConfig merges ~/.claw/guard.json with the project's
+// .claw/guard.json. A project can guard more paths and narrow the allowed
+// commands, but not loosen the user's guard: a cloned repository's config
+// is as untrusted as the content it protects.
+func LoadGuardConfig(projectDir string) (*GuardConfig, error) {
+	home, _ := os.UserHomeDir()
+	user, err := readGuardConfig(filepath.Join(home, guardConfigFile))
+	if err != nil {
+		return nil, err
+	}
+	project, err := readGuardConfig(filepath.Join(projectDir, guardConfigFile))
+	if err != nil {
+		return nil, err
+	}
+	return MergeGuardConfigs(user, project), nil
+}
+
+// readGuardConfig reads a guard config without applying defaults (nil if missing)
+func readGuardConfig(path string) (*GuardConfig, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	cfg := &GuardConfig{}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("invalid guard config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// ParseGuardConfig parses a guard config, applying defaults
+func ParseGuardConfig(data []byte) (*GuardConfig, error) {
+	cfg := &GuardConfig{}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, cfg); err != nil {
+			return nil, fmt.Errorf("invalid guard config: %w", err)
+		}
+	}
+	return MergeGuardConfigs(cfg, nil), nil
+}
+
+// MergeGuardConfigs combines the user's config with a project's (either may
+// be nil), applying defaults: the project's paths are guarded in addition
+// to the user's, and a project allow list only keeps commands the user
+// allows too.
+func MergeGuardConfigs(user, project *GuardConfig) *GuardConfig {
+	cfg := &GuardConfig{Paths: DefaultGuardPaths, Allow: DefaultGuardAllow}
+	if user != nil {
+		if len(user.Paths) > 0 {
+			cfg.Paths = user.Paths
+		}
+		if user.Allow != nil {
+			cfg.Allow = user.Allow
+		}
+	}
+	if project == nil {
+		return cfg
+	}
+
+	paths := append([]string{}, cfg.Paths...)
+	for _, p := range project.Paths {
+		if !containsString(paths, p) {
+			paths = append(paths, p)
+		}
+	}
+	cfg.Paths = paths
+
+	if project.Allow != nil {
+		allow := []string{}
+		for _, a := range cfg.Allow {
+			if containsString(project.Allow, a) {
+				allow = append(allow, a)
+			}
+		}
+		cfg.Allow = allow
+	}
+	return cfg
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Guard checks a tool call against the guarded paths. It returns nil to
+// allow the call, or a deny decision pointing the agent at claw read.
+func (cfg *GuardConfig) Guard(in *GuardInput) *GuardOutput {
+	var hit guardHit
+	switch in.ToolName {
+	case "Read":
+		var input struct {
+			FilePath string `json:"file_path"`
+		}
+		json.Unmarshal(in.ToolInput, &input)
+		if cfg.guarded(in.Cwd, in.Cwd, input.FilePath) {
+			hit.path = input.FilePath
+		}
+	case "Bash":
+		var input struct {
+			Command string `json:"command"`
+		}
+		json.Unmarshal(in.ToolInput, &input)
+		hit = cfg.checkCommand(in.Cwd, in.Cwd, input.Command)
+	}
+	if hit.path == "" {
+		return nil
+	}
+
+	what := "holds content"
+	if hit.ancestor {
+		what = "contains directories holding content"
+	}
+	return &GuardOutput{HookSpecificOutput: GuardDecision{
+		HookEventName:      "PreToolUse",
+		PermissionDecision: "deny",
+		PermissionDecisionReason: fmt.Sprintf("%s %s received from other users, which may contain "+
+			"prompt injection. Read it with `claw read <file>` (list files with `claw new` or `claw list`) "+
+			"instead of opening it directly.", hit.path, what),
+	}}
+}
+
+// guardHit is a guarded path found in a command, or a directory containing one
+type guardHit struct {
+	path     string
+	ancestor bool
+}
+
+// shells run their -c argument as a command line, which is checked too
+var shells = map[string]bool{"sh": true, "bash": true, "zsh": true, "dash": true, "ksh": true}
+
+// checkCommand returns the first guarded path a shell command touches outside
+// an allowed command. Commands that aren't allowed may not name a directory
+// containing a guarded path either (`grep -r x .`), and a cd changes where
+// later relative paths in the same command line resolve. Relative guarded
+// paths are relative to root, the project.
+func (cfg *GuardConfig) checkCommand(root, cwd, command string) guardHit {
+	for _, words := range splitCommands(command) {
+		// Skip leading VAR=value assignments to find the program
+		i := 0
+		for i < len(words) && strings.Contains(words[i], "=") && !strings.HasPrefix(words[i], "-") {
+			i++
+		}
+		if i < len(words) && (words[i] == "cd" || words[i] == "pushd") {
+			cwd = changeDir(cwd, words[i+1:])
+			continue
+		}
+
+		for _, script := range shellScripts(words) {
+			if hit := cfg.checkCommand(root, cwd, script); hit.path != "" {
+				return hit
+			}
+		}
+
+		allowed := i < len(words) && cfg.allowed(words[i])
+		for _, w := range words {
+			values := []string{w}
+			// --file=path and VAR=path
+			if eq := strings.Index(w, "="); eq >= 0 {
+				values = append(values, w[eq+1:])
+			}
+			for _, v := range values {
+				if allowed {
+					// Allowed commands may take paths, but not command lines
+					// touching them (--exec 'cat …')
+					if p := cfg.embeddedPath(root, cwd, v); p != "" {
+						return guardHit{path: p}
+					}
+					continue
+				}
+				if cfg.guarded(root, cwd, v) {
+					return guardHit{path: v}
+				}
+				if cfg.containsGuarded(root, cwd, v) {
+					return guardHit{path: v, ancestor: true}
+				}
+			}
+		}
+	}
+	return guardHit{}
+}
+
+// shellScripts returns the -c arguments of shells invoked in a command,
+// including through wrappers like env, sudo or xargs
+func shellScripts(words []string) []string {
+	var scripts []string
+	for j, w := range words {
+		if !shells[filepath.Base(w)] {
+			continue
+		}
+		for k := j + 1; k < len(words) && strings.HasPrefix(words[k], "-"); k++ {
+			if !strings.HasPrefix(words[k], "--") && strings.Contains(words[k], "c") && k+1 < len(words) {
+				scripts = append(scripts, words[k+1])
+				break
+			}
+		}
+	}
+	return scripts
+}
+
+// embeddedPath finds a guarded path inside an argument that is itself a
+// command line. Arguments that are just paths with spaces don't count.
+func (cfg *GuardConfig) embeddedPath(root, cwd, arg string) string {
+	if !strings.ContainsAny(arg, " \t\n") || cfg.guarded(root, cwd, arg) {
+		return ""
+	}
+	for _, words := range splitCommands(arg) {
+		for _, w := range words {
+			if cfg.guarded(root, cwd, w) {
+				return w
+			}
+		}
+	}
+	return ""
+}
+
+// changeDir follows cd's arguments from cwd
+func changeDir(cwd string, args []string) string {
+	for _, a := range args {
+		if a == "--" || (strings.HasPrefix(a, "-") && a != "-") {
+			continue
+		}
+		if a == "-" {
+			return cwd // Previous directory: unknown, stay put
+		}
+		return resolvePath(cwd, a)
+	}
+	home, _ := os.UserHomeDir()
+	return home
+}
+
+func (cfg *GuardConfig) allowed(program string) bool {
+	name := filepath.Base(program)
+	for _, a := range cfg.Allow {
+		if name == a {
+			return true
+		}
+	}
+	return false
+}
+
+// resolvePath makes path absolute against cwd, expanding ~/
+func resolvePath(cwd, path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		home, _ := os.UserHomeDir()
+		path = filepath.Join(home, strings.TrimPrefix(path[1:], "/"))
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(cwd, path)
+	}
+	return filepath.Clean(path)
+}
+
+// containsGuarded reports whether path is a directory above a guarded path,
+// such as the project root or .claw
+func (cfg *GuardConfig) containsGuarded(root, cwd, path string) bool {
+	if path == "" || strings.HasPrefix(path, "-") {
+		return false
+	}
+	path = resolvePath(cwd, path)
+	for _, pattern := range cfg.patterns(root) {
+		if within(pattern, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// guarded reports whether path (relative to cwd) is, or is inside, a
+// guarded path
+func (cfg *GuardConfig) guarded(root, cwd, path string) bool {
+	if path == "" {
+		return false
+	}
+	path = resolvePath(cwd, path)
+	for _, pattern := range cfg.patterns(root) {
+		if within(path, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// patterns returns the guarded paths made absolute against the project root
+func (cfg *GuardConfig) patterns(root string) []string {
+	patterns := make([]string, len(cfg.Paths))
+	for i, pattern := range cfg.Paths {
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(root, pattern)
+		}
+		patterns[i] = filepath.Clean(pattern)
+	}
+	return patterns
+}
+
+// within reports whether path lies inside pattern. Both may contain glob
+// characters and are compared segment by segment, so `cat .claw/rec*/x`
+// matches .claw/received and ".claw/channels/*" matches every channel.
+func within(path, pattern string) bool {
+	sep := string(filepath.Separator)
+	ps := strings.Split(path, sep)
+	gs := strings.Split(pattern, sep)
+	if len(ps) < len(gs) {
+		return false
+	}
+	for i, g := range gs {
+		if ps[i] == g {
+			continue
+		}
+		if ok, _ := filepath.Match(g, ps[i]); ok {
+			continue
+		}
+		if ok, _ := filepath.Match(ps[i], g); ok {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// splitCommands splits a shell command line into simple commands (on ;, &,
+// |, newlines and subshell parentheses) and each of those into words,
+// honouring quotes and backslash escapes. It is a guardrail, not a shell parser.
+func splitCommands(command string) [][]string {
+	var commands [][]string
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+
+	endWord := func() {
+		if inWord {
+			words = append(words, word.String())
+			word.Reset()
+			inWord = false
+		}
+	}
+	endCommand := func() {
+		endWord()
+		if len(words) > 0 {
+			commands = append(commands, words)
+			words = nil
+		}
+	}
+
+	runes := []rune(command)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else if r == '\\' && quote == '"' && i+1 < len(runes) {
+				i++
+				word.WriteRune(runes[i])
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == '\\' && i+1 < len(runes):
+			i++
+			word.WriteRune(runes[i])
+			inWord = true
+		case r == '&' && i > 0 && (runes[i-1] == '>' || runes[i-1] == '<'):
+			// 2>&1 and <&3 are redirections, not command separators
+		case r == ';' || r == '&' || r == '|' || r == '\n' || r == '(' || r == ')' || r == '`':
+			endCommand()
+		case r == '$' && i+1 < len(runes) && runes[i+1] == '(':
+			endCommand()
+			i++
+		case r == '<' || r == '>':
+			endWord() // Redirection targets are words of the same command
+		case r == ' ' || r == '\t':
+			endWord()
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	endCommand()
+	return commands
+}
@@ -0,0 +1,123 @@
+package hooks
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestGuard runs each case in testdata/guard: input.json is the hook payload,
+// output.json the expected response (absent means the call is allowed) and
+// guard.json an optional config.
+func TestGuard(t *testing.T) {
+	dirs, err := filepath.Glob(filepath.Join("testdata", "guard", "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dirs) == 0 {
+		t.Fatal("no guard test cases found")
+	}
+
+	for _, dir := range dirs {
+		t.Run(filepath.Base(dir), func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join(dir, "input.json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var in GuardInput
+			if err := json.Unmarshal(data, &in); err != nil {
+				t.Fatalf("invalid input.json: %v", err)
+			}
+
+			configData, err := os.ReadFile(filepath.Join(dir, "guard.json"))
+			if err != nil && !os.IsNotExist(err) {
+				t.Fatal(err)
+			}
+			cfg, err := ParseGuardConfig(configData)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got any
+			if out := cfg.Guard(&in); out != nil {
+				got = roundTrip(t, out)
+			}
+
+			var want any
+			if data, err := os.ReadFile(filepath.Join(dir, "output.json")); err == nil {
+				if err := json.Unmarshal(data, &want); err != nil {
+					t.Fatalf("invalid output.json: %v", err)
+				}
+			} else if !os.IsNotExist(err) {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(got, want) {
+				gotJSON, _ := json.MarshalIndent(got, "", "  ")
+				wantJSON, _ := json.MarshalIndent(want, "", "  ")
+				t.Errorf("output mismatch\ngot:  %s\nwant: %s", gotJSON, wantJSON)
+			}
+		})
+	}
+}
+
+// roundTrip converts v to its generic JSON form for comparison
+func roundTrip(t *testing.T, v any) any {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out any
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestParseGuardConfigDefaults(t *testing.T) {
+	cfg, err := ParseGuardConfig([]byte(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cfg.Paths, DefaultGuardPaths) {
+		t.Errorf("paths = %v, want %v", cfg.Paths, DefaultGuardPaths)
+	}
+	if !reflect.DeepEqual(cfg.Allow, DefaultGuardAllow) {
+		t.Errorf("allow = %v, want %v", cfg.Allow, DefaultGuardAllow)
+	}
+
+	if _, err := ParseGuardConfig([]byte(`{"paths": "nope"}`)); err == nil {
+		t.Error("expected error for invalid config")
+	}
+}
+
+func TestMergeGuardConfigs(t *testing.T) {
+	user := &GuardConfig{Paths: []string{"downloads"}, Allow: []string{"claw", "ls", "cat"}}
+
+	// A project can't empty the guard or allow more than the user does
+	project := &GuardConfig{Paths: []string{"vendor/untrusted"}, Allow: []string{"claw", "grep"}}
+	cfg := MergeGuardConfigs(user, project)
+	if want := []string{"downloads", "vendor/untrusted"}; !reflect.DeepEqual(cfg.Paths, want) {
+		t.Errorf("paths = %v, want %v", cfg.Paths, want)
+	}
+	if want := []string{"claw"}; !reflect.DeepEqual(cfg.Allow, want) {
+		t.Errorf("allow = %v, want %v", cfg.Allow, want)
+	}
+
+	cfg = MergeGuardConfigs(nil, &GuardConfig{Paths: []string{}, Allow: []string{"cat", "ls"}})
+	if !reflect.DeepEqual(cfg.Paths, DefaultGuardPaths) {
+		t.Errorf("paths = %v, want defaults %v", cfg.Paths, DefaultGuardPaths)
+	}
+	if want := []string{"ls"}; !reflect.DeepEqual(cfg.Allow, want) {
+		t.Errorf("allow = %v, want %v", cfg.Allow, want)
+	}
+
+	// Without a project config the user's settings apply as they are
+	cfg = MergeGuardConfigs(user, nil)
+	if !reflect.DeepEqual(cfg.Paths, user.Paths) || !reflect.DeepEqual(cfg.Allow, user.Allow) {
+		t.Errorf("got %+v, want %+v", cfg, user)
+	}
+}
@@ -0,0 +1,437 @@
+package hooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/epuerta9/claw2claw/internal/diff"
+)
+
+// Commands registered in Claude Code settings
+const (
+	InboxHookCommand = "claw inbox --quiet --if-stale 30m"
+	GuardHookCommand = "claw guard"
+	GuardHookMatcher = "Bash|Read"
+
+	legacyInboxHookCommand = "c2c inbox --quiet --if-stale 30m"
+)
+
+const installRecordFile = ".claw/install.json"
+
+// DefaultHooks are the hooks claw install registers in settings.json
+var DefaultHooks = []SettingsHook{
+	{Event: "SessionStart", Command: SessionStartCommand},
+	{Event: "UserPromptSubmit", Command: InboxHookCommand},
+	{Event: "PreToolUse", Matcher: GuardHookMatcher, Command: GuardHookCommand},
+}
+
+// legacyHooks were registered by earlier versions and are replaced on install
+var legacyHooks = []SettingsHook{
+	{Event: "UserPromptSubmit", Command: legacyInboxHookCommand},
+}
+
+// slashHooks are the /share and /receive entries in ~/.claude/hooks.json
+var slashHooks = []HookEntry{
+	{Matcher: "/share", Hooks: []string{"claw share-hook"}},
+	{Matcher: "/receive", Hooks: []string{"claw receive-hook"}},
+}
+
+// Target is a Claude Code config directory hooks are installed into
+type Target struct {
+	Dir     string // Directory holding settings.json
+	Project bool   // A project's .claude/ rather than ~/.claude/
+}
+
+// UserTarget is ~/.claude
+func UserTarget() (*Target, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	return &Target{Dir: filepath.Join(home, ".claude")}, nil
+}
+
+// ProjectTarget is the .claude directory of a project
+func ProjectTarget(projectDir string) (*Target, error) {
+	abs, err := filepath.Abs(projectDir)
+	if err != nil {
+		return nil, err
+	}
+	return &Target{Dir: filepath.Join(abs, ".claude"), Project: true}, nil
+}
+
+// SettingsPath is the settings.json hooks are registered in
+func (t *Target) SettingsPath() string {
+	return filepath.Join(t.Dir, "settings.json")
+}
+
+// slashHooksPath is hooks.json, which only exists for the user target
+func (t *Target) slashHooksPath() string {
+	return filepath.Join(t.Dir, "hooks.json")
+}
+
+// InstallRecord lists what claw install added to a target, so uninstall
+// removes exactly that and leaves hooks the user set up alone
+type InstallRecord struct {
+	Dir         string         `json:"dir"`
+	InstalledAt time.Time      `json:"installed_at"`
+	Hooks       []SettingsHook `json:"hooks,omitempty"`
+	SlashHooks  []HookEntry    `json:"slash_hooks,omitempty"` // Entries added to hooks.json
+	Created     []string       `json:"created,omitempty"`     // Files install created, removed again once empty
+}
+
+func (r *InstallRecord) created(path string) bool {
+	for _, p := range r.Created {
+		if p == path {
+			return true
+		}
+	}
+	return false
+}
+
+// noteCreated records that c creates its file
+func (r *InstallRecord) noteCreated(c *FileChange) {
+	if c != nil && c.Before == nil && !r.created(c.Path) {
+		r.Created = append(r.Created, c.Path)
+	}
+}
+
+func (r *InstallRecord) hasHook(h SettingsHook) bool {
+	for _, rh := range r.Hooks {
+		if rh == h {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *InstallRecord) hasSlashHook(matcher string) bool {
+	for _, e := range r.SlashHooks {
+		if e.Matcher == matcher {
+			return true
+		}
+	}
+	return false
+}
+
+// installRecords is ~/.claw/install.json, keyed by target directory
+type installRecords struct {
+	Targets map[string]*InstallRecord `json:"targets"`
+}
+
+// GetInstallRecordPath returns the path of the install record
+func GetInstallRecordPath() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, installRecordFile)
+}
+
+func loadInstallRecords() (*installRecords, error) {
+	records := &installRecords{Targets: make(map[string]*InstallRecord)}
+	data, err := os.ReadFile(GetInstallRecordPath())
+	if os.IsNotExist(err) {
+		return records, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, records); err != nil {
+		return nil, fmt.Errorf("invalid install record %s: %w", GetInstallRecordPath(), err)
+	}
+	if records.Targets == nil {
+		records.Targets = make(map[string]*InstallRecord)
+	}
+	return records, nil
+}
+
+func (r *installRecords) save() error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := GetInstallRecordPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// FileChange is a pending rewrite of a config file
+type FileChange struct {
+	Path   string
+	Before []byte // nil if the file doesn't exist yet
+	After  []byte // nil to delete the file
+}
+
+// Diff renders the change as a unified diff
+func (c *FileChange) Diff() string {
+	aName, bName := c.Path, c.Path
+	if c.Before == nil {
+		aName = "/dev/null"
+	}
+	if c.After == nil {
+		bName = "/dev/null"
+	}
+	return diff.Unified(aName, bName, string(c.Before), string(c.After), 3)
+}
+
+// Plan is the set of edits an install or uninstall makes. Nothing is
+// written until Apply, so a plan doubles as a dry run.
+type Plan struct {
+	Target   *Target
+	Changes  []*FileChange
+	Added    []SettingsHook // Hooks registered by this plan
+	Removed  []SettingsHook // Hooks removed (ours, or legacy ones being replaced)
+	Present  []SettingsHook // Hooks that were already registered
+	NoRecord bool           // Uninstall without a record: removed the known claw hooks
+
+	record *InstallRecord // Record to save after applying, nil to delete it
+}
+
+// PlanInstall works out how to register the claw hooks in t. Hooks that are
+// already there are left alone and, unless an earlier install added them,
+// are not recorded as ours.
+func PlanInstall(t *Target) (*Plan, error) {
+	records, err := loadInstallRecords()
+	if err != nil {
+		return nil, err
+	}
+	record := records.Targets[t.Dir]
+	if record == nil {
+		record = &InstallRecord{Dir: t.Dir}
+	}
+
+	sf, err := loadSettingsFile(t.SettingsPath())
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &Plan{Target: t, record: record}
+	for _, h := range legacyHooks {
+		if sf.remove(h) {
+			plan.Removed = append(plan.Removed, h)
+		}
+	}
+	for _, h := range DefaultHooks {
+		if sf.has(h) {
+			plan.Present = append(plan.Present, h)
+			continue
+		}
+		sf.add(h)
+		plan.Added = append(plan.Added, h)
+		if !record.hasHook(h) {
+			record.Hooks = append(record.Hooks, h)
+		}
+	}
+	change, err := sf.change()
+	if err := plan.addChange(change, err); err != nil {
+		return nil, err
+	}
+	record.noteCreated(change)
+
+	// The /share and /receive hooks live in ~/.claude/hooks.json
+	if !t.Project {
+		hf, err := loadSlashHooksFile(t.slashHooksPath())
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range slashHooks {
+			if hf.has(e.Matcher) {
+				continue
+			}
+			hf.add(e)
+			if !record.hasSlashHook(e.Matcher) {
+				record.SlashHooks = append(record.SlashHooks, e)
+			}
+		}
+		change, err := hf.change()
+		if err := plan.addChange(change, err); err != nil {
+			return nil, err
+		}
+		record.noteCreated(change)
+	}
+
+	return plan, nil
+}
+
+// PlanUninstall works out how to remove what claw install added to t. With
+// no install record (hooks installed by an older version) it removes the
+// hooks claw registers by default.
+func PlanUninstall(t *Target) (*Plan, error) {
+	records, err := loadInstallRecords()
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &Plan{Target: t}
+	record := records.Targets[t.Dir]
+	if record == nil {
+		plan.NoRecord = true
+		record = &InstallRecord{Dir: t.Dir, Hooks: append(append([]SettingsHook{}, DefaultHooks...), legacyHooks...)}
+		if !t.Project {
+			record.SlashHooks = slashHooks
+		}
+	}
+
+	sf, err := loadSettingsFile(t.SettingsPath())
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range record.Hooks {
+		if sf.remove(h) {
+			plan.Removed = append(plan.Removed, h)
+		}
+	}
+	change, err := sf.change()
+	if change != nil && sf.empty() && record.created(change.Path) {
+		change.After = nil
+	}
+	if err := plan.addChange(change, err); err != nil {
+		return nil, err
+	}
+
+	if len(record.SlashHooks) > 0 {
+		hf, err := loadSlashHooksFile(t.slashHooksPath())
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range record.SlashHooks {
+			hf.remove(e)
+		}
+		change, err := hf.change()
+		if change != nil && len(hf.config.Hooks) == 0 && record.created(change.Path) {
+			change.After = nil
+		}
+		if err := plan.addChange(change, err); err != nil {
+			return nil, err
+		}
+	}
+
+	return plan, nil
+}
+
+func (p *Plan) addChange(c *FileChange, err error) error {
+	if err != nil {
+		return err
+	}
+	if c != nil {
+		p.Changes = append(p.Changes, c)
+	}
+	return nil
+}
+
+// Diff renders every pending change
+func (p *Plan) Diff() string {
+	var sb strings.Builder
+	for _, c := range p.Changes {
+		sb.WriteString(c.Diff())
+	}
+	return sb.String()
+}
+
+// Apply writes the changes, backing up each existing file first as
+// <file>.bak-<timestamp>, and updates the install record. Returns the
+// backup paths.
+func (p *Plan) Apply(now time.Time) ([]string, error) {
+	var backups []string
+	for _, c := range p.Changes {
+		// Never write over a file that changed since the plan was made
+		current, err := os.ReadFile(c.Path)
+		if err != nil && !os.IsNotExist(err) {
+			return backups, err
+		}
+		if string(current) != string(c.Before) {
+			return backups, fmt.Errorf("%s changed while planning, run the command again", c.Path)
+		}
+
+		if c.Before != nil {
+			backup, err := backupFile(c.Path, c.Before, now)
+			if err != nil {
+				return backups, fmt.Errorf("failed to back up %s: %w", c.Path, err)
+			}
+			backups = append(backups, backup)
+		}
+		if c.After == nil {
+			if err := os.Remove(c.Path); err != nil {
+				return backups, fmt.Errorf("failed to remove %s: %w", c.Path, err)
+			}
+			continue
+		}
+		if err := writeFileAtomic(c.Path, c.After); err != nil {
+			return backups, fmt.Errorf("failed to write %s: %w", c.Path, err)
+		}
+	}
+
+	records, err := loadInstallRecords()
+	if err != nil {
+		return backups, err
+	}
+	if p.record != nil {
+		p.record.InstalledAt = now
+		records.Targets[p.Target.Dir] = p.record
+	} else {
+		delete(records.Targets, p.Target.Dir)
+	}
+	if err := records.save(); err != nil {
+		return backups, fmt.Errorf("failed to save install record: %w", err)
+	}
+	return backups, nil
+}
+
+// backupFile copies data next to path with a timestamp suffix, keeping the
+// file's permissions
+func backupFile(path string, data []byte, now time.Time) (string, error) {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	stamp := now.Format("20060102-150405")
+	backup := fmt.Sprintf("%s.bak-%s", path, stamp)
+	for i := 1; ; i++ {
+		f, err := os.OpenFile(backup, os.O_WRONLY|os.O_CREATE|os.O_EXCL, mode)
+		if os.IsExist(err) {
+			backup = fmt.Sprintf("%s.bak-%s-%d", path, stamp, i)
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		if _, err := f.Write(data); err != nil {
+			f.Close()
+			return "", err
+		}
+		return backup, f.Close()
+	}
+}
+
+// writeFileAtomic replaces path via a temp file and rename, keeping the
+// existing file's permissions
+func writeFileAtomic(path string, data []byte) error {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,257 @@
+package hooks
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/epuerta9/claw2claw/internal/account"
+	"github.com/epuerta9/claw2claw/internal/inbox"
+	"github.com/epuerta9/claw2claw/internal/manifest"
+	"github.com/epuerta9/claw2claw/internal/safereader"
+)
+
+// SessionStartCommand is registered as the SessionStart hook
+const SessionStartCommand = "claw hook session-start"
+
+// Limits keep the injected context compact
+const (
+	sessionMaxItems   = 10
+	sessionMaxPreview = 120
+)
+
+// SessionStartInput is the SessionStart hook payload sent by Claude Code
+type SessionStartInput struct {
+	SessionID     string `json:"session_id,omitempty"`
+	HookEventName string `json:"hook_event_name"`
+	Source        string `json:"source,omitempty"` // startup, resume, clear or compact
+	Cwd           string `json:"cwd"`
+}
+
+// SessionStartOutput is the hook response adding context to the session
+type SessionStartOutput struct {
+	HookSpecificOutput SessionContext `json:"hookSpecificOutput"`
+}
+
+// SessionContext carries the text Claude Code adds to the session
+type SessionContext struct {
+	HookEventName     string `json:"hookEventName"`
+	AdditionalContext string `json:"additionalContext"`
+}
+
+// SessionSummary is what happened since the last session: unread files in
+// the project, unread notifications and board changes
+type SessionSummary struct {
+	Unread        []*manifest.FileEntry
+	Updated       []*manifest.FileEntry
+	Notifications []account.Notification
+	Snoozed       int
+	BoardChanges  []account.BoardSection
+	InboxError    string // Why the inbox couldn't be checked, if it couldn't
+}
+
+// BuildSessionSummary collects unread files from m and, when cfg is a
+// logged-in team account, the inbox. The inbox check gives up after timeout
+// so a slow server never holds up session start.
+func BuildSessionSummary(m *manifest.Manifest, cfg *account.Config, timeout time.Duration) *SessionSummary {
+	s := &SessionSummary{}
+	if m != nil {
+		s.Unread = m.GetUnread()
+		s.Updated = m.GetUpdatedSinceRead()
+		sortEntries(s.Unread)
+		sortEntries(s.Updated)
+	}
+
+	if cfg == nil || !cfg.LoggedIn || cfg.TeamID == "" {
+		return s
+	}
+
+	type result struct {
+		summary *account.InboxSummary
+		err     error
+	}
+	done := make(chan result, 1)
+	go func() {
+		summary, err := account.PeekInbox(cfg) // Leaves claw inbox's checkpoint alone
+		done <- result{summary, err}
+	}()
+
+	select {
+	case r := <-done:
+		if r.err != nil {
+			s.InboxError = r.err.Error()
+			return s
+		}
+		s.BoardChanges = r.summary.BoardChanges
+		s.Notifications = r.summary.Notifications
+		if state, err := inbox.Load(cfg); err == nil {
+			state.Record(s.Notifications...)
+			state.Save()
+			s.Notifications, s.Snoozed = state.Select(s.Notifications, inbox.Filter{})
+		}
+	case <-time.After(timeout):
+		s.InboxError = "timed out"
+	}
+	return s
+}
+
+// Empty reports whether there is nothing to tell the session
+func (s *SessionSummary) Empty() bool {
+	return len(s.Unread) == 0 && len(s.Updated) == 0 && len(s.Notifications) == 0 &&
+		len(s.BoardChanges) == 0 && s.InboxError == ""
+}
+
+// Output builds the hook response, or nil if there is nothing to report.
+// File names, notifications and board text come from other users, so they
+// are wrapped in safereader markers; only the claw hints are outside.
+func (s *SessionSummary) Output() *SessionStartOutput {
+	if s.Empty() {
+		return nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString("claw2claw: activity since your last session.\n")
+
+	if body := s.Render(); body != "" {
+		sc := safereader.Scan("session-start", []byte(strings.TrimSuffix(body, "\n")), time.Now())
+		sb.WriteString(sc.FormatForClaude())
+	}
+
+	var hints []string
+	if len(s.Unread) > 0 || len(s.Updated) > 0 {
+		hints = append(hints, "Read files with `claw read <file>`, never by opening .claw/ paths directly.")
+	}
+	if len(s.Notifications) > 0 {
+		hints = append(hints, "Open a notification with `claw inbox read <id>`; reply with `claw inbox reply <id> <body>`.")
+	}
+	if len(s.BoardChanges) > 0 {
+		hints = append(hints, "See the full board with `claw board`.")
+	}
+	if s.InboxError != "" {
+		hints = append(hints, fmt.Sprintf("The team inbox could not be checked (%s); run `claw inbox` later.", s.InboxError))
+	}
+	for _, h := range hints {
+		sb.WriteString(h + "\n")
+	}
+
+	return &SessionStartOutput{HookSpecificOutput: SessionContext{
+		HookEventName:     "SessionStart",
+		AdditionalContext: sb.String(),
+	}}
+}
+
+// Render formats the untrusted part of the summary as compact text
+func (s *SessionSummary) Render() string {
+	var sb strings.Builder
+
+	if len(s.Unread) > 0 {
+		fmt.Fprintf(&sb, "Unread files (%d):\n", len(s.Unread))
+		for i, e := range s.Unread {
+			if i == sessionMaxItems {
+				fmt.Fprintf(&sb, "- ... and %d more (claw new)\n", len(s.Unread)-i)
+				break
+			}
+			sb.WriteString("- " + describeEntry(e) + "\n")
+		}
+	}
+
+	if len(s.Updated) > 0 {
+		fmt.Fprintf(&sb, "Updated since last read (%d):\n", len(s.Updated))
+		for i, e := range s.Updated {
+			if i == sessionMaxItems {
+				fmt.Fprintf(&sb, "- ... and %d more (claw new)\n", len(s.Updated)-i)
+				break
+			}
+			sb.WriteString("- " + describeEntry(e) + "\n")
+		}
+	}
+
+	if len(s.Notifications) > 0 {
+		fmt.Fprintf(&sb, "Unread notifications (%d", len(s.Notifications))
+		if s.Snoozed > 0 {
+			fmt.Fprintf(&sb, ", %d snoozed", s.Snoozed)
+		}
+		sb.WriteString("):\n")
+		for i, n := range s.Notifications {
+			if i == sessionMaxItems {
+				fmt.Fprintf(&sb, "- ... and %d more (claw inbox)\n", len(s.Notifications)-i)
+				break
+			}
+			fmt.Fprintf(&sb, "- [%s] %s from %s: %s", n.Type, shortID(n.ID), n.FromUser, oneLine(n.Subject))
+			if first := oneLine(n.Body); first != "" {
+				sb.WriteString(" — " + first)
+			}
+			if len(n.Attachments) > 0 {
+				fmt.Fprintf(&sb, " (%d attachment(s))", len(n.Attachments))
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	if len(s.BoardChanges) > 0 {
+		fmt.Fprintf(&sb, "Board changes (%d):\n", len(s.BoardChanges))
+		for i, bs := range s.BoardChanges {
+			if i == sessionMaxItems {
+				fmt.Fprintf(&sb, "- ... and %d more (claw board)\n", len(s.BoardChanges)-i)
+				break
+			}
+			fmt.Fprintf(&sb, "- [%s] updated by %s: %s\n", bs.Section, bs.UpdatedBy, preview(bs.Content))
+		}
+	}
+
+	return sb.String()
+}
+
+// describeEntry summarizes a received file on one line
+func describeEntry(e *manifest.FileEntry) string {
+	desc := e.Path
+	var details []string
+	if p := e.Provenance; p != nil {
+		if p.From != "" {
+			details = append(details, "from "+p.From)
+		}
+		details = append(details, "via "+p.Source)
+	} else if e.FromChannel != "" {
+		details = append(details, "via channel")
+	}
+	details = append(details, "received "+e.ReceivedAt.Format("2006-01-02 15:04"))
+	if e.Provenance != nil && len(e.Provenance.Warnings) > 0 {
+		details = append(details, fmt.Sprintf("%d injection warning(s)", len(e.Provenance.Warnings)))
+	}
+	return desc + " (" + strings.Join(details, ", ") + ")"
+}
+
+// oneLine returns the first non-empty line of s, truncated
+func oneLine(s string) string {
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if r := []rune(line); len(r) > sessionMaxPreview {
+			line = string(r[:sessionMaxPreview]) + "..."
+		}
+		return line
+	}
+	return ""
+}
+
+// preview collapses s onto one line, truncated
+func preview(s string) string {
+	return oneLine(strings.Join(strings.Fields(s), " "))
+}
+
+func shortID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
+
+// sortEntries orders files newest first
+func sortEntries(entries []*manifest.FileEntry) {
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].ReceivedAt.After(entries[j].ReceivedAt)
+	})
+}
@@ -0,0 +1,344 @@
+package hooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// SettingsHook is a command hook in a Claude Code settings file
+type SettingsHook struct {
+	Event   string `json:"event"`
+	Matcher string `json:"matcher,omitempty"`
+	Command string `json:"command"`
+}
+
+func (h SettingsHook) String() string {
+	if h.Matcher != "" {
+		return fmt.Sprintf("%s (%s) → %s", h.Event, h.Matcher, h.Command)
+	}
+	return fmt.Sprintf("%s → %s", h.Event, h.Command)
+}
+
+// settingsFile is a settings.json being edited. Only the "hooks" member is
+// re-encoded; the rest of the file is written back byte for byte.
+type settingsFile struct {
+	path    string
+	before  []byte // nil if the file doesn't exist
+	members []settingsMember
+	hooks   map[string]interface{} // Decoded "hooks" value, nil if absent
+	dirty   bool
+}
+
+// settingsMember locates a top-level member in before
+type settingsMember struct {
+	key      string
+	keyStart int // Offset of the key's opening quote
+	valStart int
+	valEnd   int
+}
+
+// loadSettingsFile reads a settings file. A file that isn't valid JSON, or
+// whose hooks don't have the shape Claude Code uses, is an error: rewriting
+// it would lose the user's settings.
+func loadSettingsFile(path string) (*settingsFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	sf := &settingsFile{path: path, before: data}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return sf, nil
+	}
+	if err := sf.parse(); err != nil {
+		return nil, fmt.Errorf("%s is not valid JSON, refusing to modify it (fix or move it and retry): %w", path, err)
+	}
+
+	if i := sf.member("hooks"); i >= 0 {
+		m := sf.members[i]
+		if err := json.Unmarshal(data[m.valStart:m.valEnd], &sf.hooks); err != nil || sf.hooks == nil {
+			return nil, fmt.Errorf("%s: \"hooks\" is not an object, refusing to modify it", path)
+		}
+		for event, list := range sf.hooks {
+			if _, ok := list.([]interface{}); !ok {
+				return nil, fmt.Errorf("%s: hooks.%s is not a list, refusing to modify it", path, event)
+			}
+		}
+	}
+	return sf, nil
+}
+
+// parse records where each top-level member of before sits
+func (sf *settingsFile) parse() error {
+	if !json.Valid(sf.before) {
+		return fmt.Errorf("invalid JSON")
+	}
+	dec := json.NewDecoder(bytes.NewReader(sf.before))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return fmt.Errorf("not a JSON object")
+	}
+	for dec.More() {
+		keyStart := skipSeparators(sf.before, int(dec.InputOffset()))
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return err
+		}
+		end := int(dec.InputOffset())
+		sf.members = append(sf.members, settingsMember{
+			key:      tok.(string),
+			keyStart: keyStart,
+			valStart: end - len(raw),
+			valEnd:   end,
+		})
+	}
+	return nil
+}
+
+// skipSeparators returns the offset of the next byte in data after i that
+// isn't whitespace or a comma
+func skipSeparators(data []byte, i int) int {
+	for i < len(data) && bytes.IndexByte([]byte(" \t\r\n,"), data[i]) >= 0 {
+		i++
+	}
+	return i
+}
+
+// member returns the index of the last top-level member named key (the one
+// JSON decoders use), or -1
+func (sf *settingsFile) member(key string) int {
+	for i := len(sf.members) - 1; i >= 0; i-- {
+		if sf.members[i].key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// empty reports whether the file would hold nothing once written
+func (sf *settingsFile) empty() bool {
+	for _, m := range sf.members {
+		if m.key != "hooks" {
+			return false
+		}
+	}
+	return len(sf.hooks) == 0
+}
+
+// has reports whether a hook running h.Command is registered for h.Event,
+// whatever its matcher
+func (sf *settingsFile) has(h SettingsHook) bool {
+	list, _ := sf.hooks[h.Event].([]interface{})
+	for _, entry := range list {
+		entryMap, _ := entry.(map[string]interface{})
+		innerHooks, _ := entryMap["hooks"].([]interface{})
+		for _, inner := range innerHooks {
+			if innerMap, ok := inner.(map[string]interface{}); ok && innerMap["command"] == h.Command {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// add appends a matcher entry running h.Command for h.Event
+func (sf *settingsFile) add(h SettingsHook) {
+	entry := map[string]interface{}{
+		"hooks": []interface{}{
+			map[string]interface{}{
+				"type":    "command",
+				"command": h.Command,
+			},
+		},
+	}
+	if h.Matcher != "" {
+		entry["matcher"] = h.Matcher
+	}
+
+	if sf.hooks == nil {
+		sf.hooks = make(map[string]interface{})
+	}
+	list, _ := sf.hooks[h.Event].([]interface{})
+	sf.hooks[h.Event] = append(list, entry)
+	sf.dirty = true
+}
+
+// remove deletes command hooks matching h exactly (event, matcher and
+// command), dropping matcher entries and events left empty. Reports whether
+// anything was removed.
+func (sf *settingsFile) remove(h SettingsHook) bool {
+	list, _ := sf.hooks[h.Event].([]interface{})
+
+	removed := false
+	var kept []interface{}
+	for _, entry := range list {
+		entryMap, ok := entry.(map[string]interface{})
+		matcher, _ := entryMap["matcher"].(string)
+		if !ok || matcher != h.Matcher {
+			kept = append(kept, entry)
+			continue
+		}
+		innerHooks, _ := entryMap["hooks"].([]interface{})
+		var innerKept []interface{}
+		for _, inner := range innerHooks {
+			if innerMap, ok := inner.(map[string]interface{}); ok && innerMap["command"] == h.Command {
+				removed = true
+				continue
+			}
+			innerKept = append(innerKept, inner)
+		}
+		if len(innerKept) == 0 && len(innerHooks) > 0 {
+			continue
+		}
+		entryMap["hooks"] = innerKept
+		kept = append(kept, entryMap)
+	}
+	if !removed {
+		return false
+	}
+
+	if len(kept) == 0 {
+		delete(sf.hooks, h.Event)
+	} else {
+		sf.hooks[h.Event] = kept
+	}
+	sf.dirty = true
+	return true
+}
+
+// change returns the pending rewrite, or nil if nothing was modified. The
+// new "hooks" value replaces the old one in place (or is appended as the
+// last member); an empty one is removed along with its key.
+func (sf *settingsFile) change() (*FileChange, error) {
+	if !sf.dirty {
+		return nil, nil
+	}
+	after, err := sf.splice()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal settings: %w", err)
+	}
+	return &FileChange{Path: sf.path, Before: sf.before, After: after}, nil
+}
+
+func (sf *settingsFile) splice() ([]byte, error) {
+	var value []byte
+	if len(sf.hooks) > 0 {
+		var err error
+		if value, err = json.MarshalIndent(sf.hooks, "  ", "  "); err != nil {
+			return nil, err
+		}
+	}
+	if len(sf.members) == 0 {
+		if value == nil {
+			return []byte("{}\n"), nil
+		}
+		return []byte("{\n  \"hooks\": " + string(value) + "\n}\n"), nil
+	}
+
+	data := sf.before
+	i := sf.member("hooks")
+	var head, mid, tail []byte
+	switch {
+	case i >= 0 && value != nil:
+		head, mid, tail = data[:sf.members[i].valStart], value, data[sf.members[i].valEnd:]
+	case i >= 0 && len(sf.members) == 1:
+		// Leave an empty object
+		head, tail = data[:bytes.IndexByte(data, '{')+1], data[skipSeparators(data, sf.members[i].valEnd):]
+	case i == 0:
+		head, tail = data[:sf.members[0].keyStart], data[sf.members[1].keyStart:]
+	case i > 0:
+		head, tail = data[:sf.members[i-1].valEnd], data[sf.members[i].valEnd:]
+	case value == nil:
+		head = data
+	default:
+		last := sf.members[len(sf.members)-1]
+		head, mid, tail = data[:last.valEnd], []byte(",\n  \"hooks\": "+string(value)), data[last.valEnd:]
+	}
+	out := make([]byte, 0, len(head)+len(mid)+len(tail))
+	return append(append(append(out, head...), mid...), tail...), nil
+}
+
+// slashHooksFile is ~/.claude/hooks.json, holding the /share and /receive hooks
+type slashHooksFile struct {
+	path   string
+	before []byte
+	config ClaudeHookConfig
+	dirty  bool
+}
+
+// loadSlashHooksFile reads hooks.json, refusing files it couldn't write back
+// unchanged
+func loadSlashHooksFile(path string) (*slashHooksFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	hf := &slashHooksFile{path: path, before: data}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return hf, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&hf.config); err != nil {
+		return nil, fmt.Errorf("%s is not in the expected format, refusing to modify it: %w", path, err)
+	}
+	return hf, nil
+}
+
+func (hf *slashHooksFile) has(matcher string) bool {
+	for _, h := range hf.config.Hooks {
+		if h.Matcher == matcher {
+			return true
+		}
+	}
+	return false
+}
+
+func (hf *slashHooksFile) add(entry HookEntry) {
+	hf.config.Hooks = append(hf.config.Hooks, entry)
+	hf.dirty = true
+}
+
+// remove deletes the entry for matcher if it still runs exactly our commands
+func (hf *slashHooksFile) remove(entry HookEntry) bool {
+	for i, h := range hf.config.Hooks {
+		if h.Matcher == entry.Matcher && equalStrings(h.Hooks, entry.Hooks) {
+			hf.config.Hooks = append(hf.config.Hooks[:i], hf.config.Hooks[i+1:]...)
+			hf.dirty = true
+			return true
+		}
+	}
+	return false
+}
+
+func (hf *slashHooksFile) change() (*FileChange, error) {
+	if !hf.dirty {
+		return nil, nil
+	}
+	if hf.config.Hooks == nil {
+		hf.config.Hooks = []HookEntry{}
+	}
+	data, err := json.MarshalIndent(hf.config, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return &FileChange{Path: hf.path, Before: hf.before, After: append(data, '\n')}, nil
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
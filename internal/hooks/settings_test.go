@@ -0,0 +1,109 @@
+package hooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const userSettings = `{
+    "model": "opus",
+    "env": {"B": "2", "A": "1"},
+    "cleanupPeriodDays": 30,
+    "hooks": {
+        "Stop": [{"hooks": [{"type": "command", "command": "say done"}]}]
+    },
+    "permissions": {"allow": ["Bash(ls:*)"]}
+}
+`
+
+// TestSettingsChangeKeepsOtherKeys checks that installing and removing hooks
+// leaves everything outside "hooks" byte-identical
+func TestSettingsChangeKeepsOtherKeys(t *testing.T) {
+	for name, before := range map[string]string{
+		"with hooks": userSettings,
+		"without hooks": strings.Replace(userSettings, `    "hooks": {
+        "Stop": [{"hooks": [{"type": "command", "command": "say done"}]}]
+    },
+`, "", 1),
+		"hooks first": "{\"hooks\": {}, \"model\": \"opus\", \"n\": 1.50}\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "settings.json")
+			if err := os.WriteFile(path, []byte(before), 0644); err != nil {
+				t.Fatal(err)
+			}
+			h := SettingsHook{Event: "SessionStart", Command: "claw hook session-start"}
+
+			sf, err := loadSettingsFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sf.add(h)
+			added := apply(t, sf)
+			if !json.Valid(added) {
+				t.Fatalf("invalid JSON after add:\n%s", added)
+			}
+			checkOtherKeys(t, []byte(before), added)
+
+			sf, err = loadSettingsFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !sf.has(h) || !sf.remove(h) {
+				t.Fatalf("added hook not found:\n%s", added)
+			}
+			removed := apply(t, sf)
+			if !json.Valid(removed) {
+				t.Fatalf("invalid JSON after remove:\n%s", removed)
+			}
+			checkOtherKeys(t, []byte(before), removed)
+		})
+	}
+}
+
+func apply(t *testing.T, sf *settingsFile) []byte {
+	t.Helper()
+	change, err := sf.change()
+	if err != nil || change == nil {
+		t.Fatalf("change: %v, %v", change, err)
+	}
+	if err := os.WriteFile(sf.path, change.After, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return change.After
+}
+
+// checkOtherKeys compares the raw bytes of every member but "hooks"
+func checkOtherKeys(t *testing.T, before, after []byte) {
+	t.Helper()
+	want := &settingsFile{before: before}
+	got := &settingsFile{before: after}
+	if err := want.parse(); err != nil {
+		t.Fatal(err)
+	}
+	if err := got.parse(); err != nil {
+		t.Fatal(err)
+	}
+	raw := func(sf *settingsFile) map[string][]byte {
+		m := make(map[string][]byte)
+		for _, mem := range sf.members {
+			if mem.key != "hooks" {
+				m[mem.key] = sf.before[mem.valStart:mem.valEnd]
+			}
+		}
+		return m
+	}
+	w, g := raw(want), raw(got)
+	if len(w) != len(g) {
+		t.Fatalf("members changed:\n%s", after)
+	}
+	for k, v := range w {
+		if !bytes.Equal(v, g[k]) {
+			t.Errorf("%s changed: %s → %s", k, v, g[k])
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package inbox
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/epuerta9/claw2claw/internal/account"
+)
+
+const (
+	// maxDeliveryAttempts stops retrying a handler that keeps failing
+	maxDeliveryAttempts = 3
+
+	// deliveredRetention is how long delivered IDs are remembered
+	deliveredRetention = 30 * 24 * time.Hour
+)
+
+// Delivery is the outcome of dispatching one notification to one handler
+type Delivery struct {
+	Notification account.Notification
+	Handler      string
+	Err          error
+	GaveUp       bool // Failed for the last time
+}
+
+// Daemon dispatches new notifications to handlers
+type Daemon struct {
+	Config     *account.Config
+	Handlers   *HandlerConfig
+	Interval   time.Duration
+	OnDelivery func(Delivery)
+	OnPoll     func() // Called when falling back to polling
+	OnError    func(error)
+}
+
+// Run dispatches notifications until ctx is cancelled. It subscribes to the
+// server's notification stream when available and otherwise polls.
+func (d *Daemon) Run(ctx context.Context) error {
+	userID := d.Config.MemberName()
+
+	// Deliver anything already waiting, then follow the stream
+	d.Poll(ctx)
+	for ctx.Err() == nil {
+		err := account.StreamNotifications(ctx, d.Config, userID, func(n account.Notification) {
+			d.dispatch(ctx, []account.Notification{n})
+		})
+		if ctx.Err() != nil {
+			return nil
+		}
+		if errors.Is(err, account.ErrStreamUnsupported) {
+			break
+		}
+		if !errors.Is(err, io.EOF) {
+			d.report(err)
+		}
+		if !sleep(ctx, 5*time.Second) {
+			return nil
+		}
+		d.Poll(ctx) // Catch up on anything missed while disconnected
+	}
+
+	if d.OnPoll != nil {
+		d.OnPoll()
+	}
+	for sleep(ctx, d.Interval) {
+		d.Poll(ctx)
+	}
+	return nil
+}
+
+// Poll fetches unread notifications once and dispatches the new ones
+func (d *Daemon) Poll(ctx context.Context) {
+	notifications, err := account.GetNotifications(d.Config, d.Config.MemberName(), true)
+	if err != nil {
+		d.report(err)
+		return
+	}
+	d.dispatch(ctx, notifications)
+}
+
+// dispatch delivers each undelivered notification to every matching handler.
+// A notification is marked delivered once no handler needs a retry.
+func (d *Daemon) dispatch(ctx context.Context, notifications []account.Notification) {
+	// Reload each time - other claw commands update the same state file
+	state, err := Load(d.Config)
+	if err != nil {
+		d.report(err)
+		return
+	}
+
+	changed := false
+	for _, n := range notifications {
+		if _, done := state.Delivered[n.ID]; done || n.ReadAt != nil {
+			continue
+		}
+
+		attempts := state.Pending[n.ID]
+		if attempts == nil {
+			attempts = make(map[string]int)
+		}
+		retry := false
+		for i := range d.Handlers.Handlers {
+			h := &d.Handlers.Handlers[i]
+			if !h.Matches(&n) || attempts[h.Name] < 0 {
+				continue
+			}
+
+			err := h.Deliver(ctx, &n)
+			delivery := Delivery{Notification: n, Handler: h.Name, Err: err}
+			if err != nil {
+				attempts[h.Name]++
+				if attempts[h.Name] >= maxDeliveryAttempts {
+					delivery.GaveUp = true
+					attempts[h.Name] = -1
+				} else {
+					retry = true
+				}
+			} else {
+				attempts[h.Name] = -1 // Done; skip this handler on retries
+			}
+			if d.OnDelivery != nil {
+				d.OnDelivery(delivery)
+			}
+		}
+
+		state.Record(n)
+		if retry {
+			state.Pending[n.ID] = attempts
+		} else {
+			state.Delivered[n.ID] = time.Now()
+			delete(state.Pending, n.ID)
+		}
+		changed = true
+	}
+
+	if changed {
+		state.pruneDelivered(deliveredRetention)
+		if err := state.Save(); err != nil {
+			d.report(err)
+		}
+	}
+}
+
+func (d *Daemon) report(err error) {
+	if err != nil && d.OnError != nil {
+		d.OnError(err)
+	}
+}
+
+// sleep waits for d, returning false if ctx is cancelled first
+func sleep(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
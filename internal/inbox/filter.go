@@ -0,0 +1,110 @@
+package inbox
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/epuerta9/claw2claw/internal/account"
+)
+
+// Filter selects notifications and board changes
+type Filter struct {
+	Types []string  // Notification types; "board" selects board changes
+	From  string    // Sender (or board section author)
+	Since time.Time // Only items newer than this
+}
+
+// Empty reports whether the filter selects everything
+func (f Filter) Empty() bool {
+	return len(f.Types) == 0 && f.From == "" && f.Since.IsZero()
+}
+
+// Match reports whether a notification passes the filter
+func (f Filter) Match(n *account.Notification) bool {
+	if len(f.Types) > 0 && !containsFold(f.Types, n.Type) {
+		return false
+	}
+	if f.From != "" && !strings.EqualFold(n.FromUser, f.From) {
+		return false
+	}
+	return f.Since.IsZero() || n.CreatedAt.After(f.Since)
+}
+
+// MatchBoard reports whether a board change passes the filter
+func (f Filter) MatchBoard(bs *account.BoardSection) bool {
+	if len(f.Types) > 0 && !containsFold(f.Types, "board") {
+		return false
+	}
+	if f.From != "" && !strings.EqualFold(bs.UpdatedBy, f.From) {
+		return false
+	}
+	return f.Since.IsZero() || bs.UpdatedAt.After(f.Since)
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseDuration accepts Go durations plus days and weeks ("2d", "1w", "1d12h")
+func ParseDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	var total time.Duration
+	for _, unit := range []struct {
+		suffix string
+		d      time.Duration
+	}{{"w", 7 * 24 * time.Hour}, {"d", 24 * time.Hour}} {
+		if i := strings.Index(s, unit.suffix); i > 0 {
+			n, err := strconv.Atoi(s[:i])
+			if err != nil {
+				return 0, fmt.Errorf("invalid duration %q", s)
+			}
+			total += time.Duration(n) * unit.d
+			s = s[i+1:]
+		}
+	}
+	if s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q (use e.g. 30m, 2h, 2d, 1w)", s)
+		}
+		total += d
+	}
+	if total <= 0 {
+		return 0, fmt.Errorf("duration must be positive")
+	}
+	return total, nil
+}
+
+// Priority ranks notification types, most urgent first
+func Priority(notifType string) int {
+	switch notifType {
+	case "blocker":
+		return 0
+	case "question":
+		return 1
+	case "mention":
+		return 2
+	case "file":
+		return 3
+	}
+	return 4
+}
+
+// SortByPriority orders notifications by type priority, newest first within a type
+func SortByPriority(notifications []account.Notification) {
+	sort.SliceStable(notifications, func(i, j int) bool {
+		pi, pj := Priority(notifications[i].Type), Priority(notifications[j].Type)
+		if pi != pj {
+			return pi < pj
+		}
+		return notifications[i].CreatedAt.After(notifications[j].CreatedAt)
+	})
+}
@@ -0,0 +1,165 @@
+package inbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/epuerta9/claw2claw/internal/account"
+)
+
+const handlersFile = ".claw/handlers.json"
+
+// handlerTimeout bounds a single webhook call or command run
+const handlerTimeout = 30 * time.Second
+
+// Match selects notifications for a handler. Empty lists match everything.
+type Match struct {
+	Types []string `json:"types,omitempty"`
+	From  []string `json:"from,omitempty"`
+}
+
+// Handler delivers matching notifications to exactly one target:
+// a local HTTP webhook, a shell command (JSON on stdin) or a directory
+type Handler struct {
+	Name    string `json:"name"`
+	Match   Match  `json:"match,omitempty"`
+	Webhook string `json:"webhook,omitempty"`
+	Command string `json:"command,omitempty"`
+	Dir     string `json:"dir,omitempty"`
+
+	// WorkDir is where commands run and relative dirs resolve (default: the
+	// current directory). The background agent sets it to the submitter's.
+	WorkDir string `json:"-"`
+}
+
+// HandlerConfig is the contents of ~/.claw/handlers.json
+type HandlerConfig struct {
+	PollInterval string    `json:"poll_interval,omitempty"` // e.g. "30s"
+	Handlers     []Handler `json:"handlers"`
+}
+
+// GetHandlersPath returns the handler config path
+func GetHandlersPath() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, handlersFile)
+}
+
+// LoadHandlers reads and validates a handler config
+func LoadHandlers(path string) (*HandlerConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no handlers configured, create %s", path)
+		}
+		return nil, err
+	}
+
+	var hc HandlerConfig
+	if err := json.Unmarshal(data, &hc); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", path, err)
+	}
+	if len(hc.Handlers) == 0 {
+		return nil, fmt.Errorf("%s has no handlers", path)
+	}
+	for i, h := range hc.Handlers {
+		targets := 0
+		for _, t := range []string{h.Webhook, h.Command, h.Dir} {
+			if t != "" {
+				targets++
+			}
+		}
+		if targets != 1 {
+			return nil, fmt.Errorf("handler %d (%s): set exactly one of webhook, command or dir", i+1, h.Name)
+		}
+		if hc.Handlers[i].Name == "" {
+			hc.Handlers[i].Name = fmt.Sprintf("handler-%d", i+1)
+		}
+	}
+	return &hc, nil
+}
+
+// Interval returns the configured poll interval, or def if unset or invalid
+func (hc *HandlerConfig) Interval(def time.Duration) time.Duration {
+	if d, err := ParseDuration(hc.PollInterval); err == nil && d >= time.Second {
+		return d
+	}
+	return def
+}
+
+// Matches reports whether the handler wants a notification
+func (h *Handler) Matches(n *account.Notification) bool {
+	if len(h.Match.Types) > 0 && !containsFold(h.Match.Types, n.Type) {
+		return false
+	}
+	if len(h.Match.From) > 0 && !containsFold(h.Match.From, n.FromUser) {
+		return false
+	}
+	return true
+}
+
+// Deliver sends a notification to the handler's target
+func (h *Handler) Deliver(ctx context.Context, n *account.Notification) error {
+	payload, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, handlerTimeout)
+	defer cancel()
+
+	switch {
+	case h.Webhook != "":
+		req, err := http.NewRequestWithContext(ctx, "POST", h.Webhook, bytes.NewReader(payload))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Claw-Notification-Type", n.Type)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			return fmt.Errorf("webhook returned %d", resp.StatusCode)
+		}
+		return nil
+
+	case h.Command != "":
+		cmd := exec.CommandContext(ctx, "sh", "-c", h.Command)
+		cmd.Dir = h.WorkDir
+		cmd.Stdin = bytes.NewReader(payload)
+		cmd.Env = append(os.Environ(),
+			"CLAW_NOTIFICATION_ID="+n.ID,
+			"CLAW_NOTIFICATION_TYPE="+n.Type,
+			"CLAW_NOTIFICATION_FROM="+n.FromUser,
+			"CLAW_NOTIFICATION_SUBJECT="+n.Subject,
+		)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+		}
+		return nil
+
+	default:
+		dir := h.Dir
+		if strings.HasPrefix(dir, "~/") {
+			home, _ := os.UserHomeDir()
+			dir = filepath.Join(home, dir[2:])
+		} else if !filepath.IsAbs(dir) && h.WorkDir != "" {
+			dir = filepath.Join(h.WorkDir, dir)
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+		path := filepath.Join(dir, filepath.Base(n.ID)+".json")
+		return os.WriteFile(path, payload, 0644)
+	}
+}
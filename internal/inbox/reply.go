@@ -0,0 +1,62 @@
+package inbox
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/epuerta9/claw2claw/internal/account"
+)
+
+// Reply answers a notification in its thread. idPrefix may be a unique ID
+// prefix of a received notification or of any cached message, so senders can
+// follow up on their own messages.
+func Reply(cfg *account.Config, idPrefix, body string) (*account.Notification, error) {
+	me := cfg.MemberName()
+
+	state, err := Load(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load inbox state: %w", err)
+	}
+
+	// Find the original notification: received ones first, then the thread cache
+	notifications, err := account.GetNotifications(cfg, me, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notifications: %w", err)
+	}
+	original, err := Resolve(notifications, state, idPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	// Mark original as read
+	if original.ToUser == me {
+		account.MarkNotificationRead(cfg, original.ID)
+	}
+
+	to := original.FromUser
+	if to == me {
+		to = original.ToUser
+	}
+	subject := original.Subject
+	if !strings.HasPrefix(subject, "Re: ") {
+		subject = "Re: " + subject
+	}
+
+	n, err := account.PostNotification(cfg, &account.Notification{
+		ToUser:   to,
+		Type:     "mention",
+		Subject:  subject,
+		Body:     body,
+		ThreadID: original.Thread(),
+		ParentID: original.ID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to send reply: %w", err)
+	}
+
+	state.Record(*original, *n)
+	if err := state.Save(); err != nil {
+		return n, fmt.Errorf("reply sent, but failed to save inbox state: %w", err)
+	}
+	return n, nil
+}
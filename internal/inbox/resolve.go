@@ -0,0 +1,97 @@
+package inbox
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/epuerta9/claw2claw/internal/account"
+)
+
+// ErrNotFound is returned when no notification or thread has the given ID
+var ErrNotFound = errors.New("not found")
+
+// Resolve finds the notification idPrefix refers to among received
+// notifications and, if state isn't nil, the thread cache. An exact ID wins;
+// a prefix matching several notifications is an error.
+func Resolve(received []account.Notification, state *State, idPrefix string) (*account.Notification, error) {
+	byID := make(map[string]*account.Notification)
+	var ids []string
+	add := func(n *account.Notification) {
+		if _, ok := byID[n.ID]; !ok {
+			byID[n.ID] = n
+			ids = append(ids, n.ID)
+		}
+	}
+	for i := range received {
+		if strings.HasPrefix(received[i].ID, idPrefix) {
+			add(&received[i])
+		}
+	}
+	if state != nil {
+		for _, n := range state.Matches(idPrefix) {
+			add(n)
+		}
+	}
+
+	if n, ok := byID[idPrefix]; ok {
+		return n, nil
+	}
+	switch len(ids) {
+	case 0:
+		return nil, fmt.Errorf("notification %w: %s", ErrNotFound, idPrefix)
+	case 1:
+		return byID[ids[0]], nil
+	}
+	return nil, ambiguous("notification", idPrefix, ids)
+}
+
+// ResolveThread returns the ID of the thread id refers to: a thread ID or
+// the ID of a message in it, from the cache or received notifications
+// (prefixes allowed). A prefix naming several threads is an error.
+func ResolveThread(received []account.Notification, state *State, id string) (string, error) {
+	seen := make(map[string]bool)
+	var threads []string
+	add := func(tid string) {
+		if !seen[tid] {
+			seen[tid] = true
+			threads = append(threads, tid)
+		}
+	}
+	for tid, t := range state.Threads {
+		if tid == id {
+			return tid, nil
+		}
+		if strings.HasPrefix(tid, id) {
+			add(tid)
+		}
+		for _, n := range t.Messages {
+			if n.ID == id {
+				return tid, nil
+			}
+			if strings.HasPrefix(n.ID, id) {
+				add(tid)
+			}
+		}
+	}
+	for _, n := range received {
+		if n.ID == id || n.Thread() == id {
+			return n.Thread(), nil
+		}
+		if strings.HasPrefix(n.ID, id) || strings.HasPrefix(n.Thread(), id) {
+			add(n.Thread())
+		}
+	}
+
+	switch len(threads) {
+	case 0:
+		return "", fmt.Errorf("thread %w: %s", ErrNotFound, id)
+	case 1:
+		return threads[0], nil
+	}
+	return "", ambiguous("thread", id, threads)
+}
+
+func ambiguous(kind, prefix string, ids []string) error {
+	return fmt.Errorf("%s ID %q is ambiguous (%d matches: %s)", kind, prefix, len(ids), strings.Join(ids, ", "))
+}
@@ -0,0 +1,77 @@
+package inbox
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/epuerta9/claw2claw/internal/account"
+)
+
+func TestResolve(t *testing.T) {
+	received := []account.Notification{{ID: "abc123"}, {ID: "abd456"}, {ID: "abc"}}
+	state := &State{Threads: map[string]*Thread{
+		"t1": {ID: "t1", Messages: []account.Notification{{ID: "ffe001", ThreadID: "t1"}, {ID: "abd456", ThreadID: "t1"}}},
+	}}
+
+	tests := []struct {
+		prefix  string
+		want    string
+		wantErr error // nil, ErrNotFound, or errAmbiguous
+	}{
+		{"abc", "abc", nil},      // Exact ID wins over longer matches
+		{"abc1", "abc123", nil},  // Unique prefix
+		{"abd", "abd456", nil},   // Same notification received and cached
+		{"ffe", "ffe001", nil},   // From the cache only
+		{"ab", "", errAmbiguous}, // Several notifications
+		{"zzz", "", ErrNotFound}, // Nothing
+	}
+	for _, tt := range tests {
+		n, err := Resolve(received, state, tt.prefix)
+		checkResolve(t, "Resolve", tt.prefix, n != nil && n.ID == tt.want || tt.want == "", err, tt.wantErr)
+	}
+
+	if _, err := Resolve(received, nil, "ffe"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Resolve without cache found a cached message: %v", err)
+	}
+}
+
+func TestResolveThread(t *testing.T) {
+	received := []account.Notification{{ID: "n1", ThreadID: "tA"}, {ID: "n2"}}
+	state := &State{Threads: map[string]*Thread{
+		"tA": {ID: "tA", Messages: []account.Notification{{ID: "n1", ThreadID: "tA"}, {ID: "m3", ThreadID: "tA"}}},
+		"tB": {ID: "tB", Messages: []account.Notification{{ID: "m4", ThreadID: "tB"}}},
+	}}
+
+	tests := []struct {
+		id      string
+		want    string
+		wantErr error
+	}{
+		{"tA", "tA", nil},       // Thread ID
+		{"m4", "tB", nil},       // Cached message
+		{"n2", "n2", nil},       // Received message starting its own thread
+		{"n1", "tA", nil},       // Received and cached
+		{"t", "", errAmbiguous}, // Two threads
+		{"x", "", ErrNotFound},
+	}
+	for _, tt := range tests {
+		got, err := ResolveThread(received, state, tt.id)
+		checkResolve(t, "ResolveThread", tt.id, got == tt.want, err, tt.wantErr)
+	}
+}
+
+var errAmbiguous = errors.New("ambiguous")
+
+func checkResolve(t *testing.T, fn, id string, ok bool, err, wantErr error) {
+	t.Helper()
+	switch {
+	case wantErr == nil && err != nil:
+		t.Errorf("%s(%q) error: %v", fn, id, err)
+	case wantErr == ErrNotFound && !errors.Is(err, ErrNotFound):
+		t.Errorf("%s(%q) = %v, want not found", fn, id, err)
+	case wantErr == errAmbiguous && (err == nil || errors.Is(err, ErrNotFound)):
+		t.Errorf("%s(%q) = %v, want ambiguous", fn, id, err)
+	case !ok:
+		t.Errorf("%s(%q) resolved to the wrong ID", fn, id)
+	}
+}
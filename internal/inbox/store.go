@@ -0,0 +1,185 @@
+// Package inbox keeps local notification state: cached threads and read-side bookkeeping
+package inbox
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/epuerta9/claw2claw/internal/account"
+)
+
+const inboxDir = ".claw/inbox"
+
+// Thread is a cached conversation
+type Thread struct {
+	ID        string                 `json:"id"`
+	Subject   string                 `json:"subject"`
+	Messages  []account.Notification `json:"messages"`
+	UpdatedAt time.Time              `json:"updated_at"`
+}
+
+// State is the local inbox state for one profile and team
+type State struct {
+	Threads   map[string]*Thread        `json:"threads"`
+	Snoozed   map[string]time.Time      `json:"snoozed,omitempty"`   // Notification ID -> hidden until
+	Delivered map[string]time.Time      `json:"delivered,omitempty"` // Notification ID -> dispatched to handlers
+	Pending   map[string]map[string]int `json:"pending,omitempty"`   // Notification ID -> handler -> failures (-1 once done)
+
+	path string
+}
+
+// GetStatePath returns ~/.claw/inbox/<profile>/<team>.json
+func GetStatePath(cfg *account.Config) string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, inboxDir, cfg.Profile(), cfg.TeamID+".json")
+}
+
+// Load reads the local inbox state (empty if missing)
+func Load(cfg *account.Config) (*State, error) {
+	s := &State{path: GetStatePath(cfg)}
+	data, err := os.ReadFile(s.path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, s); err != nil {
+			return nil, err
+		}
+	}
+	if s.Threads == nil {
+		s.Threads = make(map[string]*Thread)
+	}
+	if s.Snoozed == nil {
+		s.Snoozed = make(map[string]time.Time)
+	}
+	if s.Delivered == nil {
+		s.Delivered = make(map[string]time.Time)
+	}
+	if s.Pending == nil {
+		s.Pending = make(map[string]map[string]int)
+	}
+	return s, nil
+}
+
+// Save writes the local inbox state, dropping expired snoozes
+func (s *State) Save() error {
+	now := time.Now()
+	for id, until := range s.Snoozed {
+		if !until.After(now) {
+			delete(s.Snoozed, id)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, data, 0600)
+}
+
+// Record adds notifications to their cached threads, replacing older copies
+func (s *State) Record(notifications ...account.Notification) {
+	for _, n := range notifications {
+		if n.ID == "" {
+			continue
+		}
+		id := n.Thread()
+		t, ok := s.Threads[id]
+		if !ok {
+			t = &Thread{ID: id, Subject: n.Subject}
+			s.Threads[id] = t
+		}
+		if n.ID == id || t.Subject == "" {
+			t.Subject = n.Subject
+		}
+
+		replaced := false
+		for i := range t.Messages {
+			if t.Messages[i].ID == n.ID {
+				t.Messages[i] = n
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			t.Messages = append(t.Messages, n)
+		}
+		sort.SliceStable(t.Messages, func(i, j int) bool {
+			return t.Messages[i].CreatedAt.Before(t.Messages[j].CreatedAt)
+		})
+		t.UpdatedAt = time.Now()
+	}
+}
+
+// Find returns a cached notification by ID or unique ID prefix
+func (s *State) Find(idPrefix string) *account.Notification {
+	if matches := s.Matches(idPrefix); len(matches) == 1 {
+		return matches[0]
+	}
+	return nil
+}
+
+// Matches returns the cached notifications whose ID starts with idPrefix,
+// or only the one whose ID is exactly idPrefix
+func (s *State) Matches(idPrefix string) []*account.Notification {
+	var matches []*account.Notification
+	for _, t := range s.Threads {
+		for i := range t.Messages {
+			n := &t.Messages[i]
+			if n.ID == idPrefix {
+				return []*account.Notification{n}
+			}
+			if strings.HasPrefix(n.ID, idPrefix) {
+				matches = append(matches, n)
+			}
+		}
+	}
+	return matches
+}
+
+// Snooze hides a notification until the given time
+func (s *State) Snooze(id string, until time.Time) {
+	s.Snoozed[id] = until
+}
+
+// IsSnoozed reports whether a notification is currently hidden
+func (s *State) IsSnoozed(id string) bool {
+	until, ok := s.Snoozed[id]
+	return ok && until.After(time.Now())
+}
+
+// Select applies the filter and snoozes to notifications and sorts them by
+// priority. Returns the visible notifications and how many are snoozed.
+func (s *State) Select(notifications []account.Notification, f Filter) ([]account.Notification, int) {
+	var visible []account.Notification
+	snoozed := 0
+	for _, n := range notifications {
+		if !f.Match(&n) {
+			continue
+		}
+		if s.IsSnoozed(n.ID) {
+			snoozed++
+			continue
+		}
+		visible = append(visible, n)
+	}
+	SortByPriority(visible)
+	return visible, snoozed
+}
+
+// pruneDelivered forgets delivered IDs older than maxAge
+func (s *State) pruneDelivered(maxAge time.Duration) {
+	cutoff := time.Now().Add(-maxAge)
+	for id, at := range s.Delivered {
+		if at.Before(cutoff) {
+			delete(s.Delivered, id)
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package lan
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"strings"
+)
+
+// DNS record types and classes used by DNS-SD
+const (
+	typeA   uint16 = 1
+	typePTR uint16 = 12
+	typeTXT uint16 = 16
+	typeSRV uint16 = 33
+	typeANY uint16 = 255
+
+	classIN    uint16 = 1
+	cacheFlush uint16 = 0x8000 // Record is unique to this responder
+	unicastQU  uint16 = 0x8000 // Question asks for a unicast reply
+
+	flagResponse uint16 = 0x8400 // QR + AA
+)
+
+var errMalformed = errors.New("malformed DNS message")
+
+// message is the subset of a DNS message mDNS needs
+type message struct {
+	id        uint16
+	flags     uint16
+	questions []question
+	answers   []record // Answers and additional records together
+}
+
+type question struct {
+	name  string
+	qtype uint16
+}
+
+type record struct {
+	name   string
+	rtype  uint16
+	class  uint16
+	ttl    uint32
+	target string // PTR and SRV
+	port   uint16 // SRV
+	ip     net.IP // A
+	text   []string
+}
+
+func (m *message) isResponse() bool {
+	return m.flags&0x8000 != 0
+}
+
+// pack encodes the message without name compression
+func (m *message) pack() []byte {
+	b := make([]byte, 12, 512)
+	binary.BigEndian.PutUint16(b[0:], m.id)
+	binary.BigEndian.PutUint16(b[2:], m.flags)
+	binary.BigEndian.PutUint16(b[4:], uint16(len(m.questions)))
+	binary.BigEndian.PutUint16(b[6:], uint16(len(m.answers)))
+
+	for _, q := range m.questions {
+		b = appendName(b, q.name)
+		b = binary.BigEndian.AppendUint16(b, q.qtype)
+		b = binary.BigEndian.AppendUint16(b, classIN|unicastQU)
+	}
+	for _, r := range m.answers {
+		b = appendName(b, r.name)
+		b = binary.BigEndian.AppendUint16(b, r.rtype)
+		b = binary.BigEndian.AppendUint16(b, r.class)
+		b = binary.BigEndian.AppendUint32(b, r.ttl)
+
+		var data []byte
+		switch r.rtype {
+		case typeA:
+			data = r.ip.To4()
+		case typePTR:
+			data = appendName(nil, r.target)
+		case typeSRV:
+			data = make([]byte, 6) // Priority and weight 0
+			binary.BigEndian.PutUint16(data[4:], r.port)
+			data = appendName(data, r.target)
+		case typeTXT:
+			for _, t := range r.text {
+				data = append(data, byte(len(t)))
+				data = append(data, t...)
+			}
+		}
+		b = binary.BigEndian.AppendUint16(b, uint16(len(data)))
+		b = append(b, data...)
+	}
+	return b
+}
+
+// appendName encodes a dotted name as DNS labels
+func appendName(b []byte, name string) []byte {
+	for _, label := range strings.Split(strings.TrimSuffix(name, "."), ".") {
+		if label == "" {
+			continue
+		}
+		b = append(b, byte(len(label)))
+		b = append(b, label...)
+	}
+	return append(b, 0)
+}
+
+// unpack decodes a message. Authority records are skipped; unknown record
+// types are kept with only their name and type.
+func unpack(b []byte) (*message, error) {
+	if len(b) < 12 {
+		return nil, errMalformed
+	}
+	m := &message{
+		id:    binary.BigEndian.Uint16(b[0:]),
+		flags: binary.BigEndian.Uint16(b[2:]),
+	}
+	qd := int(binary.BigEndian.Uint16(b[4:]))
+	an := int(binary.BigEndian.Uint16(b[6:]))
+	ns := int(binary.BigEndian.Uint16(b[8:]))
+	ar := int(binary.BigEndian.Uint16(b[10:]))
+
+	off := 12
+	for i := 0; i < qd; i++ {
+		name, n, err := readName(b, off)
+		if err != nil || n+4 > len(b) {
+			return nil, errMalformed
+		}
+		m.questions = append(m.questions, question{name: name, qtype: binary.BigEndian.Uint16(b[n:])})
+		off = n + 4
+	}
+	for i := 0; i < an+ns+ar; i++ {
+		r, n, err := readRecord(b, off)
+		if err != nil {
+			return nil, err
+		}
+		if i < an || i >= an+ns {
+			m.answers = append(m.answers, r)
+		}
+		off = n
+	}
+	return m, nil
+}
+
+func readRecord(b []byte, off int) (record, int, error) {
+	name, off, err := readName(b, off)
+	if err != nil || off+10 > len(b) {
+		return record{}, 0, errMalformed
+	}
+	r := record{
+		name:  name,
+		rtype: binary.BigEndian.Uint16(b[off:]),
+		class: binary.BigEndian.Uint16(b[off+2:]),
+		ttl:   binary.BigEndian.Uint32(b[off+4:]),
+	}
+	size := int(binary.BigEndian.Uint16(b[off+8:]))
+	start := off + 10
+	end := start + size
+	if end > len(b) {
+		return record{}, 0, errMalformed
+	}
+
+	switch r.rtype {
+	case typeA:
+		if size == 4 {
+			r.ip = net.IP(append([]byte(nil), b[start:end]...))
+		}
+	case typePTR:
+		if r.target, _, err = readName(b, start); err != nil {
+			return record{}, 0, err
+		}
+	case typeSRV:
+		if size < 7 {
+			return record{}, 0, errMalformed
+		}
+		r.port = binary.BigEndian.Uint16(b[start+4:])
+		if r.target, _, err = readName(b, start+6); err != nil {
+			return record{}, 0, err
+		}
+	case typeTXT:
+		for i := start; i < end; {
+			n := int(b[i])
+			if i+1+n > end {
+				return record{}, 0, errMalformed
+			}
+			r.text = append(r.text, string(b[i+1:i+1+n]))
+			i += 1 + n
+		}
+	}
+	return r, end, nil
+}
+
+// readName decodes a possibly compressed name at off, returning it and the
+// offset just past it
+func readName(b []byte, off int) (string, int, error) {
+	var labels []string
+	end := -1
+	for jumps := 0; ; {
+		if off >= len(b) {
+			return "", 0, errMalformed
+		}
+		n := int(b[off])
+		switch {
+		case n == 0:
+			if end < 0 {
+				end = off + 1
+			}
+			return strings.Join(labels, ".") + ".", end, nil
+		case n&0xC0 == 0xC0:
+			if off+1 >= len(b) || jumps > 16 {
+				return "", 0, errMalformed
+			}
+			if end < 0 {
+				end = off + 2
+			}
+			off = int(binary.BigEndian.Uint16(b[off:]) & 0x3FFF)
+			jumps++
+		case n&0xC0 != 0 || off+1+n > len(b):
+			return "", 0, errMalformed
+		default:
+			labels = append(labels, string(b[off+1:off+1+n]))
+			off += 1 + n
+		}
+	}
+}
@@ -0,0 +1,277 @@
+// Package lan finds claw2claw shares on the local network with multicast DNS
+// service discovery (RFC 6762/6763). A share is published under a random ID
+// that says nothing about its code phrase; receivers browse for shares and
+// try their code against each one.
+package lan
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ServiceType is the DNS-SD service claw shares are published under
+const ServiceType = "_claw2claw._tcp.local."
+
+// recordTTL is how long (seconds) peers may cache the records
+const recordTTL = 120
+
+// queryInterval is how often Browse repeats its query
+const queryInterval = time.Second
+
+var mdnsGroup = &net.UDPAddr{IP: net.IPv4(224, 0, 0, 251), Port: 5353}
+
+// ErrNotFound is returned when no share answers before the context ends
+var ErrNotFound = errors.New("no matching share found on the local network")
+
+// NewServiceID returns a random name for a share. Anything derived from the
+// code phrase could be inverted by guessing codes offline, so the ID is
+// unrelated to it.
+func NewServiceID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
+
+// Share is a share found on the network
+type Share struct {
+	ID    string
+	Addrs []string // host:port
+}
+
+func instanceName(id string) string {
+	return id + "." + ServiceType
+}
+
+// hostName is the share's own host name, so the machine's name isn't published
+func hostName(id string) string {
+	return "claw-" + id[:8] + ".local."
+}
+
+// Advertiser answers mDNS queries for one share until closed
+type Advertiser struct {
+	conn     *net.UDPConn
+	id       string
+	port     uint16
+	ips      []net.IP
+	closed   chan struct{}
+	finished chan struct{}
+	once     sync.Once
+}
+
+// Advertise publishes a share listening on port until the advertiser is closed
+func Advertise(id string, port int) (*Advertiser, error) {
+	conn, err := net.ListenMulticastUDP("udp4", nil, mdnsGroup)
+	if err != nil {
+		return nil, fmt.Errorf("failed to join mDNS group: %w", err)
+	}
+	a := &Advertiser{
+		conn:     conn,
+		id:       id,
+		port:     uint16(port),
+		ips:      localIPv4s(),
+		closed:   make(chan struct{}),
+		finished: make(chan struct{}),
+	}
+	go a.serve()
+	return a, nil
+}
+
+// Close stops answering queries. It is safe to call more than once.
+func (a *Advertiser) Close() error {
+	var err error
+	a.once.Do(func() {
+		close(a.closed)
+		err = a.conn.Close()
+		<-a.finished
+	})
+	return err
+}
+
+func (a *Advertiser) serve() {
+	defer close(a.finished)
+	buf := make([]byte, 9000)
+	for {
+		n, src, err := a.conn.ReadFromUDP(buf)
+		if err != nil {
+			select {
+			case <-a.closed:
+				return
+			default:
+				continue
+			}
+		}
+		query, err := unpack(buf[:n])
+		if err != nil || query.isResponse() {
+			continue
+		}
+		answers := a.answer(query.questions)
+		if len(answers) == 0 {
+			continue
+		}
+
+		resp := &message{flags: flagResponse, answers: answers}
+		dst := mdnsGroup
+		if src.Port != mdnsGroup.Port {
+			// Legacy unicast query (RFC 6762 section 6.7): reply to the
+			// sender directly, echoing its ID and questions
+			resp.id, resp.questions, dst = query.id, query.questions, src
+		}
+		a.conn.WriteToUDP(resp.pack(), dst)
+	}
+}
+
+// answer returns the records answering the questions about this share
+func (a *Advertiser) answer(questions []question) []record {
+	instance, host := instanceName(a.id), hostName(a.id)
+	ptr := record{name: ServiceType, rtype: typePTR, class: classIN, ttl: recordTTL, target: instance}
+	srv := record{name: instance, rtype: typeSRV, class: classIN | cacheFlush, ttl: recordTTL, target: host, port: a.port}
+	txt := record{name: instance, rtype: typeTXT, class: classIN | cacheFlush, ttl: recordTTL, text: []string{"v=1"}}
+
+	var want struct{ ptr, srv, addrs bool }
+	for _, q := range questions {
+		name := strings.ToLower(q.name)
+		switch {
+		case name == ServiceType && (q.qtype == typePTR || q.qtype == typeANY):
+			want.ptr, want.srv, want.addrs = true, true, true
+		case name == instance && (q.qtype == typeSRV || q.qtype == typeTXT || q.qtype == typeANY):
+			want.srv, want.addrs = true, true
+		case name == host && (q.qtype == typeA || q.qtype == typeANY):
+			want.addrs = true
+		}
+	}
+
+	var records []record
+	if want.ptr {
+		records = append(records, ptr)
+	}
+	if want.srv {
+		records = append(records, srv, txt)
+	}
+	if want.addrs {
+		for _, ip := range a.ips {
+			records = append(records, record{name: host, rtype: typeA, class: classIN | cacheFlush, ttl: recordTTL, ip: ip})
+		}
+	}
+	return records
+}
+
+// Browse queries the network for shares until ctx ends, calling found with
+// each one the first time it answers. It stops early, returning nil, when
+// found returns true.
+func Browse(ctx context.Context, found func(Share) bool) error {
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{})
+	if err != nil {
+		return fmt.Errorf("failed to open mDNS socket: %w", err)
+	}
+	defer conn.Close()
+	stop := context.AfterFunc(ctx, func() { conn.SetReadDeadline(time.Now()) })
+	defer stop()
+
+	var idBytes [2]byte
+	rand.Read(idBytes[:])
+	query := (&message{
+		id:        binary.BigEndian.Uint16(idBytes[:]),
+		questions: []question{{name: ServiceType, qtype: typePTR}},
+	}).pack()
+
+	seen := make(map[string]bool)
+	buf := make([]byte, 9000)
+	for {
+		if _, err := conn.WriteToUDP(query, mdnsGroup); err != nil {
+			return fmt.Errorf("failed to send mDNS query: %w", err)
+		}
+		conn.SetReadDeadline(time.Now().Add(queryInterval))
+		for {
+			n, _, err := conn.ReadFromUDP(buf)
+			if err != nil {
+				break // Deadline: query again
+			}
+			resp, err := unpack(buf[:n])
+			if err != nil || !resp.isResponse() {
+				continue
+			}
+			for _, share := range shares(resp) {
+				if seen[share.ID] {
+					continue
+				}
+				seen[share.ID] = true
+				if found(share) {
+					return nil
+				}
+				if ctx.Err() != nil {
+					return ErrNotFound
+				}
+			}
+		}
+		if ctx.Err() != nil {
+			return ErrNotFound
+		}
+	}
+}
+
+// shares extracts the shares a response points to with their addresses
+func shares(resp *message) []Share {
+	var found []Share
+	for _, r := range resp.answers {
+		if r.rtype != typePTR || !strings.EqualFold(r.name, ServiceType) {
+			continue
+		}
+		id, ok := strings.CutSuffix(strings.ToLower(r.target), "."+ServiceType)
+		if !ok || id == "" {
+			continue
+		}
+		if addrs := addresses(resp, r.target); len(addrs) > 0 {
+			found = append(found, Share{ID: id, Addrs: addrs})
+		}
+	}
+	return found
+}
+
+// addresses extracts host:port for the instance from a response
+func addresses(resp *message, instance string) []string {
+	var port uint16
+	var host string
+	for _, r := range resp.answers {
+		if r.rtype == typeSRV && strings.EqualFold(r.name, instance) {
+			port, host = r.port, r.target
+		}
+	}
+	if port == 0 {
+		return nil
+	}
+	var addrs []string
+	for _, r := range resp.answers {
+		if r.rtype == typeA && r.ip != nil && strings.EqualFold(r.name, host) {
+			addrs = append(addrs, net.JoinHostPort(r.ip.String(), strconv.Itoa(int(port))))
+		}
+	}
+	return addrs
+}
+
+// localIPv4s lists this machine's non-loopback IPv4 addresses, or loopback
+// if it has none
+func localIPv4s() []net.IP {
+	var ips []net.IP
+	if addrs, err := net.InterfaceAddrs(); err == nil {
+		for _, a := range addrs {
+			if ipnet, ok := a.(*net.IPNet); ok && !ipnet.IP.IsLoopback() && ipnet.IP.To4() != nil {
+				ips = append(ips, ipnet.IP.To4())
+			}
+		}
+	}
+	if len(ips) == 0 {
+		ips = []net.IP{net.IPv4(127, 0, 0, 1).To4()}
+	}
+	return ips
+}
@@ -0,0 +1,65 @@
+package lan
+
+import (
+	"encoding/hex"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/epuerta9/claw2claw/pkg/pake"
+)
+
+func TestServiceIDNotDerivedFromCode(t *testing.T) {
+	session, err := pake.NewSession("1234-apple-river", pake.RoleSender)
+	if err != nil {
+		t.Fatal(err)
+	}
+	codeHash := hex.EncodeToString(session.GetCodeHash())
+
+	seen := make(map[string]bool)
+	for i := 0; i < 8; i++ {
+		id, err := NewServiceID()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(id) != 32 {
+			t.Errorf("NewServiceID() = %q, want 32 hex characters", id)
+		}
+		if strings.Contains(codeHash, id[:8]) {
+			t.Errorf("NewServiceID() = %q overlaps the code hash %s", id, codeHash)
+		}
+		if seen[id] {
+			t.Errorf("NewServiceID() repeated %q", id)
+		}
+		seen[id] = true
+	}
+}
+
+func TestBrowseAnswerRoundTrip(t *testing.T) {
+	id, err := NewServiceID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := &Advertiser{id: id, port: 4242, ips: []net.IP{net.IPv4(192, 168, 1, 20).To4()}}
+
+	resp := &message{flags: flagResponse, answers: a.answer([]question{{name: ServiceType, qtype: typePTR}})}
+	got, err := unpack(resp.pack())
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := shares(got)
+	if len(found) != 1 {
+		t.Fatalf("shares() = %v, want one share", found)
+	}
+	if found[0].ID != id {
+		t.Errorf("share ID = %q, want %q", found[0].ID, id)
+	}
+	if len(found[0].Addrs) != 1 || found[0].Addrs[0] != "192.168.1.20:4242" {
+		t.Errorf("share addrs = %v, want [192.168.1.20:4242]", found[0].Addrs)
+	}
+
+	// Questions about other shares get no answer
+	if records := a.answer([]question{{name: instanceName("0123456789abcdef"), qtype: typeSRV}}); len(records) != 0 {
+		t.Errorf("answer() for another share = %v, want none", records)
+	}
+}
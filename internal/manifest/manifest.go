@@ -5,16 +5,22 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
+
+	"github.com/epuerta9/claw2claw/internal/safereader"
+	"github.com/epuerta9/claw2claw/internal/secrets"
 )
 
 // Manifest tracks received files and their read state
 type Manifest struct {
 	Version   string                  `json:"version"`
 	UpdatedAt time.Time               `json:"updated_at"`
-	Files     map[string]*FileEntry   `json:"files"`
+	Files     map[string]*FileEntry   `json:"files"` // Keyed by path relative to .claw
 	Channels  map[string]*ChannelInfo `json:"channels"`
 	path      string
 }
@@ -22,6 +28,7 @@ type Manifest struct {
 // FileEntry tracks a single received file
 type FileEntry struct {
 	Filename    string    `json:"filename"`
+	Path        string    `json:"path"` // Relative to .claw, e.g. "received/notes.md"
 	ReceivedAt  time.Time `json:"received_at"`
 	LastReadAt  *time.Time `json:"last_read_at,omitempty"`
 	ContentHash string    `json:"content_hash"`
@@ -29,31 +36,59 @@ type FileEntry struct {
 	FromChannel string    `json:"from_channel,omitempty"`
 	Sequence    int       `json:"sequence"`
 	IsNew       bool      `json:"is_new"`
+	Provenance  *Provenance `json:"provenance,omitempty"`
+}
+
+// Provenance records where a received file came from
+type Provenance struct {
+	Source       string   `json:"source"`                  // "relay", "channel" or "team"
+	SourceID     string   `json:"source_id,omitempty"`     // Room, channel or shared file ID
+	From         string   `json:"from,omitempty"`          // Sender, if known
+	Path         string   `json:"path"`                    // Where the file was written
+	OriginalName string   `json:"original_name,omitempty"` // Name as sent, if it had to be sanitized
+	Encrypted    bool     `json:"encrypted,omitempty"`     // Decrypted locally
+	Verified     bool     `json:"verified"`                // Content hash checked against the server's
+	Warnings     []string `json:"warnings,omitempty"`      // safereader findings
 }
 
+// Provenance sources
+const (
+	SourceRelay   = "relay"
+	SourceChannel = "channel"
+	SourceTeam    = "team"
+	SourceLAN     = "lan"   // Direct from a peer found on the local network
+	SourceLocal   = "local" // Found on disk; claw didn't receive it, so its origin is unknown
+)
+
 // ChannelInfo tracks a bidirectional channel
 type ChannelInfo struct {
 	ID          string    `json:"id"`
 	Name        string    `json:"name,omitempty"`
 	CreatedAt   time.Time `json:"created_at"`
 	LastActivity time.Time `json:"last_activity"`
-	Code        string    `json:"code"` // Encryption code for this channel
+	Code        string    `json:"code"` // Encryption code, or a "secret:" reference into the secret store
 	Role        string    `json:"role"` // "creator" or "joiner"
 	MessageCount int      `json:"message_count"`
 }
 
 const manifestFile = ".claw/manifest.json"
 
-// Load loads or creates a manifest
+// Load loads or creates the manifest in the current directory
 func Load() (*Manifest, error) {
+	return LoadDir(".")
+}
+
+// LoadDir loads or creates the manifest of the project at dir
+func LoadDir(dir string) (*Manifest, error) {
+	path := filepath.Join(dir, manifestFile)
 	m := &Manifest{
 		Version:  "1.0",
 		Files:    make(map[string]*FileEntry),
 		Channels: make(map[string]*ChannelInfo),
-		path:     manifestFile,
+		path:     path,
 	}
 
-	data, err := os.ReadFile(manifestFile)
+	data, err := os.ReadFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return m, nil
@@ -64,10 +99,82 @@ func Load() (*Manifest, error) {
 	if err := json.Unmarshal(data, m); err != nil {
 		return nil, err
 	}
-	m.path = manifestFile
+	m.path = path
+	m.migrateKeys()
 	return m, nil
 }
 
+// migrateKeys re-keys entries from manifests that keyed files by bare name.
+// Those without a recorded path were in .claw/received.
+func (m *Manifest) migrateKeys() {
+	for key, entry := range m.Files {
+		if entry.Path != "" {
+			continue
+		}
+		delete(m.Files, key)
+		entry.Path = "received/" + entry.Filename
+		if p := entry.Provenance; p != nil && p.Path != "" {
+			entry.Path = m.Key(p.Path)
+		}
+		m.Files[entry.Path] = entry
+	}
+}
+
+// Key returns the manifest key of the file at path: its path relative to
+// the project's .claw directory, or the cleaned path if it's outside it
+func (m *Manifest) Key(path string) string {
+	clawDir, err1 := filepath.Abs(filepath.Dir(m.path))
+	abs, err2 := filepath.Abs(path)
+	if err1 != nil || err2 != nil {
+		return filepath.ToSlash(filepath.Clean(path))
+	}
+	rel, err := filepath.Rel(clawDir, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return filepath.ToSlash(abs)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// Entry returns the entry of the file at path, or nil
+func (m *Manifest) Entry(path string) *FileEntry {
+	return m.Files[m.Key(path)]
+}
+
+// fileDirs are the directories under .claw that FindFile looks in, in order
+var fileDirs = []string{"received", "shared"}
+
+// ErrFileNotFound is returned by FindFile when no file has the name
+var ErrFileNotFound = errors.New("file not found")
+
+// FindFile finds a file claw wrote into .claw/received or .claw/shared by
+// name, or by its key ("shared/notes.md") when the same name is in both.
+// The name is sanitized, so it can't point anywhere else.
+func FindFile(name string) (string, error) {
+	dirs := fileDirs
+	for _, dir := range fileDirs {
+		if rest, ok := strings.CutPrefix(filepath.ToSlash(name), dir+"/"); ok {
+			dirs, name = []string{dir}, rest
+			break
+		}
+	}
+	name = safereader.SanitizeFilename(name)
+
+	var found []string
+	for _, dir := range dirs {
+		path := filepath.Join(".claw", dir, name)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			found = append(found, path)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return "", fmt.Errorf("%w: %s", ErrFileNotFound, name)
+	case 1:
+		return found[0], nil
+	}
+	return "", fmt.Errorf("%s is in both .claw/received and .claw/shared, name one: received/%s or shared/%s", name, name, name)
+}
+
 // Save persists the manifest to disk
 func (m *Manifest) Save() error {
 	m.UpdatedAt = time.Now()
@@ -85,19 +192,21 @@ func (m *Manifest) Save() error {
 	return os.WriteFile(m.path, data, 0644)
 }
 
-// RecordReceived records a newly received file
-func (m *Manifest) RecordReceived(filename string, size int64, content []byte, channelID string) {
+// RecordReceived records a newly received file written to path
+func (m *Manifest) RecordReceived(path string, size int64, content []byte, channelID string) {
 	hash := sha256.Sum256(content)
 	hashStr := hex.EncodeToString(hash[:])
+	key := m.Key(path)
 
 	// Check if this is an update to existing file
 	seq := 1
-	if existing, ok := m.Files[filename]; ok {
+	if existing, ok := m.Files[key]; ok {
 		seq = existing.Sequence + 1
 	}
 
-	m.Files[filename] = &FileEntry{
-		Filename:    filename,
+	m.Files[key] = &FileEntry{
+		Filename:    filepath.Base(path),
+		Path:        key,
 		ReceivedAt:  time.Now(),
 		ContentHash: hashStr,
 		Size:        size,
@@ -107,9 +216,32 @@ func (m *Manifest) RecordReceived(filename string, size int64, content []byte, c
 	}
 }
 
-// MarkRead marks a file as read
-func (m *Manifest) MarkRead(filename string) {
-	if entry, ok := m.Files[filename]; ok {
+// RecordProvenance records a received file together with where it came from
+// (p.Path)
+func (m *Manifest) RecordProvenance(content []byte, p Provenance) {
+	m.RecordReceived(p.Path, int64(len(content)), content, "")
+	entry := m.Entry(p.Path)
+	if p.Source == SourceChannel {
+		entry.FromChannel = p.SourceID
+	}
+	entry.Provenance = &p
+}
+
+// RecordScanned records a file written by safereader.WriteSafe (or scanned
+// after receipt) with its provenance and scan findings. originalName is the
+// name as sent, if known.
+func (m *Manifest) RecordScanned(sc *safereader.SafeContent, originalName string, p Provenance) {
+	p.Path = sc.Path
+	if originalName != "" && originalName != sc.Filename {
+		p.OriginalName = originalName
+	}
+	p.Warnings = sc.Warnings
+	m.RecordProvenance(sc.RawContent, p)
+}
+
+// MarkRead marks the file at path as read
+func (m *Manifest) MarkRead(path string) {
+	if entry := m.Entry(path); entry != nil {
 		now := time.Now()
 		entry.LastReadAt = &now
 		entry.IsNew = false
@@ -138,8 +270,17 @@ func (m *Manifest) GetUpdatedSinceRead() []*FileEntry {
 	return updated
 }
 
-// RecordChannel records a channel
-func (m *Manifest) RecordChannel(id, name, code, role string) {
+// RecordChannel records a channel. When a secret store is set up the code is
+// kept there and the manifest only holds a reference to it.
+func (m *Manifest) RecordChannel(id, name, code, role string) error {
+	if secrets.Enabled() {
+		ref, err := secrets.Store(channelSecretName(id), code)
+		if err != nil {
+			return err
+		}
+		code = ref
+	}
+
 	m.Channels[id] = &ChannelInfo{
 		ID:          id,
 		Name:        name,
@@ -148,6 +289,34 @@ func (m *Manifest) RecordChannel(id, name, code, role string) {
 		Code:        code,
 		Role:        role,
 	}
+	return nil
+}
+
+// Secret returns the channel's encryption code, resolving secret store references
+func (ch *ChannelInfo) Secret() (string, error) {
+	return secrets.Resolve(ch.Code)
+}
+
+// MigrateSecrets moves plaintext channel codes into the secret store,
+// returning how many were moved
+func (m *Manifest) MigrateSecrets() (int, error) {
+	moved := 0
+	for id, ch := range m.Channels {
+		if ch.Code == "" || secrets.IsRef(ch.Code) {
+			continue
+		}
+		ref, err := secrets.Store(channelSecretName(id), ch.Code)
+		if err != nil {
+			return moved, err
+		}
+		ch.Code = ref
+		moved++
+	}
+	return moved, nil
+}
+
+func channelSecretName(id string) string {
+	return "channel/" + id
 }
 
 // UpdateChannelActivity updates the last activity time for a channel
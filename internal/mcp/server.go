@@ -0,0 +1,300 @@
+// Package mcp implements a Model Context Protocol server over stdio so agents
+// can call claw2claw as typed tools instead of scraping CLI output
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// ProtocolVersion is the MCP revision this server implements
+const ProtocolVersion = "2025-06-18"
+
+// maxMessageSize bounds a single JSON-RPC message read from stdin
+const maxMessageSize = 16 << 20
+
+// JSON-RPC error codes
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+)
+
+// Handler runs a tool. args is the raw "arguments" object; the result is
+// returned to the client as JSON.
+type Handler func(ctx context.Context, args json.RawMessage) (any, error)
+
+// Tool is a callable tool advertised through tools/list
+type Tool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	InputSchema map[string]any `json:"inputSchema"`
+	Handler     Handler        `json:"-"`
+}
+
+// Property describes one tool argument
+type Property struct {
+	Type        string    `json:"type"`
+	Description string    `json:"description,omitempty"`
+	Enum        []string  `json:"enum,omitempty"`
+	Items       *Property `json:"items,omitempty"`
+}
+
+// Schema builds the JSON Schema for a tool's arguments
+func Schema(props map[string]Property, required ...string) map[string]any {
+	if props == nil {
+		props = map[string]Property{}
+	}
+	s := map[string]any{"type": "object", "properties": props}
+	if len(required) > 0 {
+		s["required"] = required
+	}
+	return s
+}
+
+// Server dispatches JSON-RPC requests to tools
+type Server struct {
+	Name    string
+	Version string
+
+	tools []Tool
+	index map[string]int
+
+	mu       sync.Mutex // Guards out and inflight
+	out      io.Writer
+	inflight map[string]context.CancelFunc
+}
+
+// NewServer creates a server with no tools
+func NewServer(name, version string) *Server {
+	return &Server{Name: name, Version: version, index: make(map[string]int)}
+}
+
+// AddTool registers a tool, replacing any tool with the same name
+func (s *Server) AddTool(t Tool) {
+	if i, ok := s.index[t.Name]; ok {
+		s.tools[i] = t
+		return
+	}
+	s.index[t.Name] = len(s.tools)
+	s.tools = append(s.tools, t)
+}
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type content struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type callResult struct {
+	Content           []content `json:"content"`
+	StructuredContent any       `json:"structuredContent,omitempty"`
+	IsError           bool      `json:"isError,omitempty"`
+}
+
+// Serve reads newline-delimited JSON-RPC messages from in and writes
+// responses to out until in is closed or ctx is cancelled. Tool calls run
+// concurrently so a long transfer doesn't block other requests.
+func (s *Server) Serve(ctx context.Context, in io.Reader, out io.Writer) error {
+	s.out = out
+	s.inflight = make(map[string]context.CancelFunc)
+
+	// When the client goes away, cancel calls still running and wait for them
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), maxMessageSize)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var req request
+		if err := json.Unmarshal(line, &req); err != nil {
+			s.send(response{ID: json.RawMessage("null"), Error: &rpcError{codeParseError, "parse error"}})
+			continue
+		}
+		if req.JSONRPC != "2.0" || req.Method == "" {
+			if req.ID != nil {
+				s.send(response{ID: req.ID, Error: &rpcError{codeInvalidRequest, "invalid request"}})
+			}
+			continue
+		}
+
+		if req.Method == "tools/call" && req.ID != nil {
+			callCtx, callCancel := context.WithCancel(ctx)
+			s.track(req.ID, callCancel)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer s.untrack(req.ID)
+				s.reply(req, s.callTool(callCtx, req.Params))
+			}()
+			continue
+		}
+
+		result, rerr := s.handle(req)
+		if req.ID == nil {
+			continue // Notification, no response
+		}
+		if rerr != nil {
+			s.send(response{ID: req.ID, Error: rerr})
+		} else {
+			s.send(response{ID: req.ID, Result: result})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read request: %w", err)
+	}
+	return nil
+}
+
+// handle answers the protocol methods other than tools/call
+func (s *Server) handle(req request) (any, *rpcError) {
+	switch req.Method {
+	case "initialize":
+		var params struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		}
+		json.Unmarshal(req.Params, &params)
+		version := ProtocolVersion
+		if params.ProtocolVersion != "" && params.ProtocolVersion < ProtocolVersion {
+			version = params.ProtocolVersion // Older clients get their own revision
+		}
+		return map[string]any{
+			"protocolVersion": version,
+			"capabilities":    map[string]any{"tools": map[string]any{"listChanged": false}},
+			"serverInfo":      map[string]string{"name": s.Name, "version": s.Version},
+		}, nil
+
+	case "ping":
+		return map[string]any{}, nil
+
+	case "tools/list":
+		return map[string]any{"tools": s.tools}, nil
+
+	case "notifications/cancelled":
+		var params struct {
+			RequestID json.RawMessage `json:"requestId"`
+		}
+		if json.Unmarshal(req.Params, &params) == nil {
+			s.cancel(params.RequestID)
+		}
+		return nil, nil
+
+	case "notifications/initialized":
+		return nil, nil
+	}
+	return nil, &rpcError{codeMethodNotFound, "method not found: " + req.Method}
+}
+
+// callTool runs a tool. Tool failures are reported in the result (isError)
+// so the model sees them; only malformed calls are protocol errors.
+func (s *Server) callTool(ctx context.Context, params json.RawMessage) any {
+	var call struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	}
+	if err := json.Unmarshal(params, &call); err != nil {
+		return &rpcError{codeInvalidParams, "invalid params"}
+	}
+	i, ok := s.index[call.Name]
+	if !ok {
+		return &rpcError{codeInvalidParams, "unknown tool: " + call.Name}
+	}
+	if len(call.Arguments) == 0 || string(call.Arguments) == "null" {
+		call.Arguments = json.RawMessage("{}")
+	}
+
+	result, err := s.tools[i].Handler(ctx, call.Arguments)
+	if err != nil {
+		return &callResult{Content: []content{{Type: "text", Text: err.Error()}}, IsError: true}
+	}
+
+	// The text copy is for models: keep <, > and & readable
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(result); err != nil {
+		return &callResult{Content: []content{{Type: "text", Text: err.Error()}}, IsError: true}
+	}
+	return &callResult{
+		Content:           []content{{Type: "text", Text: strings.TrimSuffix(buf.String(), "\n")}},
+		StructuredContent: result,
+	}
+}
+
+// reply sends a tools/call outcome, which is either a result or an *rpcError
+func (s *Server) reply(req request, outcome any) {
+	if rerr, ok := outcome.(*rpcError); ok {
+		s.send(response{ID: req.ID, Error: rerr})
+		return
+	}
+	s.send(response{ID: req.ID, Result: outcome})
+}
+
+func (s *Server) send(resp response) {
+	resp.JSONRPC = "2.0"
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.out.Write(append(data, '\n'))
+}
+
+func (s *Server) track(id json.RawMessage, cancel context.CancelFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inflight[string(id)] = cancel
+}
+
+func (s *Server) untrack(id json.RawMessage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cancel, ok := s.inflight[string(id)]; ok {
+		cancel()
+		delete(s.inflight, string(id))
+	}
+}
+
+func (s *Server) cancel(id json.RawMessage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cancel, ok := s.inflight[string(id)]; ok {
+		cancel()
+	}
+}
@@ -0,0 +1,160 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rpcClient drives Server.Serve over pipes, one request at a time
+type rpcClient struct {
+	t   *testing.T
+	in  *io.PipeWriter
+	out *bufio.Scanner
+}
+
+func (c *rpcClient) send(msg string) {
+	c.t.Helper()
+	if _, err := io.WriteString(c.in, msg+"\n"); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+func (c *rpcClient) recv() map[string]any {
+	c.t.Helper()
+	if !c.out.Scan() {
+		c.t.Fatalf("no response: %v", c.out.Err())
+	}
+	var resp map[string]any
+	if err := json.Unmarshal(c.out.Bytes(), &resp); err != nil {
+		c.t.Fatalf("invalid response %s: %v", c.out.Bytes(), err)
+	}
+	if resp["jsonrpc"] != "2.0" {
+		c.t.Errorf("response %s lacks jsonrpc 2.0", c.out.Bytes())
+	}
+	return resp
+}
+
+func (c *rpcClient) call(msg string) map[string]any {
+	c.t.Helper()
+	c.send(msg)
+	return c.recv()
+}
+
+func TestServeRoundTrip(t *testing.T) {
+	s := NewServer("test", "1.2.3")
+	s.AddTool(Tool{
+		Name:        "echo",
+		InputSchema: Schema(map[string]Property{"text": {Type: "string"}}, "text"),
+		Handler: func(ctx context.Context, args json.RawMessage) (any, error) {
+			var in struct {
+				Text string `json:"text"`
+			}
+			if err := decode(args, &in); err != nil {
+				return nil, err
+			}
+			return map[string]string{"echo": in.Text}, nil
+		},
+	})
+	s.AddTool(Tool{
+		Name:        "block",
+		InputSchema: Schema(nil),
+		Handler: func(ctx context.Context, args json.RawMessage) (any, error) {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(5 * time.Second):
+				return nil, errors.New("not cancelled")
+			}
+		},
+	})
+
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- s.Serve(context.Background(), inR, outW)
+		outW.Close()
+	}()
+	c := &rpcClient{t: t, in: inW, out: bufio.NewScanner(outR)}
+
+	// initialize
+	resp := c.call(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05"}}`)
+	result := resp["result"].(map[string]any)
+	if result["protocolVersion"] != "2024-11-05" {
+		t.Errorf("protocolVersion = %v, want the client's older revision", result["protocolVersion"])
+	}
+	if info := result["serverInfo"].(map[string]any); info["name"] != "test" || info["version"] != "1.2.3" {
+		t.Errorf("serverInfo = %v", info)
+	}
+	c.send(`{"jsonrpc":"2.0","method":"notifications/initialized"}`) // No response
+
+	// tools/list
+	resp = c.call(`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`)
+	tools := resp["result"].(map[string]any)["tools"].([]any)
+	if len(tools) != 2 || tools[0].(map[string]any)["name"] != "echo" || tools[0].(map[string]any)["inputSchema"] == nil {
+		t.Errorf("tools/list = %v", tools)
+	}
+
+	// tools/call
+	resp = c.call(`{"jsonrpc":"2.0","id":"a","method":"tools/call","params":{"name":"echo","arguments":{"text":"<hi>"}}}`)
+	if resp["id"] != "a" {
+		t.Errorf("id = %v, want a", resp["id"])
+	}
+	result = resp["result"].(map[string]any)
+	if sc := result["structuredContent"].(map[string]any); sc["echo"] != "<hi>" {
+		t.Errorf("structuredContent = %v", sc)
+	}
+	if text := result["content"].([]any)[0].(map[string]any)["text"].(string); !strings.Contains(text, `"<hi>"`) {
+		t.Errorf("text content = %q, want unescaped JSON", text)
+	}
+
+	// A tool error is a result the model sees, not a protocol error
+	resp = c.call(`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"echo","arguments":{"bogus":1}}}`)
+	if result := resp["result"].(map[string]any); result["isError"] != true {
+		t.Errorf("bad arguments result = %v, want isError", result)
+	}
+
+	// Cancelling a running call ends it with an error result
+	c.send(`{"jsonrpc":"2.0","id":5,"method":"tools/call","params":{"name":"block"}}`)
+	c.send(`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":5}}`)
+	resp = c.recv()
+	if resp["id"] != float64(5) {
+		t.Fatalf("response id = %v, want 5", resp["id"])
+	}
+	result = resp["result"].(map[string]any)
+	if text := result["content"].([]any)[0].(map[string]any)["text"]; result["isError"] != true || text != context.Canceled.Error() {
+		t.Errorf("cancelled call result = %v", result)
+	}
+
+	// Protocol errors
+	errCode := func(resp map[string]any) float64 {
+		e, _ := resp["error"].(map[string]any)
+		code, _ := e["code"].(float64)
+		return code
+	}
+	if resp := c.call(`{"jsonrpc":"2.0","id":6,"method":"tools/call","params":{"name":"nope"}}`); errCode(resp) != codeInvalidParams {
+		t.Errorf("unknown tool = %v", resp)
+	}
+	if resp := c.call(`{"jsonrpc":"2.0","id":7,"method":"resources/list"}`); errCode(resp) != codeMethodNotFound {
+		t.Errorf("unknown method = %v", resp)
+	}
+	if resp := c.call(`{not json`); errCode(resp) != codeParseError || resp["id"] != nil {
+		t.Errorf("parse error = %v", resp)
+	}
+
+	inW.Close()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Serve() = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Serve() didn't return after stdin closed")
+	}
+}
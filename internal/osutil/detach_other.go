@@ -0,0 +1,9 @@
+//go:build !unix
+
+package osutil
+
+import "syscall"
+
+func DetachAttr() *syscall.SysProcAttr {
+	return nil
+}
@@ -0,0 +1,10 @@
+//go:build unix
+
+package osutil
+
+import "syscall"
+
+// DetachAttr starts a process in its own session so it survives the terminal
+func DetachAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setsid: true}
+}
@@ -0,0 +1,19 @@
+//go:build !unix
+
+package osutil
+
+import "os"
+
+// Lock only creates the lock file here: without flock, callers run unlocked
+func Lock(path string) (unlock func(), err error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return func() { f.Close() }, nil
+}
+
+// TryLock is Lock; it never reports ErrLocked on this platform
+func TryLock(path string) (unlock func(), err error) {
+	return Lock(path)
+}
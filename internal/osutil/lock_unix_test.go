@@ -0,0 +1,43 @@
+//go:build unix
+
+package osutil
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTryLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.lock")
+	unlock, err := TryLock(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := TryLock(path); !errors.Is(err, ErrLocked) {
+		t.Fatalf("second TryLock() error = %v, want ErrLocked", err)
+	}
+
+	// Lock waits for the holder
+	acquired := make(chan func())
+	go func() {
+		u, err := Lock(path)
+		if err != nil {
+			t.Error(err)
+		}
+		acquired <- u
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("Lock() returned while the lock was held")
+	case <-time.After(50 * time.Millisecond):
+	}
+	unlock()
+	select {
+	case u := <-acquired:
+		u()
+	case <-time.After(5 * time.Second):
+		t.Fatal("Lock() didn't return after unlock")
+	}
+}
@@ -0,0 +1,8 @@
+// Package osutil holds the small platform-specific pieces shared by claw's
+// background processes: detaching from the terminal and file locks
+package osutil
+
+import "errors"
+
+// ErrLocked is returned by TryLock when another process holds the lock
+var ErrLocked = errors.New("locked by another process")
@@ -24,6 +24,12 @@ const (
 	MsgPakeA MessageType = "PAKE_A" // Sender's PAKE message
 	MsgPakeB MessageType = "PAKE_B" // Receiver's PAKE response
 
+	// Direct connection (relay used for rendezvous only)
+	MsgDirectOffer  MessageType = "DIRECT_OFFER"  // Sender's listen addresses, encrypted
+	MsgDirectFailed MessageType = "DIRECT_FAILED" // Receiver couldn't connect, use the relay
+	MsgDirectHello  MessageType = "DIRECT_HELLO"  // Receiver proves the session key over TCP
+	MsgDirectReady  MessageType = "DIRECT_READY"  // Sender accepts the direct connection
+
 	// Content transfer
 	MsgEncrypted MessageType = "ENCRYPTED" // Encrypted content
 	MsgAck       MessageType = "ACK"       // Acknowledgment
@@ -89,7 +95,20 @@ type RoomCreatedPayload struct {
 
 // PakePayload contains PAKE exchange data
 type PakePayload struct {
-	Data []byte `json:"data"` // PAKE message bytes
+	Data   []byte `json:"data"`             // PAKE message bytes
+	Direct bool   `json:"direct,omitempty"` // Receiver accepts a direct connection offer
+}
+
+// DirectPayload carries direct connection data encrypted with the session key
+type DirectPayload struct {
+	Data []byte `json:"data"`
+}
+
+// DirectOffer is the plaintext of a DIRECT_OFFER: where the sender listens
+// and a one-time token the receiver must prove it can encrypt
+type DirectOffer struct {
+	Addresses []string `json:"addresses"`
+	Token     []byte   `json:"token"`
 }
 
 // EncryptedPayload contains encrypted content
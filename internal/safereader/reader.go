@@ -13,6 +13,7 @@ import (
 // SafeContent wraps external content with safety markers
 type SafeContent struct {
 	Filename    string
+	Path        string
 	Content     string
 	ReceivedAt  time.Time
 	Warnings    []string
@@ -50,10 +51,23 @@ func ReadSafe(filePath string) (*SafeContent, error) {
 		return nil, err
 	}
 
+	sc := scan(filePath, content, info.ModTime())
+	return sc, nil
+}
+
+// Scan checks content that didn't come from a file, such as a notification,
+// and wraps it with safety markers. name identifies it in the markers.
+func Scan(name string, content []byte, receivedAt time.Time) *SafeContent {
+	return scan(name, content, receivedAt)
+}
+
+// scan checks content for suspicious patterns and wraps it with safety markers
+func scan(filePath string, content []byte, receivedAt time.Time) *SafeContent {
 	sc := &SafeContent{
 		Filename:   filepath.Base(filePath),
+		Path:       filePath,
 		RawContent: content,
-		ReceivedAt: info.ModTime(),
+		ReceivedAt: receivedAt,
 		IsSafe:     true,
 	}
 
@@ -69,7 +83,7 @@ func ReadSafe(filePath string) (*SafeContent, error) {
 	// Wrap content with safety markers
 	sc.Content = sc.wrapContent(contentStr)
 
-	return sc, nil
+	return sc
 }
 
 // wrapContent wraps the content with clear external content markers
@@ -0,0 +1,93 @@
+package safereader
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// maxFilenameLen keeps sanitized names well under filesystem limits
+const maxFilenameLen = 200
+
+// maxNameAttempts bounds the search for a free numbered filename
+const maxNameAttempts = 1000
+
+// SanitizeFilename reduces an untrusted filename to a safe base name:
+// no directories, no control characters and no leading dots
+func SanitizeFilename(name string) string {
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || r == '/' || r == ':' {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(strings.TrimLeft(name, ". "))
+
+	if len(name) > maxFilenameLen {
+		ext := filepath.Ext(name)
+		if len(ext) > 16 {
+			ext = ""
+		}
+		name = strings.ToValidUTF8(name[:maxFilenameLen-len(ext)], "") + ext
+	}
+	if name == "" {
+		return "file"
+	}
+	return name
+}
+
+// WriteSafe writes untrusted content into dir under a sanitized name and
+// scans it. Existing files are never overwritten: identical content reuses
+// the existing file, anything else gets a numbered name (notes-1.md).
+func WriteSafe(dir, name string, content []byte) (*SafeContent, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	safe := SanitizeFilename(name)
+	ext := filepath.Ext(safe)
+	base := strings.TrimSuffix(safe, ext)
+	for i := 0; i < maxNameAttempts; i++ {
+		candidate := safe
+		if i > 0 {
+			candidate = fmt.Sprintf("%s-%d%s", base, i, ext)
+		}
+		p := filepath.Join(dir, candidate)
+
+		if info, err := os.Lstat(p); err == nil {
+			if info.Mode().IsRegular() {
+				if existing, err := os.ReadFile(p); err == nil && bytes.Equal(existing, content) {
+					return scan(p, content, info.ModTime()), nil
+				}
+			}
+			continue
+		}
+
+		// O_EXCL refuses to follow a symlink or clobber a file created meanwhile
+		f, err := os.OpenFile(p, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if _, err := f.Write(content); err != nil {
+			f.Close()
+			os.Remove(p)
+			return nil, err
+		}
+		if err := f.Close(); err != nil {
+			os.Remove(p)
+			return nil, err
+		}
+		return scan(p, content, time.Now()), nil
+	}
+	return nil, fmt.Errorf("too many files named %s in %s", safe, dir)
+}
@@ -0,0 +1,150 @@
+package secrets
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/epuerta9/claw2claw/internal/osutil"
+)
+
+const agentSocketName = ".claw/unlock.sock"
+
+// GetAgentSocketPath returns the unlock agent's per-user socket
+func GetAgentSocketPath() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, agentSocketName)
+}
+
+// ServeAgent holds the store key in memory and hands it to local clients
+// until ttl elapses or a LOCK request arrives
+func ServeAgent(key []byte, ttl time.Duration) error {
+	path := GetAgentSocketPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	os.Remove(path) // Stale socket from a previous agent
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", path, err)
+	}
+	defer os.Remove(path)
+	if err := os.Chmod(path, 0600); err != nil {
+		ln.Close()
+		return err
+	}
+
+	timer := time.AfterFunc(ttl, func() { ln.Close() })
+	defer timer.Stop()
+
+	encoded := hex.EncodeToString(key)
+	defer func() {
+		for i := range key {
+			key[i] = 0
+		}
+	}()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return nil // Listener closed: TTL expired or locked
+		}
+
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+		line, _ := bufio.NewReader(conn).ReadString('\n')
+		switch strings.TrimSpace(line) {
+		case "KEY":
+			fmt.Fprintln(conn, encoded)
+		case "PING":
+			fmt.Fprintln(conn, "OK")
+		case "LOCK":
+			fmt.Fprintln(conn, "OK")
+			conn.Close()
+			ln.Close()
+			return nil
+		default:
+			fmt.Fprintln(conn, "ERR unknown request")
+		}
+		conn.Close()
+	}
+}
+
+// StartAgent launches a detached unlock agent (`claw secrets agent`) holding key for ttl
+func StartAgent(key []byte, ttl time.Duration) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command(exe, "secrets", "agent", "--ttl", ttl.String())
+	cmd.SysProcAttr = osutil.DetachAttr()
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start unlock agent: %w", err)
+	}
+	fmt.Fprintln(stdin, hex.EncodeToString(key))
+	stdin.Close()
+	cmd.Process.Release()
+
+	// Wait for the socket to come up
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		if AgentRunning() {
+			return nil
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	return fmt.Errorf("unlock agent did not start")
+}
+
+// AgentRunning reports whether an unlock agent is answering on the socket
+func AgentRunning() bool {
+	resp, err := agentRequest("PING")
+	return err == nil && resp == "OK"
+}
+
+// LockAgent stops the unlock agent, forgetting the cached key
+func LockAgent() error {
+	_, err := agentRequest("LOCK")
+	return err
+}
+
+func agentKey() ([]byte, error) {
+	resp, err := agentRequest("KEY")
+	if err != nil {
+		return nil, err
+	}
+	return hex.DecodeString(resp)
+}
+
+func agentRequest(req string) (string, error) {
+	conn, err := net.DialTimeout("unix", GetAgentSocketPath(), time.Second)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	conn.SetDeadline(time.Now().Add(2 * time.Second))
+	if _, err := fmt.Fprintln(conn, req); err != nil {
+		return "", err
+	}
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	line = strings.TrimSpace(line)
+	if strings.HasPrefix(line, "ERR") {
+		return "", fmt.Errorf("unlock agent: %s", line)
+	}
+	return line, nil
+}
@@ -0,0 +1,135 @@
+package secrets
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// EnvPassphrase unlocks a passphrase store non-interactively (CI, agents)
+const EnvPassphrase = "CLAW_PASSPHRASE"
+
+const (
+	keychainService = "claw2claw"
+	keychainAccount = "secrets"
+)
+
+// obtainKey finds the store key: OS keychain, unlock agent, CLAW_PASSPHRASE,
+// then an interactive prompt on the terminal
+func obtainKey(env *envelope) ([]byte, error) {
+	if env.KDF == KDFKeychain {
+		return keychainLoad()
+	}
+
+	if key, err := agentKey(); err == nil {
+		return key, nil
+	}
+
+	if passphrase := os.Getenv(EnvPassphrase); passphrase != "" {
+		return keyFromPassphrase(env, passphrase)
+	}
+
+	// Only prompt when a person is at the terminal - never from hooks or pipes
+	if info, err := os.Stdin.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return nil, ErrLocked
+	}
+	passphrase, err := ReadPassphrase("🔐 Secret store passphrase: ")
+	if err != nil {
+		return nil, ErrLocked
+	}
+	return keyFromPassphrase(env, passphrase)
+}
+
+// ReadPassphrase prompts on the controlling terminal with echo disabled
+func ReadPassphrase(prompt string) (string, error) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return "", fmt.Errorf("no terminal available: %w", err)
+	}
+	defer tty.Close()
+
+	fmt.Fprint(tty, prompt)
+	setEcho(tty, false)
+	line, err := bufio.NewReader(tty).ReadString('\n')
+	setEcho(tty, true)
+	fmt.Fprintln(tty)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func setEcho(tty *os.File, on bool) {
+	arg := "-echo"
+	if on {
+		arg = "echo"
+	}
+	cmd := exec.Command("stty", arg)
+	cmd.Stdin = tty
+	cmd.Run()
+}
+
+// KeychainAvailable reports whether an OS keychain tool is installed
+func KeychainAvailable() bool {
+	switch runtime.GOOS {
+	case "darwin":
+		_, err := exec.LookPath("security")
+		return err == nil
+	case "linux":
+		_, err := exec.LookPath("secret-tool")
+		return err == nil
+	}
+	return false
+}
+
+func keychainStore(key []byte) error {
+	encoded := hex.EncodeToString(key)
+	switch runtime.GOOS {
+	case "darwin":
+		// The command is read from stdin (security -i) so the key never shows
+		// up in argv, where other users could see it in ps. Interactive mode
+		// doesn't fail the exit status, but the command is silent on success.
+		cmd := exec.Command("security", "-i")
+		cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n",
+			keychainService, keychainAccount, encoded))
+		out, err := cmd.CombinedOutput()
+		if err == nil && len(bytes.TrimSpace(out)) > 0 {
+			err = errors.New(string(bytes.TrimSpace(out)))
+		}
+		if err != nil {
+			return fmt.Errorf("failed to store key in keychain: %w", err)
+		}
+		return nil
+	case "linux":
+		cmd := exec.Command("secret-tool", "store", "--label=claw2claw secret store",
+			"service", keychainService, "account", keychainAccount)
+		cmd.Stdin = strings.NewReader(encoded)
+		return cmd.Run()
+	}
+	return fmt.Errorf("no OS keychain support on %s", runtime.GOOS)
+}
+
+func keychainLoad() ([]byte, error) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("security", "find-generic-password",
+			"-s", keychainService, "-a", keychainAccount, "-w")
+	case "linux":
+		cmd = exec.Command("secret-tool", "lookup", "service", keychainService, "account", keychainAccount)
+	default:
+		return nil, fmt.Errorf("no OS keychain support on %s", runtime.GOOS)
+	}
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%w: keychain lookup failed: %v", ErrLocked, err)
+	}
+	return hex.DecodeString(string(bytes.TrimSpace(out)))
+}
@@ -0,0 +1,376 @@
+// Package secrets provides an encrypted local store for tokens and channel codes
+package secrets
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/epuerta9/claw2claw/internal/crypto"
+	"github.com/epuerta9/claw2claw/internal/osutil"
+	"golang.org/x/crypto/scrypt"
+)
+
+var (
+	ErrNotInitialized = errors.New("secret store not initialized, run: claw secrets init")
+	ErrLocked         = errors.New("secret store is locked, run: claw secrets unlock (or set CLAW_PASSPHRASE)")
+	ErrBadPassphrase  = errors.New("wrong passphrase")
+	ErrNotFound       = errors.New("secret not found")
+)
+
+// RefPrefix marks a config value as a reference into the secret store
+const RefPrefix = "secret:"
+
+// Key sources recorded in the store header
+const (
+	KDFScrypt   = "scrypt"   // Key derived from a user passphrase
+	KDFKeychain = "keychain" // Random key held by the OS keychain
+)
+
+const storeFileName = ".claw/secrets.json"
+
+// scrypt parameters for passphrase-derived keys
+const (
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+// envelope is the on-disk format of the store
+type envelope struct {
+	Version int    `json:"version"`
+	KDF     string `json:"kdf"`
+	Salt    string `json:"salt,omitempty"` // base64, scrypt only
+	Data    string `json:"data"`           // base64 nonce || ciphertext || tag
+}
+
+var (
+	mu        sync.Mutex
+	cachedKey []byte // Unlocked key for the lifetime of this process
+)
+
+// GetStorePath returns the path to the encrypted store
+func GetStorePath() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, storeFileName)
+}
+
+// Enabled reports whether a secret store has been initialized
+func Enabled() bool {
+	_, err := os.Stat(GetStorePath())
+	return err == nil
+}
+
+// Ref returns the reference string stored in config files for a secret
+func Ref(name string) string {
+	return RefPrefix + name
+}
+
+// IsRef reports whether a config value is a secret reference
+func IsRef(value string) bool {
+	return strings.HasPrefix(value, RefPrefix)
+}
+
+// Resolve returns the secret behind a reference, or the value unchanged if it is not one
+func Resolve(value string) (string, error) {
+	if !IsRef(value) {
+		return value, nil
+	}
+	return Get(strings.TrimPrefix(value, RefPrefix))
+}
+
+// Store saves value under name and returns its reference. Empty values and
+// values that are already references are returned unchanged.
+func Store(name, value string) (string, error) {
+	if value == "" || IsRef(value) {
+		return value, nil
+	}
+	if err := Put(name, value); err != nil {
+		return "", err
+	}
+	return Ref(name), nil
+}
+
+// Init creates a new store. With useKeychain a random key is kept in the OS
+// keychain; otherwise the key is derived from passphrase.
+func Init(passphrase string, useKeychain bool) error {
+	if Enabled() {
+		return fmt.Errorf("secret store already exists at %s", GetStorePath())
+	}
+
+	env := &envelope{Version: 1}
+	var key []byte
+	if useKeychain {
+		k, err := crypto.GenerateRandom(crypto.KeySize)
+		if err != nil {
+			return err
+		}
+		if err := keychainStore(k); err != nil {
+			return fmt.Errorf("failed to store key in OS keychain: %w", err)
+		}
+		env.KDF = KDFKeychain
+		key = k
+	} else {
+		if passphrase == "" {
+			return fmt.Errorf("passphrase is required")
+		}
+		salt, err := crypto.GenerateRandom(16)
+		if err != nil {
+			return err
+		}
+		k, err := deriveKey(passphrase, salt)
+		if err != nil {
+			return err
+		}
+		env.KDF = KDFScrypt
+		env.Salt = base64.StdEncoding.EncodeToString(salt)
+		key = k
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	unlock, err := lockStore()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if Enabled() {
+		return fmt.Errorf("secret store already exists at %s", GetStorePath())
+	}
+	cachedKey = key
+	return writeStore(env, key, map[string]string{})
+}
+
+// Get returns a secret by name
+func Get(name string) (string, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	_, values, err := openStore()
+	if err != nil {
+		return "", err
+	}
+	v, ok := values[name]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return v, nil
+}
+
+// Put stores a secret by name
+func Put(name, value string) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	unlock, err := lockForUpdate()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	env, values, err := openStore()
+	if err != nil {
+		return err
+	}
+	if values[name] == value {
+		return nil
+	}
+	values[name] = value
+	return writeStore(env, cachedKey, values)
+}
+
+// Delete removes a secret by name
+func Delete(name string) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	unlock, err := lockForUpdate()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	env, values, err := openStore()
+	if err != nil {
+		return err
+	}
+	if _, ok := values[name]; !ok {
+		return nil
+	}
+	delete(values, name)
+	return writeStore(env, cachedKey, values)
+}
+
+// List returns the names of all stored secrets
+func List() ([]string, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	_, values, err := openStore()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// KeySource returns how the store key is obtained (KDFScrypt or KDFKeychain)
+func KeySource() (string, error) {
+	env, err := readEnvelope()
+	if err != nil {
+		return "", err
+	}
+	return env.KDF, nil
+}
+
+// Unlock derives the store key from passphrase and verifies it against the store.
+// The key is cached for the rest of this process and returned for the unlock agent.
+func Unlock(passphrase string) ([]byte, error) {
+	env, err := readEnvelope()
+	if err != nil {
+		return nil, err
+	}
+	key, err := keyFromPassphrase(env, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := decryptValues(env, key); err != nil {
+		return nil, err
+	}
+
+	mu.Lock()
+	cachedKey = key
+	mu.Unlock()
+	return key, nil
+}
+
+// lockStore takes the store's file lock, which serializes read-modify-write
+// cycles between processes (mu only covers this one)
+func lockStore() (func(), error) {
+	path := GetStorePath()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	return osutil.Lock(path + ".lock")
+}
+
+// lockForUpdate obtains the store key, which may prompt or wait on the
+// keychain, and then takes the file lock. Callers must hold mu and re-read
+// the store once locked.
+func lockForUpdate() (func(), error) {
+	if _, _, err := openStore(); err != nil {
+		return nil, err
+	}
+	return lockStore()
+}
+
+// openStore reads and decrypts the store, obtaining the key if needed.
+// Callers must hold mu.
+func openStore() (*envelope, map[string]string, error) {
+	env, err := readEnvelope()
+	if err != nil {
+		return nil, nil, err
+	}
+	if cachedKey == nil {
+		key, err := obtainKey(env)
+		if err != nil {
+			return nil, nil, err
+		}
+		cachedKey = key
+	}
+	values, err := decryptValues(env, cachedKey)
+	if err != nil {
+		cachedKey = nil
+		return nil, nil, err
+	}
+	return env, values, nil
+}
+
+func readEnvelope() (*envelope, error) {
+	data, err := os.ReadFile(GetStorePath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotInitialized
+		}
+		return nil, err
+	}
+	var env envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, fmt.Errorf("corrupt secret store: %w", err)
+	}
+	return &env, nil
+}
+
+func decryptValues(env *envelope, key []byte) (map[string]string, error) {
+	ciphertext, err := base64.StdEncoding.DecodeString(env.Data)
+	if err != nil {
+		return nil, fmt.Errorf("corrupt secret store: %w", err)
+	}
+	plaintext, err := crypto.Decrypt(key, ciphertext)
+	if err != nil {
+		return nil, ErrBadPassphrase
+	}
+	values := map[string]string{}
+	if err := json.Unmarshal(plaintext, &values); err != nil {
+		return nil, fmt.Errorf("corrupt secret store: %w", err)
+	}
+	return values, nil
+}
+
+func writeStore(env *envelope, key []byte, values map[string]string) error {
+	plaintext, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+	ciphertext, err := crypto.Encrypt(key, plaintext)
+	if err != nil {
+		return err
+	}
+	env.Data = base64.StdEncoding.EncodeToString(ciphertext)
+
+	data, err := json.MarshalIndent(env, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	path := GetStorePath()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".secrets-*.tmp") // Created 0600
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // No-op once renamed
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func deriveKey(passphrase string, salt []byte) ([]byte, error) {
+	return scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, crypto.KeySize)
+}
+
+func keyFromPassphrase(env *envelope, passphrase string) ([]byte, error) {
+	if env.KDF != KDFScrypt {
+		return nil, fmt.Errorf("store key is held by the OS keychain, no passphrase needed")
+	}
+	salt, err := base64.StdEncoding.DecodeString(env.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("corrupt secret store: %w", err)
+	}
+	return deriveKey(passphrase, salt)
+}
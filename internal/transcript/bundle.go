@@ -0,0 +1,290 @@
+package transcript
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// BundleKind identifies a handoff bundle file
+const BundleKind = "claw2claw/handoff"
+
+// BundleExt is the file extension handoff bundles are sent with
+const BundleExt = ".handoff.json"
+
+// Tool output modes
+const (
+	OutputTrim    = "trim"    // Keep the head and tail of long outputs
+	OutputSummary = "summary" // One line per tool call: what ran and how big the output was
+	OutputFull    = "full"    // Keep outputs whole (still redacted)
+	OutputNone    = "none"    // Drop tool calls entirely
+)
+
+// Trim limits for OutputTrim
+const (
+	trimHeadLines = 20
+	trimTailLines = 5
+	trimMaxBytes  = 2000
+	maxInputChars = 300
+)
+
+// Options selects what goes into a bundle
+type Options struct {
+	From, To   int    // 1-based inclusive turn range; 0 means open-ended
+	Last       int    // Only the last N turns (overrides From/To)
+	ToolOutput string // OutputTrim (default), OutputSummary, OutputFull or OutputNone
+}
+
+// Bundle is a session packaged for handoff
+type Bundle struct {
+	Kind       string       `json:"kind"`
+	Version    int          `json:"version"`
+	SessionID  string       `json:"session_id"`
+	Project    string       `json:"project,omitempty"` // Project directory name, not the full path
+	GitBranch  string       `json:"git_branch,omitempty"`
+	Title      string       `json:"title,omitempty"`
+	CreatedAt  time.Time    `json:"created_at"`
+	From       int          `json:"from"`
+	To         int          `json:"to"`
+	TotalTurns int          `json:"total_turns"`
+	ToolOutput string       `json:"tool_output"`
+	Redactions int          `json:"redactions"`
+	Turns      []BundleTurn `json:"turns"`
+}
+
+// BundleTurn is one turn of a handoff
+type BundleTurn struct {
+	Index     int       `json:"index"`
+	At        time.Time `json:"at"`
+	Prompt    string    `json:"prompt"`
+	Compacted bool      `json:"compacted,omitempty"`
+	Steps     []Step    `json:"steps,omitempty"`
+}
+
+// Step is assistant text or a tool call with its (possibly trimmed) output
+type Step struct {
+	Text      string `json:"text,omitempty"`
+	Tool      string `json:"tool,omitempty"`
+	Input     string `json:"input,omitempty"`
+	Output    string `json:"output,omitempty"`
+	Lines     int    `json:"lines,omitempty"` // Lines in the original output
+	Truncated bool   `json:"truncated,omitempty"`
+	IsError   bool   `json:"is_error,omitempty"`
+}
+
+// ParseRange parses a turn range: "3-7", "5" (just turn 5), "5-" (5 to the
+// end) or "-3" (up to turn 3)
+func ParseRange(s string) (from, to int, err error) {
+	s = strings.TrimSpace(s)
+	lo, hi, isRange := strings.Cut(s, "-")
+	parse := func(v string) (int, error) {
+		if v == "" {
+			return 0, nil
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return 0, fmt.Errorf("invalid turn range %q", s)
+		}
+		return n, nil
+	}
+	if from, err = parse(lo); err != nil {
+		return 0, 0, err
+	}
+	if !isRange {
+		if from == 0 {
+			return 0, 0, fmt.Errorf("invalid turn range %q", s)
+		}
+		return from, from, nil
+	}
+	if to, err = parse(hi); err != nil {
+		return 0, 0, err
+	}
+	if from > 0 && to > 0 && from > to {
+		return 0, 0, fmt.Errorf("invalid turn range %q", s)
+	}
+	return from, to, nil
+}
+
+// Build packages the selected turns of t, trimming tool output and
+// stripping secrets
+func Build(t *Transcript, opts Options) (*Bundle, error) {
+	mode := opts.ToolOutput
+	switch mode {
+	case "":
+		mode = OutputTrim
+	case OutputTrim, OutputSummary, OutputFull, OutputNone:
+	default:
+		return nil, fmt.Errorf("invalid tool output mode %q (trim, summary, full or none)", mode)
+	}
+
+	total := len(t.Turns)
+	from, to := opts.From, opts.To
+	if opts.Last > 0 {
+		from, to = total-opts.Last+1, total
+	}
+	if from < 1 {
+		from = 1
+	}
+	if to == 0 || to > total {
+		to = total
+	}
+	if from > to {
+		return nil, fmt.Errorf("turn range %d-%d is outside the session (%d turns)", opts.From, opts.To, total)
+	}
+
+	b := &Bundle{
+		Kind:       BundleKind,
+		Version:    1,
+		SessionID:  t.SessionID,
+		Project:    baseName(t.Cwd),
+		GitBranch:  t.GitBranch,
+		CreatedAt:  time.Now(),
+		From:       from,
+		To:         to,
+		TotalTurns: total,
+		ToolOutput: mode,
+	}
+	b.Title = b.redact(t.Title)
+
+	for _, turn := range t.Turns[from-1 : to] {
+		bt := BundleTurn{Index: turn.Index, At: turn.At, Prompt: b.redact(turn.Prompt), Compacted: turn.Compacted}
+
+		// Pair tool calls with their results
+		results := make(map[string]Block)
+		for _, m := range turn.Messages {
+			for _, blk := range m.Blocks {
+				if blk.Kind == BlockToolResult {
+					results[blk.ToolID] = blk
+				}
+			}
+		}
+
+		for _, m := range turn.Messages {
+			for _, blk := range m.Blocks {
+				switch blk.Kind {
+				case BlockText:
+					if m.Role == "assistant" {
+						bt.Steps = append(bt.Steps, Step{Text: b.redact(blk.Text)})
+					}
+				case BlockToolUse:
+					if mode == OutputNone {
+						continue
+					}
+					bt.Steps = append(bt.Steps, b.toolStep(blk, results[blk.ToolID], mode))
+				}
+			}
+		}
+		b.Turns = append(b.Turns, bt)
+	}
+	return b, nil
+}
+
+// toolStep summarizes a tool call and its output according to mode.
+// Secrets are redacted before anything is cut, so trimming can't split a
+// secret and leave it unrecognizable.
+func (b *Bundle) toolStep(use, result Block, mode string) Step {
+	step := Step{Tool: use.Tool, Input: truncate(b.redact(summarizeInput(use.Input)), maxInputChars), IsError: result.IsError}
+	output := strings.TrimRight(result.Text, "\n")
+	if output != "" {
+		step.Lines = strings.Count(output, "\n") + 1
+	}
+
+	switch mode {
+	case OutputFull:
+		step.Output = b.redact(output)
+	case OutputTrim:
+		step.Output, step.Truncated = trimOutput(b.redact(output))
+	case OutputSummary:
+		step.Truncated = output != ""
+	}
+	return step
+}
+
+func (b *Bundle) redact(s string) string {
+	s, n := Redact(s)
+	b.Redactions += n
+	return s
+}
+
+// summarizeInput shows the argument that says what a tool call did (the
+// command, file or pattern) rather than the whole input. The caller
+// redacts and truncates it.
+func summarizeInput(input json.RawMessage) string {
+	var fields map[string]any
+	if json.Unmarshal(input, &fields) == nil {
+		for _, key := range []string{"command", "file_path", "path", "pattern", "url", "query", "description", "prompt"} {
+			if v, ok := fields[key].(string); ok && v != "" {
+				return v
+			}
+		}
+	}
+	var buf bytes.Buffer
+	if json.Compact(&buf, input) != nil {
+		return ""
+	}
+	return buf.String()
+}
+
+// trimOutput keeps the head and tail of long output
+func trimOutput(s string) (string, bool) {
+	lines := strings.Split(s, "\n")
+	truncated := false
+	if len(lines) > trimHeadLines+trimTailLines {
+		omitted := len(lines) - trimHeadLines - trimTailLines
+		lines = append(append(lines[:trimHeadLines:trimHeadLines], fmt.Sprintf("... (%d lines omitted) ...", omitted)), lines[len(lines)-trimTailLines:]...)
+		truncated = true
+	}
+	out := strings.Join(lines, "\n")
+	if len(out) > trimMaxBytes {
+		out = truncate(out, trimMaxBytes)
+		truncated = true
+	}
+	return out, truncated
+}
+
+// truncate cuts s to at most n bytes on a rune boundary
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n] + "..."
+}
+
+func baseName(path string) string {
+	path = strings.TrimRight(path, "/\\")
+	if i := strings.LastIndexAny(path, "/\\"); i >= 0 {
+		return path[i+1:]
+	}
+	return path
+}
+
+// Marshal encodes a bundle for sending
+func (b *Bundle) Marshal() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(b); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ParseBundle decodes a handoff bundle. ok is false if data isn't one.
+func ParseBundle(data []byte) (b *Bundle, ok bool) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return nil, false
+	}
+	if err := json.Unmarshal(trimmed, &b); err != nil || b.Kind != BundleKind {
+		return nil, false
+	}
+	return b, true
+}
@@ -0,0 +1,105 @@
+// Package transcript turns Claude Code session transcripts into handoff
+// bundles that can be shared as structured context
+package transcript
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrNotFound is returned when no transcript matches
+var ErrNotFound = errors.New("no Claude Code transcript found")
+
+// ProjectDir returns the directory Claude Code keeps a project's transcripts
+// in: ~/.claude/projects/ plus the encoded absolute project path
+func ProjectDir(projectDir string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	abs, err := filepath.Abs(projectDir)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".claude", "projects", encodePath(abs)), nil
+}
+
+// encodePath replaces everything but ASCII letters and digits with a dash, as
+// Claude Code does. It works on UTF-16 code units, so a character outside the
+// Basic Multilingual Plane becomes two dashes.
+func encodePath(path string) string {
+	var sb strings.Builder
+	for _, r := range path {
+		switch {
+		case 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z', '0' <= r && r <= '9':
+			sb.WriteRune(r)
+		case r > 0xFFFF:
+			sb.WriteString("--")
+		default:
+			sb.WriteByte('-')
+		}
+	}
+	return sb.String()
+}
+
+// Find locates a transcript for the project. session may be a path to a
+// .jsonl file, a session ID or unique ID prefix, or empty for the most
+// recently active session.
+func Find(projectDir, session string) (string, error) {
+	if strings.HasSuffix(session, ".jsonl") {
+		if _, err := os.Stat(session); err != nil {
+			return "", fmt.Errorf("%w: %s", ErrNotFound, session)
+		}
+		return session, nil
+	}
+
+	dir, err := ProjectDir(projectDir)
+	if err != nil {
+		return "", err
+	}
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return "", fmt.Errorf("%w in %s", ErrNotFound, dir)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	var latest string
+	var latestMod int64
+	var matches []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".jsonl") {
+			continue
+		}
+		if session != "" {
+			if strings.HasPrefix(name, session) {
+				matches = append(matches, name)
+			}
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if mod := info.ModTime().UnixNano(); latest == "" || mod > latestMod {
+			latest, latestMod = name, mod
+		}
+	}
+
+	switch {
+	case session == "" && latest != "":
+		return filepath.Join(dir, latest), nil
+	case len(matches) == 1:
+		return filepath.Join(dir, matches[0]), nil
+	case len(matches) > 1:
+		return "", fmt.Errorf("session %q is ambiguous (%d matches)", session, len(matches))
+	case session != "":
+		return "", fmt.Errorf("%w for session %q in %s", ErrNotFound, session, dir)
+	}
+	return "", fmt.Errorf("%w in %s", ErrNotFound, dir)
+}
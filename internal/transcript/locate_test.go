@@ -0,0 +1,24 @@
+package transcript
+
+import "testing"
+
+func TestEncodePath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/root/module", "-root-module"},
+		{"/home/me/my.project", "-home-me-my-project"},
+		{"/home/me/my_project", "-home-me-my-project"},
+		{"/home/me/My Project (2)", "-home-me-My-Project--2-"},
+		{"/srv/app@v1+beta", "-srv-app-v1-beta"},
+		{"/home/josé/café", "-home-jos--caf-"},
+		{"/tmp/🦀", "-tmp---"},
+		{`C:\Users\me\repo`, "C--Users-me-repo"},
+	}
+	for _, tt := range tests {
+		if got := encodePath(tt.path); got != tt.want {
+			t.Errorf("encodePath(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package transcript
+
+import "regexp"
+
+// Redacted replaces secrets stripped from a bundle
+const Redacted = "[REDACTED]"
+
+// secretPatterns match credentials commonly seen in tool output. Patterns
+// with a prefix group keep it, so the reader still sees what was removed.
+var secretPatterns = []struct {
+	re      *regexp.Regexp
+	replace string
+}{
+	// PEM private keys, including ones cut off before their END line
+	{regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?(?:-----END [A-Z ]*PRIVATE KEY-----|\z)`), Redacted},
+	// AWS access key IDs
+	{regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`), Redacted},
+	// GitHub tokens
+	{regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`), Redacted},
+	// Slack tokens
+	{regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`), Redacted},
+	// API keys in the sk-... style
+	{regexp.MustCompile(`\bsk-[A-Za-z0-9_-]{20,}`), Redacted},
+	// Google API keys
+	{regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`), Redacted},
+	// JWTs
+	{regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`), Redacted},
+	// Bearer tokens in headers
+	{regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9._~+/=-]{16,}`), "${1}" + Redacted},
+	// Passwords in URLs
+	{regexp.MustCompile(`(://[^/\s:@]+:)[^/\s@\[][^/\s@]*@`), "${1}" + Redacted + "@"},
+	// SECRET_NAME=value environment assignments
+	{regexp.MustCompile(`\b([A-Z0-9_]*(?:PASSWORD|PASSWD|SECRET|TOKEN|API_?KEY|ACCESS_?KEY|PRIVATE_?KEY|CREDENTIALS?)[A-Z0-9_]*\s*[:=]\s*["']?)([^\s"',;\[][^\s"',;]{5,})`), "${1}" + Redacted},
+	// "secret_name": "value" in JSON
+	{regexp.MustCompile(`(?i)("[a-z0-9_.-]*(?:password|passwd|secret|token|api[_-]?key|access[_-]?key|private[_-]?key)[a-z0-9_.-]*"\s*:\s*")([^"\[][^"]{5,})`), "${1}" + Redacted},
+	// --password value and --token=value flags
+	{regexp.MustCompile(`(?i)(--(?:password|passwd|secret|token|api-key|access-key)[= ])([^\s\[][^\s]{5,})`), "${1}" + Redacted},
+}
+
+// Redact strips secrets from s, returning the cleaned text and how many
+// secrets were removed
+func Redact(s string) (string, int) {
+	count := 0
+	for _, p := range secretPatterns {
+		s = p.re.ReplaceAllStringFunc(s, func(match string) string {
+			replaced := p.re.ReplaceAllString(match, p.replace)
+			if replaced != match {
+				count++
+			}
+			return replaced
+		})
+	}
+	return s, count
+}
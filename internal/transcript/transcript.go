@@ -0,0 +1,197 @@
+package transcript
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// maxLineSize bounds one transcript line; large tool outputs can be big
+const maxLineSize = 64 << 20
+
+// Block kinds
+const (
+	BlockText       = "text"
+	BlockToolUse    = "tool_use"
+	BlockToolResult = "tool_result"
+)
+
+// Block is one piece of a message. Thinking blocks are dropped on load.
+type Block struct {
+	Kind    string
+	Text    string          // Text, or the tool output for tool_result
+	Tool    string          // Tool name for tool_use
+	ToolID  string          // Links a tool_result to its tool_use
+	Input   json.RawMessage // Tool input for tool_use
+	IsError bool            // Tool result was an error
+}
+
+// Message is a user or assistant message
+type Message struct {
+	Role   string
+	Blocks []Block
+	At     time.Time
+}
+
+// Turn is a user prompt and everything that happened until the next one
+type Turn struct {
+	Index     int // 1-based
+	Prompt    string
+	Compacted bool // Prompt is Claude Code's summary of an earlier, compacted conversation
+	At        time.Time
+	Messages  []Message // Assistant messages and tool results
+}
+
+// Transcript is a parsed Claude Code session
+type Transcript struct {
+	SessionID string
+	Cwd       string
+	GitBranch string
+	Title     string // Claude Code's summary line, if it wrote one
+	Turns     []Turn
+}
+
+// entry is one line of a transcript .jsonl file
+type entry struct {
+	Type             string          `json:"type"`
+	SessionID        string          `json:"sessionId"`
+	Cwd              string          `json:"cwd"`
+	GitBranch        string          `json:"gitBranch"`
+	Timestamp        time.Time       `json:"timestamp"`
+	IsSidechain      bool            `json:"isSidechain"`
+	IsMeta           bool            `json:"isMeta"`
+	IsCompactSummary bool            `json:"isCompactSummary"`
+	Summary          string          `json:"summary"`
+	Message          json.RawMessage `json:"message"`
+}
+
+type rawMessage struct {
+	Role    string          `json:"role"`
+	Content json.RawMessage `json:"content"`
+}
+
+type rawBlock struct {
+	Type      string          `json:"type"`
+	Text      string          `json:"text"`
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Input     json.RawMessage `json:"input"`
+	ToolUseID string          `json:"tool_use_id"`
+	Content   json.RawMessage `json:"content"`
+	IsError   bool            `json:"is_error"`
+}
+
+// Load parses a transcript file. Sidechain (subagent) messages, meta
+// messages and thinking blocks are skipped; lines that aren't messages are
+// ignored so newer transcript formats still load.
+func Load(path string) (*Transcript, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	t := &Transcript{}
+	var turn *Turn
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	for line := 1; scanner.Scan(); line++ {
+		var e entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("invalid transcript line %d: %w", line, err)
+		}
+		if e.Type == "summary" && t.Title == "" {
+			t.Title = e.Summary
+		}
+		if (e.Type != "user" && e.Type != "assistant") || e.IsSidechain || e.IsMeta || len(e.Message) == 0 {
+			continue
+		}
+		if t.SessionID == "" {
+			t.SessionID, t.Cwd, t.GitBranch = e.SessionID, e.Cwd, e.GitBranch
+		}
+
+		var rm rawMessage
+		if err := json.Unmarshal(e.Message, &rm); err != nil {
+			continue
+		}
+		msg := Message{Role: rm.Role, Blocks: parseContent(rm.Content), At: e.Timestamp}
+		if len(msg.Blocks) == 0 {
+			continue
+		}
+
+		// A user message that isn't only tool results starts a new turn
+		if e.Type == "user" && !onlyToolResults(msg.Blocks) {
+			t.Turns = append(t.Turns, Turn{
+				Index:     len(t.Turns) + 1,
+				Prompt:    joinText(msg.Blocks),
+				Compacted: e.IsCompactSummary,
+				At:        e.Timestamp,
+			})
+			turn = &t.Turns[len(t.Turns)-1]
+			continue
+		}
+		if turn == nil {
+			continue // Tool output before any prompt
+		}
+		turn.Messages = append(turn.Messages, msg)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read transcript: %w", err)
+	}
+	if len(t.Turns) == 0 {
+		return nil, fmt.Errorf("transcript %s has no turns", path)
+	}
+	return t, nil
+}
+
+// parseContent decodes message content, which is a string or a list of blocks
+func parseContent(content json.RawMessage) []Block {
+	var s string
+	if json.Unmarshal(content, &s) == nil {
+		if strings.TrimSpace(s) == "" {
+			return nil
+		}
+		return []Block{{Kind: BlockText, Text: s}}
+	}
+
+	var raw []rawBlock
+	if json.Unmarshal(content, &raw) != nil {
+		return nil
+	}
+	var blocks []Block
+	for _, rb := range raw {
+		switch rb.Type {
+		case "text":
+			if strings.TrimSpace(rb.Text) != "" {
+				blocks = append(blocks, Block{Kind: BlockText, Text: rb.Text})
+			}
+		case "tool_use":
+			blocks = append(blocks, Block{Kind: BlockToolUse, Tool: rb.Name, ToolID: rb.ID, Input: rb.Input})
+		case "tool_result":
+			blocks = append(blocks, Block{Kind: BlockToolResult, ToolID: rb.ToolUseID, Text: joinText(parseContent(rb.Content)), IsError: rb.IsError})
+		}
+	}
+	return blocks
+}
+
+func onlyToolResults(blocks []Block) bool {
+	for _, b := range blocks {
+		if b.Kind != BlockToolResult {
+			return false
+		}
+	}
+	return true
+}
+
+func joinText(blocks []Block) string {
+	var parts []string
+	for _, b := range blocks {
+		if b.Kind == BlockText {
+			parts = append(parts, b.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}